@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var attachCmd = &cobra.Command{
+	Use:   "attach [session-id]",
+	Short: "Attach to a running session",
+	Long: `Attach the terminal to the tmux or screen session of a running
+reconnaissance session. Sessions of the process backend have no terminal;
+follow their output with 'trident-recon logs --follow' instead.
+
+The session ID may be shortened to any unique prefix. Without an ID, a picker
+lists all running sessions. Inside tmux, the current client is switched to
+the session instead of nesting tmux.
+
+Examples:
+  trident-recon attach
+  trident-recon attach abc123def456
+  trident-recon attach abc1`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAttach,
+}
+
+func init() {
+	rootCmd.AddCommand(attachCmd)
+	attachCmd.Flags().StringVar(&toolFilter, "tool", "", "Only offer sessions of this tool in the picker")
+}
+
+func runAttach(cmd *cobra.Command, args []string) error {
+	stateDir := config.GetStateDir()
+	sm := executor.NewSessionManager(stateDir)
+
+	var id string
+	if len(args) == 1 {
+		id = args[0]
+	} else {
+		picked, err := pickRunningSession(sm)
+		if err != nil {
+			return err
+		}
+		if picked == "" {
+			return nil
+		}
+		id = picked
+	}
+
+	return sm.AttachToSession(id)
+}
+
+// pickRunningSession asks the user to choose one of the running sessions
+// and returns its ID, or an empty string when there is nothing to pick
+func pickRunningSession(sm *executor.SessionManager) (string, error) {
+	sessions, err := sm.ListSessions(toolFilter)
+	if err != nil {
+		return "", fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var labels []string
+	ids := make(map[string]string)
+	for _, s := range sessions {
+		if s.Status != executor.StatusRunning {
+			continue
+		}
+		label := fmt.Sprintf("%s · %s · %s (%s)", s.Tool, s.CommandName, s.Target, s.ID)
+		labels = append(labels, label)
+		ids[label] = s.ID
+	}
+
+	if len(labels) == 0 {
+		utils.PrintInfo("No running sessions to attach to")
+		return "", nil
+	}
+
+	selected, err := utils.PromptSelect("Attach to session", labels)
+	if err != nil {
+		return "", err
+	}
+
+	return ids[selected], nil
+}
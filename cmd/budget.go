@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/scope"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// loadBudgets converts the budget section of the config for the generator
+// and the scheduler
+func loadBudgets(cfg *config.Config) (executor.Budgets, error) {
+	budgets := executor.Budgets{
+		PerHost: executor.Budget{
+			Rate:    cfg.Budget.PerHost.Rate,
+			Threads: cfg.Budget.PerHost.Threads,
+		},
+	}
+
+	names := make([]string, 0, len(cfg.Budget.Programs))
+	for name := range cfg.Budget.Programs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		program := cfg.Budget.Programs[name]
+		s, err := scope.New(program.Scope, nil)
+		if err != nil {
+			return executor.Budgets{}, fmt.Errorf("budget.programs.%s: %w", name, err)
+		}
+		budgets.Programs = append(budgets.Programs, executor.ProgramBudget{
+			Name:   name,
+			Scope:  s,
+			Budget: executor.Budget{Rate: program.Rate, Threads: program.Threads},
+		})
+	}
+
+	return budgets, nil
+}
+
+// allowUnthrottled lets run start sessions a rate budget cannot throttle
+var allowUnthrottled bool
+
+// checkThrottled refuses sessions a rate budget covers but cannot limit,
+// because their template takes no {RATE}, unless --allow-unthrottled is
+// given, in which case each one is warned about
+func checkThrottled(budgets executor.Budgets, sessions []executor.Session) error {
+	unthrottled := budgets.Unthrottled(sessions)
+	if len(unthrottled) == 0 {
+		return nil
+	}
+
+	var lines []string
+	for _, s := range unthrottled {
+		line := fmt.Sprintf("%s - %s (%s)", s.Tool, s.CommandName, s.Target)
+		if allowUnthrottled {
+			utils.PrintWarning("Not rate limited by the budget, its template has no {RATE}: " + line)
+			continue
+		}
+		lines = append(lines, "  "+line)
+	}
+	if allowUnthrottled {
+		return nil
+	}
+
+	return fmt.Errorf("a rate budget covers %d session(s) whose template has no {RATE}, so the budget cannot limit them:\n%s\n"+
+		"add {RATE} to their templates, leave them out with --skip, or pass --allow-unthrottled to run them anyway",
+		len(unthrottled), strings.Join(lines, "\n"))
+}
+
+// shareNote describes the budget share a session started with, if any
+func shareNote(session executor.Session) string {
+	var parts []string
+	if session.Rate > 0 {
+		parts = append(parts, fmt.Sprintf("rate %d/s", session.Rate))
+	}
+	if session.Threads > 0 {
+		parts = append(parts, fmt.Sprintf("%d threads", session.Threads))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var dumpEffective bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+	Long: `Inspect the configuration and where it is loaded from.
+
+The configuration is built from these layers, later ones win:
+  1. User config: --config, $TRIDENT_CONFIG, $XDG_CONFIG_HOME/trident-recon/config.yaml
+     or ~/.config/trident-recon/config.yaml
+  2. Project config: the nearest .trident.yaml in the working directory or its
+     parents, deep-merged over the user config
+  3. Environment: TRIDENT_<PATH> overrides a single value, e.g.
+     TRIDENT_GLOBAL_OUTPUT_DIR or TRIDENT_SCHEDULER_MAX_CONCURRENT
+
+Examples:
+  trident-recon config path
+  trident-recon config dump
+  trident-recon config dump --effective`,
+}
+
+var configDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Print the user config, or the merged config with --effective",
+	RunE:  runConfigDump,
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the config files and state directory in use",
+	RunE:  runConfigPath,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configDumpCmd)
+	configCmd.AddCommand(configPathCmd)
+	configDumpCmd.Flags().BoolVar(&dumpEffective, "effective", false, "Print the merged config with the source of every value")
+}
+
+func runConfigDump(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	if !dumpEffective {
+		data, err := os.ReadFile(config.GetConfigPath())
+		if err != nil {
+			return fmt.Errorf("failed to read config: %w (run 'trident-recon init' first)", err)
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	cfg, sources, err := config.LoadWithSources()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Apply the same defaults the other commands get
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	data, err := cfg.DumpEffective(sources)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "# Effective configuration (comments show where each value came from)\n")
+	fmt.Fprintf(out, "# User config:    %s\n", config.GetConfigPath())
+	if projectPath := config.FindProjectConfig(); projectPath != "" {
+		fmt.Fprintf(out, "# Project config: %s\n", projectPath)
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+func runConfigPath(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	projectPath := config.FindProjectConfig()
+	if projectPath == "" {
+		projectPath = "(none)"
+	}
+
+	fmt.Fprintf(out, "Config file:     %s\n", config.GetConfigPath())
+	fmt.Fprintf(out, "Project config:  %s\n", projectPath)
+	fmt.Fprintf(out, "State directory: %s\n", config.GetStateDir())
+	return nil
+}
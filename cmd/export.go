@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/export"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat string
+	exportFile   string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export findings as JSON Lines, CSV or SARIF",
+	Long: `Export the normalized findings for spreadsheets and code-scanning dashboards.
+
+Formats:
+  jsonl  One JSON object per finding
+  csv    One row per finding with a header row
+  sarif  SARIF 2.1.0, one result per target URL and rule
+         (e.g. exposed-backup-file, exposed-vcs, api-documentation)
+
+Every record links back to the session ID, tool and command name that found
+it. The filters of 'trident-recon results' are available as well. Without -u
+or -l, findings of all targets are exported.
+
+Examples:
+  trident-recon export --format jsonl -u http://example.com
+  trident-recon export --format csv -l targets.txt --out findings.csv
+  trident-recon export --format sarif --status 2xx --out trident.sarif
+  trident-recon export --format jsonl --run 20250101-120000-ab12`,
+	RunE: runExport,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	addFindingFilterFlags(exportCmd)
+	exportCmd.Flags().StringVar(&exportFormat, "format", export.FormatJSONL, "Export format: jsonl, csv or sarif")
+	exportCmd.Flags().StringVar(&exportFile, "out", "", "Write to this file instead of stdout")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	filter, err := buildResultFilter()
+	if err != nil {
+		return err
+	}
+
+	findings, err := loadFindings(config.GetStateDir())
+	if err != nil {
+		return err
+	}
+	findings = filter.Apply(findings)
+
+	if exportFile == "" {
+		if err := export.Write(cmd.OutOrStdout(), exportFormat, findings, version); err != nil {
+			return fmt.Errorf("export failed: %w", err)
+		}
+		return nil
+	}
+
+	path := utils.ExpandPath(exportFile)
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	if err := export.Write(file, exportFormat, findings, version); err != nil {
+		file.Close()
+		return fmt.Errorf("export failed: %w", err)
+	}
+	// A failed close can mean the data never reached the disk
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+
+	utils.PrintSuccess(fmt.Sprintf("Exported %d finding(s) to %s", len(findings), exportFile))
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/bc0d3/trident-recon/pkg/config"
 	"github.com/bc0d3/trident-recon/pkg/generator"
+	"github.com/bc0d3/trident-recon/pkg/targets"
 	"github.com/bc0d3/trident-recon/pkg/utils"
 	"github.com/spf13/cobra"
 )
@@ -21,12 +22,18 @@ that can be manually executed.
 Examples:
   trident-recon generate -u http://example.com
   trident-recon generate -u http://example.com -o ~/scans/target1
-  trident-recon generate -u http://example.com --tools ffuf,gobuster`,
+  trident-recon generate -u http://example.com --tools ffuf,gobuster
+  trident-recon generate -l targets.txt --scope program-scope.yaml
+  trident-recon generate -u http://example.com --profile quick
+  trident-recon generate -u http://example.com --var username=alice --var cookie="sid=abc"`,
 	RunE: runGenerate,
 }
 
 func init() {
 	rootCmd.AddCommand(generateCmd)
+	addScopeFlags(generateCmd)
+	addProfileFlag(generateCmd)
+	addVarFlag(generateCmd)
 }
 
 func runGenerate(cmd *cobra.Command, args []string) error {
@@ -42,17 +49,36 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w (run 'trident-recon init' first)", err)
 	}
 
+	// Apply profile overlay
+	if err := applyProfile(cfg); err != nil {
+		return err
+	}
+
 	// Validate config
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("invalid config: %w", err)
 	}
 
+	// Keep secret values out of everything written
+	registerSecrets(cfg)
+
+	// Check user variables
+	if err := checkVars(cfg); err != nil {
+		return err
+	}
+
 	// Get targets
 	targets, err := getTargets()
 	if err != nil {
 		return err
 	}
 
+	// Drop targets outside the scope
+	targets, err = applyScope(cfg, targets)
+	if err != nil {
+		return err
+	}
+
 	utils.PrintSuccess(fmt.Sprintf("Found %d target(s)", len(targets)))
 
 	// If multiple targets, generate for all together (for domain list support)
@@ -62,10 +88,10 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 
 	// Single target - process individually
 	for i, target := range targets {
-		utils.PrintInfo(fmt.Sprintf("[%d/%d] Processing target: %s", i+1, len(targets), target))
+		utils.PrintInfo(fmt.Sprintf("[%d/%d] Processing target: %s", i+1, len(targets), target.URL))
 
 		if err := generateForTarget(cfg, target, ""); err != nil {
-			utils.PrintError(fmt.Sprintf("Failed to generate for %s: %v", target, err))
+			utils.PrintError(fmt.Sprintf("Failed to generate for %s: %v", target.URL, err))
 			continue
 		}
 	}
@@ -73,32 +99,37 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func generateForTarget(cfg *config.Config, target string, domainListFile string) error {
+func generateForTarget(cfg *config.Config, target targets.Target, domainListFile string) error {
 	// Parse URL to get domain
-	_, domain, err := utils.ParseURL(target)
+	_, domain, err := utils.ParseURL(target.URL)
 	if err != nil {
 		return fmt.Errorf("invalid URL: %w", err)
 	}
-	domain = utils.SanitizeDomain(domain)
+	domain = utils.TargetKey(domain)
 
 	// Determine output directory
-	var outDir string
-	if outputDir != "" {
-		// User specified output directory: outputDir/domain/
-		outDir = filepath.Join(utils.ExpandPath(outputDir), domain)
-	} else {
-		// No output specified: use domain only
-		baseDir := utils.ExpandPath(cfg.Global.OutputDir)
-		outDir = filepath.Join(baseDir, domain)
-	}
+	outDir := targetOutputDir(cfg, domain)
 
 	// Create output directory
 	if err := utils.EnsureDir(outDir); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
+	// Resolve user variables
+	vars, err := targetVars(cfg, target)
+	if err != nil {
+		return err
+	}
+
+	budgets, err := loadBudgets(cfg)
+	if err != nil {
+		return err
+	}
+
 	// Generate commands
-	gen := generator.New(cfg, target, outDir)
+	gen := generator.New(cfg, target.URL, outDir)
+	gen.SetUserVars(vars)
+	gen.SetBudgets(budgets)
 	sessions, err := gen.Generate(toolsFilter, skipTools)
 	if err != nil {
 		return fmt.Errorf("failed to generate commands: %w", err)
@@ -108,9 +139,10 @@ func generateForTarget(cfg *config.Config, target string, domainListFile string)
 
 	// Generate markdown
 	mdGen := generator.MarkdownGenerator{
-		Target:    target,
+		Target:    target.URL,
 		OutputDir: outDir,
 		Sessions:  sessions,
+		Vars:      vars,
 	}
 
 	markdown := mdGen.Generate()
@@ -149,33 +181,61 @@ func generateForTarget(cfg *config.Config, target string, domainListFile string)
 	return nil
 }
 
-func getTargets() ([]string, error) {
+// baseOutputDir returns the directory all target directories live in
+func baseOutputDir(cfg *config.Config) string {
+	if outputDir != "" {
+		// User specified output directory
+		return utils.ExpandPath(outputDir)
+	}
+	return utils.ExpandPath(cfg.Global.OutputDir)
+}
+
+// targetOutputDir returns the output directory of a target domain
+func targetOutputDir(cfg *config.Config, domain string) string {
+	return filepath.Join(baseOutputDir(cfg), domain)
+}
+
+// getTargets returns the targets given with -u or loaded from the -l file.
+// A -u value may also be host:port or a CIDR range.
+func getTargets() ([]targets.Target, error) {
 	if targetURL != "" {
-		return []string{targetURL}, nil
+		urls, err := targets.ParseLine(targetURL)
+		if err != nil {
+			return nil, err
+		}
+		list := make([]targets.Target, len(urls))
+		for i, url := range urls {
+			list[i] = targets.Target{URL: url}
+		}
+		return list, nil
 	}
 
 	if targetList != "" {
-		targets, err := utils.ReadLines(targetList)
+		list, err := targets.Load(targetList, inputFormat)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read targets file: %w", err)
 		}
-		if len(targets) == 0 {
+		if len(list) == 0 {
 			return nil, fmt.Errorf("no targets found in file")
 		}
-		return targets, nil
+		return list, nil
 	}
 
 	return nil, fmt.Errorf("no target specified")
 }
 
-func generateForMultipleTargets(cfg *config.Config, targets []string) error {
-	// Determine base output directory
-	var baseOutDir string
-	if outputDir != "" {
-		baseOutDir = utils.ExpandPath(outputDir)
-	} else {
-		baseOutDir = utils.ExpandPath(cfg.Global.OutputDir)
+// getTargetURLs returns the URLs of the targets given with -u or -l
+func getTargetURLs() ([]string, error) {
+	list, err := getTargets()
+	if err != nil {
+		return nil, err
 	}
+	return targets.URLs(list), nil
+}
+
+func generateForMultipleTargets(cfg *config.Config, targets []targets.Target) error {
+	// Determine base output directory
+	baseOutDir := baseOutputDir(cfg)
 
 	// Create base output directory
 	if err := utils.EnsureDir(baseOutDir); err != nil {
@@ -185,13 +245,19 @@ func generateForMultipleTargets(cfg *config.Config, targets []string) error {
 	// Create domains list file in base directory
 	domainListFile := filepath.Join(baseOutDir, "domains.txt")
 	var domains []string
+	seenDomains := make(map[string]bool)
 	for _, target := range targets {
-		_, domain, err := utils.ParseURL(target)
+		_, domain, err := utils.ParseURL(target.URL)
 		if err != nil {
-			utils.PrintError(fmt.Sprintf("Failed to parse %s: %v", target, err))
+			utils.PrintError(fmt.Sprintf("Failed to parse %s: %v", target.URL, err))
 			continue
 		}
-		domains = append(domains, utils.SanitizeDomain(domain))
+		domain = utils.SanitizeDomain(domain)
+		if seenDomains[domain] {
+			continue
+		}
+		seenDomains[domain] = true
+		domains = append(domains, domain)
 	}
 
 	if err := utils.WriteLines(domainListFile, domains); err != nil {
@@ -200,46 +266,61 @@ func generateForMultipleTargets(cfg *config.Config, targets []string) error {
 
 	utils.PrintSuccess(fmt.Sprintf("Created domains file: %s", domainListFile))
 
+	budgets, err := loadBudgets(cfg)
+	if err != nil {
+		return err
+	}
+
 	// Process each target in its own subdirectory
 	for i, target := range targets {
-		utils.PrintInfo(fmt.Sprintf("[%d/%d] Processing target: %s", i+1, len(targets), target))
+		utils.PrintInfo(fmt.Sprintf("[%d/%d] Processing target: %s", i+1, len(targets), target.URL))
 
 		// Parse domain for this target
-		_, domain, err := utils.ParseURL(target)
+		_, domain, err := utils.ParseURL(target.URL)
 		if err != nil {
-			utils.PrintError(fmt.Sprintf("Failed to parse %s: %v", target, err))
+			utils.PrintError(fmt.Sprintf("Failed to parse %s: %v", target.URL, err))
 			continue
 		}
-		domain = utils.SanitizeDomain(domain)
+		domain = utils.TargetKey(domain)
 
 		// Create subdirectory for this target
 		targetOutDir := filepath.Join(baseOutDir, domain)
 		if err := utils.EnsureDir(targetOutDir); err != nil {
-			utils.PrintError(fmt.Sprintf("Failed to create directory for %s: %v", target, err))
+			utils.PrintError(fmt.Sprintf("Failed to create directory for %s: %v", target.URL, err))
+			continue
+		}
+
+		// Resolve user variables for this target
+		vars, err := targetVars(cfg, target)
+		if err != nil {
+			utils.PrintError(fmt.Sprintf("Failed to generate for %s: %v", target.URL, err))
 			continue
 		}
 
 		// Generate commands for this target
-		gen := generator.New(cfg, target, targetOutDir)
+		gen := generator.New(cfg, target.URL, targetOutDir)
 		gen.SetDomainListFile(domainListFile)
+		gen.SetUserVars(vars)
+		gen.SetBudgets(budgets)
 
 		sessions, err := gen.Generate(toolsFilter, skipTools)
 		if err != nil {
-			utils.PrintError(fmt.Sprintf("Failed to generate for %s: %v", target, err))
+			utils.PrintError(fmt.Sprintf("Failed to generate for %s: %v", target.URL, err))
 			continue
 		}
 
 		// Generate markdown for this target
 		mdGen := generator.MarkdownGenerator{
-			Target:    target,
+			Target:    target.URL,
 			OutputDir: targetOutDir,
 			Sessions:  sessions,
+			Vars:      vars,
 		}
 
 		markdown := mdGen.Generate()
 		mdPath := filepath.Join(targetOutDir, "comandos.md")
 		if err := utils.WriteFile(mdPath, markdown); err != nil {
-			utils.PrintError(fmt.Sprintf("Failed to write markdown for %s: %v", target, err))
+			utils.PrintError(fmt.Sprintf("Failed to write markdown for %s: %v", target.URL, err))
 			continue
 		}
 
@@ -251,7 +332,7 @@ func generateForMultipleTargets(cfg *config.Config, targets []string) error {
 		plainText := txtGen.Generate()
 		txtPath := filepath.Join(targetOutDir, "comandos.txt")
 		if err := utils.WriteFile(txtPath, plainText); err != nil {
-			utils.PrintError(fmt.Sprintf("Failed to write commands for %s: %v", target, err))
+			utils.PrintError(fmt.Sprintf("Failed to write commands for %s: %v", target.URL, err))
 			continue
 		}
 
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/results"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var ingestCmd = &cobra.Command{
+	Use:   "ingest [session-id...]",
+	Short: "Parse tool outputs into findings",
+	Long: `Parse the output files of finished sessions into normalized findings.
+
+Each tool/output format pair has its own parser (ffuf json, gobuster text,
+dirsearch text/json, feroxbuster text/json). Findings are stored per target
+in the state directory. Parsing a session again replaces its findings.
+
+Without session IDs, every finished session with an output file is parsed.
+
+Examples:
+  trident-recon ingest
+  trident-recon ingest --tool ffuf
+  trident-recon ingest abc123def456`,
+	RunE: runIngest,
+}
+
+func init() {
+	rootCmd.AddCommand(ingestCmd)
+	ingestCmd.Flags().StringVar(&toolFilter, "tool", "", "Filter by tool name")
+}
+
+func runIngest(cmd *cobra.Command, args []string) error {
+	stateDir := config.GetStateDir()
+	sm := executor.NewSessionManager(stateDir)
+
+	var sessions []executor.Session
+	if len(args) > 0 {
+		for _, id := range args {
+			session, err := sm.GetSession(id)
+			if err != nil {
+				return fmt.Errorf("session not found: %w", err)
+			}
+			sessions = append(sessions, *session)
+		}
+	} else {
+		all, err := sm.ListSessions(toolFilter)
+		if err != nil {
+			return fmt.Errorf("failed to list sessions: %w", err)
+		}
+		for _, s := range all {
+			if s.OutputFile != "" && s.Status.IsTerminal() && utils.FileExists(s.OutputFile) {
+				sessions = append(sessions, s)
+			}
+		}
+	}
+
+	if len(sessions) == 0 {
+		utils.PrintInfo("No finished sessions with output to parse")
+		return nil
+	}
+
+	// New findings are notified when the config has channels; ingesting
+	// works without a config
+	notifier, _ := loadNotifier()
+
+	total, fresh, sent := 0, 0, 0
+	for _, s := range sessions {
+		count, found, err := results.Ingest(stateDir, s)
+		if err != nil {
+			utils.PrintWarning(fmt.Sprintf("Skipped %s (%s - %s): %v", s.ID, s.Tool, s.CommandName, err))
+			continue
+		}
+		if err := executor.MarkParsed(stateDir, s.ID); err != nil {
+			utils.PrintWarning(fmt.Sprintf("Failed to mark %s as parsed: %v", s.ID, err))
+		}
+		utils.PrintSuccess(fmt.Sprintf("%s (%s - %s): %d finding(s), %d new", s.ID, s.Tool, s.CommandName, count, len(found)))
+		total += count
+		fresh += len(found)
+
+		if notifier != nil {
+			sent += notifier.NotifyFindings(s, found)
+		}
+	}
+
+	fmt.Println()
+	utils.PrintSuccess(fmt.Sprintf("Stored %d finding(s) from %d session(s), %d new", total, len(sessions), fresh))
+	if sent > 0 {
+		utils.PrintInfo(fmt.Sprintf("Sent %d notification(s) about new findings", sent))
+	}
+
+	return nil
+}
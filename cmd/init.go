@@ -15,7 +15,9 @@ var initCmd = &cobra.Command{
 
 This will create:
   - Config file at ~/.config/trident-recon/config.yaml
-  - State directory at ~/.local/state/trident-recon`,
+    ($XDG_CONFIG_HOME/trident-recon/config.yaml, or the --config path)
+  - State directory at ~/.local/state/trident-recon
+    ($XDG_STATE_HOME/trident-recon, or the --state-dir path)`,
 	RunE: runInit,
 }
 
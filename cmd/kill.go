@@ -14,6 +14,8 @@ var killCmd = &cobra.Command{
 	Short: "Kill a specific session",
 	Long: `Kill a specific reconnaissance session by its ID.
 
+The session is kept in the state directory with status "killed".
+
 Examples:
   trident-recon kill abc123def456`,
 	Args: cobra.ExactArgs(1),
@@ -23,7 +25,7 @@ Examples:
 var killAllCmd = &cobra.Command{
 	Use:   "kill-all",
 	Short: "Kill all active sessions",
-	Long: `Kill all active reconnaissance sessions.
+	Long: `Kill all queued and running reconnaissance sessions.
 
 You can optionally filter by tool using the --tool flag.
 
@@ -61,7 +63,7 @@ func runKillAll(cmd *cobra.Command, args []string) error {
 	sm := executor.NewSessionManager(stateDir)
 
 	// Get sessions to be killed
-	sessions, err := sm.ListSessions(toolFilter)
+	sessions, err := sm.ActiveSessions(toolFilter)
 	if err != nil {
 		return fmt.Errorf("failed to list sessions: %w", err)
 	}
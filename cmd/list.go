@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"text/tabwriter"
+	"time"
 
 	"github.com/bc0d3/trident-recon/pkg/config"
 	"github.com/bc0d3/trident-recon/pkg/executor"
@@ -12,14 +14,21 @@ import (
 
 var listCmd = &cobra.Command{
 	Use:   "list",
-	Short: "List active trident-recon sessions",
-	Long: `List all active reconnaissance sessions.
+	Short: "List trident-recon sessions",
+	Long: `List all reconnaissance sessions.
 
-Shows session ID, tool, command, status, and target for each session.
+Shows session ID, tool, command, status, exit code, duration, progress and
+target for each session. Status is one of: pending, queued, running,
+succeeded, failed, killed, timed-out or lost (the tmux, screen or
+process session vanished without an exit code).
+
+Progress (percent done and, while running, the time left) is read from the
+output of ffuf, gobuster, feroxbuster and dirsearch.
 
 Examples:
   trident-recon list
-  trident-recon list --tool ffuf`,
+  trident-recon list --tool ffuf
+  trident-recon list --run 20250101-120000-ab12`,
 	Aliases: []string{"ls"},
 	RunE:    runList,
 }
@@ -27,6 +36,7 @@ Examples:
 func init() {
 	rootCmd.AddCommand(listCmd)
 	listCmd.Flags().StringVar(&toolFilter, "tool", "", "Filter by tool name")
+	listCmd.Flags().StringVar(&runFilter, "run", "", "Only sessions of this run")
 }
 
 func runList(cmd *cobra.Command, args []string) error {
@@ -39,39 +49,59 @@ func runList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to list sessions: %w", err)
 	}
 
+	if runFilter != "" {
+		if sessions, err = filterRun(stateDir, sessions); err != nil {
+			return err
+		}
+	}
+
 	if len(sessions) == 0 {
-		utils.PrintInfo("No active sessions found")
+		utils.PrintInfo("No sessions found")
 		return nil
 	}
 
-	fmt.Printf("\n🔱 Trident Recon - Active Sessions (%d)\n\n", len(sessions))
+	fmt.Printf("\n🔱 Trident Recon - Sessions (%d)\n\n", len(sessions))
+
+	printSessionsTable(cmd.OutOrStdout(), sessions)
+	fmt.Println()
+
+	utils.PrintInfo("Use 'trident-recon attach [id]' to attach to a session")
+	utils.PrintInfo("Use 'trident-recon logs <id>' to view the output of a session")
+	utils.PrintInfo("Use 'trident-recon kill <id>' to kill a session")
+
+	return nil
+}
 
+// printSessionsTable prints one row per session
+func printSessionsTable(out io.Writer, sessions []executor.Session) {
 	// Create table writer
-	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "ID\tTOOL\tCOMMAND\tSTATUS\tTARGET")
-	fmt.Fprintln(w, "──\t────\t───────\t──────\t──────")
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ID\tTOOL\tCOMMAND\tSTATUS\tEXIT\tDURATION\tPROGRESS\tTARGET")
+	fmt.Fprintln(w, "──\t────\t───────\t──────\t────\t────────\t────────\t──────")
 
 	for _, s := range sessions {
-		status := s.Status
+		status := string(s.Status)
 		if status == "" {
 			status = "unknown"
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+		exitCode := "-"
+		if s.ExitCode != nil {
+			exitCode = fmt.Sprintf("%d", *s.ExitCode)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 			s.ID,
 			s.Tool,
 			truncate(s.CommandName, 30),
 			status,
+			exitCode,
+			formatDuration(s.Elapsed()),
+			formatProgress(s),
 			truncate(s.Target, 40))
 	}
 
 	w.Flush()
-	fmt.Println()
-
-	utils.PrintInfo("Use 'tmux attach -t <session-id>' to attach to a session")
-	utils.PrintInfo("Use 'trident-recon kill <id>' to kill a session")
-
-	return nil
 }
 
 func truncate(s string, max int) string {
@@ -80,3 +110,33 @@ func truncate(s string, max int) string {
 	}
 	return s[:max-3] + "..."
 }
+
+// formatDuration formats a duration for table output
+func formatDuration(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// formatProgress shows how far a session got: percent done and, while it
+// runs, the time left
+func formatProgress(s executor.Session) string {
+	p := s.Progress
+	if p == nil {
+		return "-"
+	}
+
+	percent, ok := p.Percent()
+	if !ok {
+		return fmt.Sprintf("%d req", p.Processed)
+	}
+	progress := fmt.Sprintf("%.0f%%", percent)
+	if eta, ok := p.ETA(); ok && s.Status == executor.StatusRunning {
+		progress += " ETA " + formatDuration(eta)
+	}
+	return progress
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	followLogs bool
+	logAttempt int
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [session-id]",
+	Short: "Show the output of a session",
+	Long: `Show everything a session printed.
+
+Output is recorded to <output-dir>/logs/<tool>-<id>.log while the session
+runs, so it is still available after the session exits. Every retry gets
+its own log, <tool>-<id>.<attempt>.log: the logs of all attempts are shown
+in order, each under a header, or a single one with --attempt. For sessions
+started before logging existed, the scrollback of the live tmux pane (or
+screen window) is shown.
+
+Examples:
+  trident-recon logs abc123def456
+  trident-recon logs abc123def456 --attempt 1
+  trident-recon logs abc123def456 --follow`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLogs,
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+	logsCmd.Flags().BoolVarP(&followLogs, "follow", "f", false, "Keep printing new output until the session ends")
+	logsCmd.Flags().IntVar(&logAttempt, "attempt", 0, "Only show the log of this attempt")
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	// Secrets echoed by a tool are redacted when the config can be read
+	if cfg, err := config.Load(); err == nil {
+		registerSecrets(cfg)
+	}
+
+	stateDir := config.GetStateDir()
+	sm := executor.NewSessionManager(stateDir)
+
+	session, err := sm.GetSession(args[0])
+	if err != nil {
+		return fmt.Errorf("session not found: %w", err)
+	}
+
+	if logAttempt != 0 {
+		if followLogs {
+			return fmt.Errorf("--follow always follows the latest attempt, it cannot be combined with --attempt")
+		}
+		for _, log := range session.AttemptLogs() {
+			if log.Attempt == logAttempt {
+				data, err := os.ReadFile(log.Path)
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(cmd.OutOrStdout(), utils.Redact(string(data)))
+				return nil
+			}
+		}
+		return fmt.Errorf("no log recorded for attempt %d of session %s", logAttempt, session.ID)
+	}
+
+	// The logs of earlier attempts come first, each under a header, and the
+	// latest attempt is read below
+	printed := 0
+	for _, log := range session.AttemptLogs() {
+		if log.Path == session.LogFile {
+			continue
+		}
+		data, err := os.ReadFile(log.Path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "==> attempt %d: %s <==\n%s\n", log.Attempt, log.Path, utils.Redact(string(data)))
+		printed++
+	}
+	if printed > 0 && session.LogFile != "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "==> attempt %d: %s <==\n", max(session.Attempts, 1), session.LogFile)
+	} else if printed > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "==> attempt %d <==\n", max(session.Attempts, 1))
+	}
+
+	if followLogs && session.LogFile != "" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return sm.FollowLog(ctx, session, utils.RedactWriter(cmd.OutOrStdout()))
+	}
+
+	output, err := sm.ReadLog(session)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(cmd.OutOrStdout(), utils.Redact(output))
+
+	if followLogs {
+		utils.PrintWarning("Session was started without a log file, --follow is not available")
+	}
+
+	return nil
+}
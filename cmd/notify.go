@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/notify"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Send and test notifications",
+	Long: `Notifications are sent to the channels in the notifications section of the
+config when sessions succeed, fail, time out or are lost, when a run
+finishes and when 'ingest' finds new findings.
+
+Session and run events are recorded by whichever command notices them and
+sent at the end of every command, so they go out as soon as anything looks
+at the sessions. 'notify send' delivers them on its own, e.g. from cron.
+
+Examples:
+  trident-recon notify test
+  trident-recon notify test team-slack
+  trident-recon notify send`,
+}
+
+var notifyTestCmd = &cobra.Command{
+	Use:   "test [channel...]",
+	Short: "Send a test message to channels (default: all)",
+	RunE:  runNotifyTest,
+}
+
+var notifySendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Deliver pending session and run events",
+	Args:  cobra.NoArgs,
+	RunE:  runNotifySend,
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+	notifyCmd.AddCommand(notifyTestCmd)
+	notifyCmd.AddCommand(notifySendCmd)
+}
+
+func runNotifyTest(cmd *cobra.Command, args []string) error {
+	notifier, err := loadNotifier()
+	if err != nil {
+		return err
+	}
+	if !notifier.Enabled() {
+		utils.PrintInfo("No notification channels configured")
+		return nil
+	}
+
+	channels := args
+	if len(channels) == 0 {
+		channels = notifier.Channels()
+	}
+
+	failed := 0
+	for _, name := range channels {
+		if err := notifier.Test(name); err != nil {
+			utils.PrintError(fmt.Sprintf("%s: %v", name, err))
+			failed++
+			continue
+		}
+		utils.PrintSuccess(fmt.Sprintf("%s: test message sent", name))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d channel(s) failed", failed, len(channels))
+	}
+	return nil
+}
+
+func runNotifySend(cmd *cobra.Command, args []string) error {
+	notifier, err := loadNotifier()
+	if err != nil {
+		return err
+	}
+
+	// Pick up sessions that ended since anything last looked
+	if _, _, err := executor.RefreshAll(config.GetStateDir()); err != nil {
+		return fmt.Errorf("failed to refresh sessions: %w", err)
+	}
+
+	sent, err := notifier.Flush(config.GetStateDir())
+	if err != nil {
+		return err
+	}
+
+	utils.PrintSuccess(fmt.Sprintf("Sent %d notification(s)", sent))
+	return nil
+}
+
+// loadNotifier creates a notifier for the channels in the config
+func loadNotifier() (*notify.Notifier, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w (run 'trident-recon init' first)", err)
+	}
+	if err := cfg.ValidateNotifications(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	// Keep secret values out of messages and errors
+	registerSecrets(cfg)
+
+	return notify.New(cfg)
+}
+
+// deliverEvents sends the session and run events recorded so far. It runs
+// after every command; problems are only reported, as the command itself
+// succeeded.
+func deliverEvents(cmd *cobra.Command) {
+	stateDir := config.GetStateDir()
+	if cmd.Hidden || cmd == notifySendCmd || !executor.HasEvents(stateDir) {
+		return
+	}
+
+	notifier, err := loadNotifier()
+	if err != nil {
+		// Events stay recorded until the config can be loaded
+		return
+	}
+
+	if _, err := notifier.Flush(stateDir); err != nil {
+		utils.PrintWarning(fmt.Sprintf("Failed to send notifications: %v", err))
+	}
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var profileName string
+
+// addProfileFlag registers the --profile flag understood by applyProfile
+func addProfileFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&profileName, "profile", "", "Apply a profile from the profiles section of the config")
+}
+
+// applyProfile overlays the --profile on the config, if one was given
+func applyProfile(cfg *config.Config) error {
+	if profileName == "" {
+		return nil
+	}
+
+	if err := cfg.ApplyProfile(profileName); err != nil {
+		return err
+	}
+
+	description := cfg.Profiles[profileName].Description
+	if description == "" {
+		utils.PrintInfo(fmt.Sprintf("Using profile: %s", profileName))
+	} else {
+		utils.PrintInfo(fmt.Sprintf("Using profile: %s (%s)", profileName, description))
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/secrets"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// redactLogCmd filters the pane output of sessions that use secrets. It is
+// started as the log command of sessions and not meant to be run by hand.
+var redactLogCmd = &cobra.Command{
+	Use:    executor.RedactLogCommand + " <values-file>",
+	Short:  "Copy stdin to stdout with secret values redacted",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	RunE:   runRedactLog,
+}
+
+func init() {
+	rootCmd.AddCommand(redactLogCmd)
+}
+
+func runRedactLog(cmd *cobra.Command, args []string) error {
+	values, err := secrets.ReadValuesFile(args[0])
+	if err != nil {
+		// Keep recording the log even if nothing can be redacted
+		fmt.Fprintf(os.Stderr, "redact-log: %v\n", err)
+	}
+	for _, value := range values {
+		utils.AddRedaction(value)
+	}
+
+	return utils.CopyRedacted(os.Stdout, os.Stdin)
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/report"
+	"github.com/bc0d3/trident-recon/pkg/results"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Build HTML reports of sessions and findings",
+	Long: `Build a self-contained HTML report per target.
+
+Each report.html contains run metadata (the run IDs, profile and config hash
+of the runs that started the sessions), the session table with statuses and
+durations, and sortable, filterable findings tables. It is written next to the
+target's comandos.md. With -l, an index.html linking every target report with
+summary counts is written to the base output directory.
+
+Run 'trident-recon ingest' first to include the latest findings.
+
+Examples:
+  trident-recon report -u http://example.com
+  trident-recon report -l targets.txt -o ~/scans/project-name`,
+	RunE: runReport,
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	// Validate flags
+	if err := validateTargetFlags(); err != nil {
+		return err
+	}
+
+	// Load config
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'trident-recon init' first)", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	// Keep secret values out of everything written
+	registerSecrets(cfg)
+
+	targets, err := getTargetURLs()
+	if err != nil {
+		return err
+	}
+
+	stateDir := config.GetStateDir()
+	sm := executor.NewSessionManager(stateDir)
+	sessions, err := sm.ListSessions("")
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	runs, err := sm.ListRuns()
+	if err != nil {
+		return fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	store := results.NewStore(stateDir)
+	indexPath := filepath.Join(baseOutputDir(cfg), "index.html")
+
+	var entries []report.IndexEntry
+	for _, target := range targets {
+		targetReport, err := buildTargetReport(cfg, store, runs, sessions, target)
+		if err != nil {
+			utils.PrintError(fmt.Sprintf("Failed to build report for %s: %v", target, err))
+			continue
+		}
+
+		reportPath := filepath.Join(targetReport.OutputDir, "report.html")
+		if err := report.WriteTarget(reportPath, targetReport); err != nil {
+			utils.PrintError(fmt.Sprintf("Failed to write report for %s: %v", target, err))
+			continue
+		}
+
+		utils.PrintSuccess(fmt.Sprintf("Report for %s: %s", target, reportPath))
+		entries = append(entries, targetReport.Entry(report.RelativeLink(indexPath, reportPath)))
+	}
+
+	if len(targets) > 1 {
+		if err := report.WriteIndex(indexPath, entries); err != nil {
+			return err
+		}
+		utils.PrintSuccess(fmt.Sprintf("Index: %s", indexPath))
+	}
+
+	return nil
+}
+
+// buildTargetReport collects the sessions and findings of one target
+func buildTargetReport(cfg *config.Config, store *results.Store, runs []executor.Run, sessions []executor.Session, target string) (*report.TargetReport, error) {
+	_, domain, err := utils.ParseURL(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	normalized := utils.NormalizeURL(target)
+	outDir := targetOutputDir(cfg, utils.TargetKey(domain))
+
+	var targetSessions []executor.Session
+	for _, s := range sessions {
+		if s.Target == normalized {
+			targetSessions = append(targetSessions, s)
+		}
+	}
+
+	findings, err := store.Load(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load findings: %w", err)
+	}
+
+	return report.NewTargetReport(normalized, outDir, runs, targetSessions, findings), nil
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/results"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	resultStatuses []string
+	resultSizes    []string
+	resultPath     string
+	resultTools    []string
+	resultCommands []string
+	resultFormat   string
+)
+
+var resultsCmd = &cobra.Command{
+	Use:   "results",
+	Short: "Query collected findings",
+	Long: `Query the findings collected by 'trident-recon ingest'.
+
+The same URL found by several tools is shown once, together with the tools
+and sessions that found it. Without -u or -l, findings of all targets are
+queried.
+
+Status filters accept codes (200), ranges (301-308) and classes (4xx).
+Size filters accept exact sizes (1234) and ranges (100-500, 1000-, -500).
+
+Examples:
+  trident-recon results -u http://example.com
+  trident-recon results -u http://example.com --status 2xx,403 --size 100-
+  trident-recon results -l targets.txt --path '\.(bak|old|zip)$' --tool ffuf
+  trident-recon results -u http://example.com --format urls | httpx
+  trident-recon results --run 20250101-120000-ab12`,
+	RunE: runResults,
+}
+
+func init() {
+	rootCmd.AddCommand(resultsCmd)
+	addFindingFilterFlags(resultsCmd)
+	resultsCmd.Flags().StringVar(&resultFormat, "format", "table", "Output format: table, json or urls")
+}
+
+// addFindingFilterFlags registers the flags understood by buildResultFilter
+func addFindingFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&resultStatuses, "status", nil, "Status codes, ranges or classes (comma-separated)")
+	cmd.Flags().StringSliceVar(&resultSizes, "size", nil, "Response size ranges in bytes (comma-separated)")
+	cmd.Flags().StringVar(&resultPath, "path", "", "Regular expression the URL path must match")
+	cmd.Flags().StringSliceVar(&resultTools, "tool", nil, "Only findings from these tools (comma-separated)")
+	cmd.Flags().StringSliceVar(&resultCommands, "command", nil, "Only findings from these command names (comma-separated)")
+	cmd.Flags().StringVar(&runFilter, "run", "", "Only findings from the sessions of this run")
+}
+
+func runResults(cmd *cobra.Command, args []string) error {
+	filter, err := buildResultFilter()
+	if err != nil {
+		return err
+	}
+
+	findings, err := loadFindings(config.GetStateDir())
+	if err != nil {
+		return err
+	}
+
+	matched := results.Dedupe(filter.Apply(findings))
+
+	out := cmd.OutOrStdout()
+	switch resultFormat {
+	case "table":
+		return printResultsTable(out, matched)
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(matched)
+	case "urls":
+		for _, r := range matched {
+			fmt.Fprintln(out, r.URL)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q (use table, json or urls)", resultFormat)
+	}
+}
+
+// buildResultFilter turns the results flags into a findings filter
+func buildResultFilter() (results.Filter, error) {
+	var filter results.Filter
+	var err error
+
+	if filter.Statuses, err = results.ParseStatusRanges(resultStatuses); err != nil {
+		return filter, err
+	}
+	if filter.Sizes, err = results.ParseSizeRanges(resultSizes); err != nil {
+		return filter, err
+	}
+	if resultPath != "" {
+		if filter.Path, err = regexp.Compile(resultPath); err != nil {
+			return filter, fmt.Errorf("invalid --path pattern: %w", err)
+		}
+	}
+	filter.Tools = resultTools
+	filter.Commands = resultCommands
+	if runFilter != "" {
+		if filter.Sessions, err = runSessionIDs(config.GetStateDir()); err != nil {
+			return filter, err
+		}
+	}
+
+	return filter, nil
+}
+
+// loadFindings loads the findings of the targets given with -u/-l, or of
+// every target when none were given
+func loadFindings(stateDir string) ([]results.Finding, error) {
+	store := results.NewStore(stateDir)
+
+	if targetURL == "" && targetList == "" {
+		return store.LoadAll()
+	}
+
+	targets, err := getTargetURLs()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []results.Finding
+	for _, target := range targets {
+		targetFindings, err := store.Load(target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load findings for %s: %w", target, err)
+		}
+		findings = append(findings, targetFindings...)
+	}
+
+	return findings, nil
+}
+
+func printResultsTable(out io.Writer, matched []results.Result) error {
+	if len(matched) == 0 {
+		utils.PrintInfo("No findings match (run 'trident-recon ingest' to parse finished sessions)")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tLENGTH\tWORDS\tTYPE\tURL\tFOUND BY\tSESSIONS")
+	fmt.Fprintln(w, "──────\t──────\t─────\t────\t───\t────────\t────────")
+
+	for _, r := range matched {
+		words := "-"
+		if r.Words > 0 {
+			words = fmt.Sprintf("%d", r.Words)
+		}
+		contentType := r.ContentType
+		if contentType == "" {
+			contentType = "-"
+		}
+
+		fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			r.Status,
+			r.Length,
+			words,
+			truncate(contentType, 24),
+			r.URL,
+			strings.Join(r.Tools, ","),
+			strings.Join(r.Sessions, ","))
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\n%d unique URL(s)\n", len(matched))
+	return nil
+}
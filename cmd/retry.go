@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	resumeKilled bool
+	retryFailed  bool
+)
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume <run-id>",
+	Short: "Restart the unfinished sessions of a run",
+	Long: `Restart the sessions of a run that never finished: sessions that were
+lost (their backend session vanished without recording an exit, e.g. after a
+reboot) and sessions still queued when the run was interrupted.
+
+Sessions run again with the same command and output paths. Killed sessions
+are left alone unless --killed is given.
+
+Examples:
+  trident-recon resume 20250101-120000-ab12
+  trident-recon resume 20250101-120000 --killed`,
+	Args: cobra.ExactArgs(1),
+	RunE: runResume,
+}
+
+var retryCmd = &cobra.Command{
+	Use:   "retry [session-id...]",
+	Short: "Run sessions again",
+	Long: `Run the given sessions again with the same command and output paths.
+
+With --failed, every session that failed, timed out or was lost is retried,
+optionally only those of one run.
+
+Commands can also be retried automatically with retries: and backoff: in
+their template; see the README.
+
+Examples:
+  trident-recon retry abc123def456
+  trident-recon retry --failed
+  trident-recon retry --failed --run 20250101-120000-ab12`,
+	RunE: runRetry,
+}
+
+func init() {
+	rootCmd.AddCommand(resumeCmd)
+	rootCmd.AddCommand(retryCmd)
+	resumeCmd.Flags().BoolVar(&resumeKilled, "killed", false, "Also restart killed sessions")
+	retryCmd.Flags().BoolVar(&retryFailed, "failed", false, "Retry every failed, timed out or lost session")
+	retryCmd.Flags().StringVar(&runFilter, "run", "", "With --failed, only sessions of this run")
+}
+
+func runResume(cmd *cobra.Command, args []string) error {
+	sm := executor.NewSessionManager(config.GetStateDir())
+
+	run, sessions, err := sm.GetRun(args[0])
+	if err != nil {
+		return fmt.Errorf("run not found: %w", err)
+	}
+
+	var unfinished []executor.Session
+	for _, s := range sessions {
+		switch {
+		case s.Status == executor.StatusLost, s.Status == executor.StatusQueued, s.Status == executor.StatusPending:
+		case s.Status == executor.StatusKilled && resumeKilled:
+		default:
+			continue
+		}
+		unfinished = append(unfinished, s)
+	}
+
+	if len(unfinished) == 0 {
+		utils.PrintInfo(fmt.Sprintf("Run %s has no unfinished sessions", run.ID))
+		return nil
+	}
+
+	utils.PrintInfo(fmt.Sprintf("Resuming %d session(s) of run %s", len(unfinished), run.ID))
+	return restartSessions(unfinished)
+}
+
+func runRetry(cmd *cobra.Command, args []string) error {
+	stateDir := config.GetStateDir()
+	sm := executor.NewSessionManager(stateDir)
+
+	if len(args) > 0 && retryFailed {
+		return fmt.Errorf("give session IDs or --failed, not both")
+	}
+	if runFilter != "" && !retryFailed {
+		return fmt.Errorf("--run can only be used with --failed")
+	}
+
+	var sessions []executor.Session
+	if retryFailed {
+		all, err := sm.ListSessions("")
+		if err != nil {
+			return fmt.Errorf("failed to list sessions: %w", err)
+		}
+		if runFilter != "" {
+			if all, err = filterRun(stateDir, all); err != nil {
+				return err
+			}
+		}
+		for _, s := range all {
+			if s.Status.IsFailure() {
+				sessions = append(sessions, s)
+			}
+		}
+	} else {
+		if len(args) == 0 {
+			return fmt.Errorf("give session IDs or --failed")
+		}
+		for _, id := range args {
+			session, err := sm.GetSession(id)
+			if err != nil {
+				return fmt.Errorf("session not found: %w", err)
+			}
+			sessions = append(sessions, *session)
+		}
+	}
+
+	if len(sessions) == 0 {
+		utils.PrintInfo("No sessions to retry")
+		return nil
+	}
+
+	utils.PrintInfo(fmt.Sprintf("Retrying %d session(s)", len(sessions)))
+	return restartSessions(sessions)
+}
+
+// restartSessions queues sessions again and starts them within the
+// scheduler limits of the current config
+func restartSessions(sessions []executor.Session) error {
+	sched, err := requeueSessions(sessions)
+	if err != nil {
+		return err
+	}
+
+	started, interrupted, err := runScheduler(sched, len(sessions))
+	if err != nil || interrupted {
+		return err
+	}
+
+	fmt.Println()
+	utils.PrintSuccess(fmt.Sprintf("Successfully started %d/%d sessions", started, len(sessions)))
+
+	return nil
+}
+
+// requeueSessions checks that sessions can run again with the current
+// config and queues them. The returned scheduler starts them.
+func requeueSessions(sessions []executor.Session) (*executor.Scheduler, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w (run 'trident-recon init' first)", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	// Keep secret values out of everything written
+	registerSecrets(cfg)
+
+	for _, s := range sessions {
+		if s.Status == executor.StatusRunning {
+			return nil, fmt.Errorf("session %s is still running", s.ID)
+		}
+	}
+
+	stateDir := config.GetStateDir()
+	exec, err := newExecutor(cfg, stateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sessions run again in their own backend, unless --backend moves them
+	if backendFlag != "" {
+		for i := range sessions {
+			sessions[i].Backend = exec.Backend.Name()
+		}
+	}
+
+	if err := exec.ValidateSessions(sessions); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := checkSecrets(sessions); err != nil {
+		return nil, err
+	}
+
+	sched, err := newScheduler(cfg, exec)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sched.Requeue(sessions); err != nil {
+		return nil, err
+	}
+	return sched, nil
+}
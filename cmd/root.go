@@ -2,19 +2,27 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/bc0d3/trident-recon/pkg/backend"
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/targets"
 	"github.com/spf13/cobra"
 )
 
 var (
 	targetURL    string
 	targetList   string
+	inputFormat  string
 	outputDir    string
 	generateOnly bool
 	runCommands  bool
 	toolsFilter  []string
 	skipTools    []string
 	toolFilter   string
+	configFlag   string
+	stateDirFlag string
+	backendFlag  string
 	version      string
 	commit       string
 	date         string
@@ -25,9 +33,16 @@ var rootCmd = &cobra.Command{
 	Short: "🔱 Bug bounty tool orchestrator",
 	Long: `Trident Recon - Malleable reconnaissance tool orchestrator
 
-Generate and execute multiple recon tools in tmux background sessions.
+Generate and execute multiple recon tools in background tmux sessions.
 Manage your bug bounty workflow with ease.`,
 	Version: "1.0.0",
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		config.SetConfigPath(configFlag)
+		config.SetStateDir(stateDirFlag)
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		deliverEvents(cmd)
+	},
 }
 
 // Execute executes the root command
@@ -46,11 +61,15 @@ func SetVersionInfo(v, c, d string) {
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&targetURL, "url", "u", "", "Target URL")
 	rootCmd.PersistentFlags().StringVarP(&targetList, "list", "l", "", "File with list of targets")
+	rootCmd.PersistentFlags().StringVar(&inputFormat, "input-format", targets.FormatAuto, "Format of the --list file: "+strings.Join(targets.Formats(), ", "))
 	rootCmd.PersistentFlags().StringVarP(&outputDir, "output", "o", "", "Output directory")
 	rootCmd.PersistentFlags().BoolVarP(&generateOnly, "generate", "g", false, "Generate commands only (don't execute)")
 	rootCmd.PersistentFlags().BoolVarP(&runCommands, "run", "r", false, "Generate and run commands")
 	rootCmd.PersistentFlags().StringSliceVarP(&toolsFilter, "tools", "t", nil, "Run only specified tools (comma-separated)")
 	rootCmd.PersistentFlags().StringSliceVar(&skipTools, "skip", nil, "Skip specified tools (comma-separated)")
+	rootCmd.PersistentFlags().StringVar(&configFlag, "config", "", "Config file (default $XDG_CONFIG_HOME/trident-recon/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&backendFlag, "backend", "", "Where sessions run: "+strings.Join(backend.Names(), ", ")+" (default global.backend, else tmux)")
+	rootCmd.PersistentFlags().StringVar(&stateDirFlag, "state-dir", "", "State directory (default $XDG_STATE_HOME/trident-recon)")
 }
 
 func validateTargetFlags() error {
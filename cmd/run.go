@@ -1,33 +1,55 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
+	"github.com/bc0d3/trident-recon/pkg/backend"
 	"github.com/bc0d3/trident-recon/pkg/config"
 	"github.com/bc0d3/trident-recon/pkg/executor"
 	"github.com/bc0d3/trident-recon/pkg/generator"
+	"github.com/bc0d3/trident-recon/pkg/targets"
 	"github.com/bc0d3/trident-recon/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
 var runCmd = &cobra.Command{
 	Use:   "run",
-	Short: "Generate and execute commands in tmux sessions",
+	Short: "Generate and execute commands in background sessions",
 	Long: `Generate reconnaissance commands and execute them in background tmux sessions.
 
-This will create tmux sessions for each command and save session metadata.
+This will create a session for each command and save session metadata.
+Sessions run in tmux unless another backend is chosen with --backend or
+global.backend: screen, or process for machines without either (CI
+containers), whose sessions cannot be attached to.
+Sessions are queued and started as earlier ones finish, within the limits
+set in the scheduler section of the config. Targets take turns so a single
+host does not use every slot.
 You can monitor sessions using 'tmux attach' or 'trident-recon list'.
 
 Examples:
   trident-recon run -u http://example.com
   trident-recon run -u http://example.com -o ~/scans/target1
-  trident-recon run -l targets.txt --tools ffuf,gobuster`,
+  trident-recon run -l targets.txt --tools ffuf,gobuster
+  trident-recon run -l targets.txt --scope program-scope.yaml
+  trident-recon run -l targets.txt --profile deep
+  trident-recon run -l targets.txt --var username=alice
+  trident-recon run -u http://example.com --backend process`,
 	RunE: runRun,
 }
 
 func init() {
 	rootCmd.AddCommand(runCmd)
+	addScopeFlags(runCmd)
+	addProfileFlag(runCmd)
+	addVarFlag(runCmd)
+	runCmd.Flags().BoolVar(&allowUnthrottled, "allow-unthrottled", false, "Run sessions a rate budget covers even if their template has no {RATE}")
 }
 
 func runRun(cmd *cobra.Command, args []string) error {
@@ -43,11 +65,24 @@ func runRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w (run 'trident-recon init' first)", err)
 	}
 
+	// Apply profile overlay
+	if err := applyProfile(cfg); err != nil {
+		return err
+	}
+
 	// Validate config
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("invalid config: %w", err)
 	}
 
+	// Keep secret values out of everything written
+	registerSecrets(cfg)
+
+	// Check user variables
+	if err := checkVars(cfg); err != nil {
+		return err
+	}
+
 	// Get state directory
 	stateDir := config.GetStateDir()
 
@@ -57,71 +92,245 @@ func runRun(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// Drop targets outside the scope
+	targets, err = applyScope(cfg, targets)
+	if err != nil {
+		return err
+	}
+
 	utils.PrintSuccess(fmt.Sprintf("Found %d target(s)", len(targets)))
 	fmt.Println()
 
-	// Process each target
+	// Generate commands for each target
+	var allSessions []executor.Session
+	var runTargets []string
 	for i, target := range targets {
-		utils.PrintInfo(fmt.Sprintf("[%d/%d] Processing target: %s", i+1, len(targets), target))
+		utils.PrintInfo(fmt.Sprintf("[%d/%d] Processing target: %s", i+1, len(targets), target.URL))
 
-		if err := runForTarget(cfg, target, stateDir); err != nil {
-			utils.PrintError(fmt.Sprintf("Failed to run for %s: %v", target, err))
+		sessions, err := prepareTarget(cfg, target)
+		if err != nil {
+			utils.PrintError(fmt.Sprintf("Failed to run for %s: %v", target.URL, err))
 			continue
 		}
 
+		allSessions = append(allSessions, sessions...)
+		runTargets = append(runTargets, target.URL)
 		fmt.Println()
 	}
 
+	if len(allSessions) == 0 {
+		return fmt.Errorf("no commands to execute")
+	}
+
+	// Refuse sessions the rate budgets cannot hold back
+	budgets, err := loadBudgets(cfg)
+	if err != nil {
+		return err
+	}
+	if err := checkThrottled(budgets, allSessions); err != nil {
+		return err
+	}
+
+	// Execute sessions
+	exec, err := newExecutor(cfg, stateDir)
+	if err != nil {
+		return err
+	}
+	utils.PrintInfo(fmt.Sprintf("Executing commands in %s sessions...", exec.Backend.Name()))
+
+	// Validate sessions before execution
+	if err := exec.ValidateSessions(allSessions); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Fail early when a secret cannot be read
+	if err := checkSecrets(allSessions); err != nil {
+		return err
+	}
+
+	// Queue everything, then start sessions as slots and budgets free up
+	sched, err := newScheduler(cfg, exec)
+	if err != nil {
+		return err
+	}
+
+	// Record the run so its sessions can be listed, killed and deleted together
+	run := executor.Run{
+		ID:         executor.NewRunID(),
+		Targets:    runTargets,
+		Profile:    profileName,
+		ConfigHash: cfg.Hash(),
+		StartedAt:  time.Now(),
+		Sessions:   len(allSessions),
+	}
+	for i := range allSessions {
+		allSessions[i].RunID = run.ID
+	}
+
+	if err := sched.Enqueue(allSessions); err != nil {
+		return fmt.Errorf("failed to queue sessions: %w", err)
+	}
+
+	run.Refresh(allSessions)
+	if err := run.Save(stateDir); err != nil {
+		return fmt.Errorf("failed to save run: %w", err)
+	}
+
+	utils.PrintInfo(fmt.Sprintf("Run ID: %s", run.ID))
+
+	if cfg.Scheduler.MaxConcurrent > 0 {
+		utils.PrintInfo(fmt.Sprintf("Queued %d session(s) (max %d running at once)", len(allSessions), cfg.Scheduler.MaxConcurrent))
+	} else {
+		utils.PrintInfo(fmt.Sprintf("Queued %d session(s) (no global cap on running sessions)", len(allSessions)))
+	}
+
+	started, interrupted, err := runScheduler(sched, len(allSessions))
+	if err != nil {
+		return err
+	}
+	if interrupted {
+		utils.PrintInfo(fmt.Sprintf("Use 'trident-recon resume %s' to start them later", run.ID))
+		utils.PrintInfo(fmt.Sprintf("Use 'trident-recon runs kill %s' to drop its queued and running sessions", run.ID))
+		return nil
+	}
+
+	fmt.Println()
+	utils.PrintSuccess(fmt.Sprintf("Successfully started %d/%d sessions", started, len(allSessions)))
+	fmt.Println()
+	fmt.Println("📋 Session Management:")
+	fmt.Println("   List sessions:      trident-recon list")
+	fmt.Printf("   Show this run:      trident-recon runs show %s\n", run.ID)
+	fmt.Println("   Attach to session:  trident-recon attach [id]")
+	fmt.Println("   Kill all sessions:  trident-recon kill-all")
+
 	return nil
 }
 
-func runForTarget(cfg *config.Config, target, stateDir string) error {
+// newExecutor creates an executor running sessions in the backend chosen
+// with --backend, or else in the config
+func newExecutor(cfg *config.Config, stateDir string) (*executor.Executor, error) {
+	name := cfg.Global.Backend
+	if backendFlag != "" {
+		name = backendFlag
+	}
+
+	b, err := backend.New(name, stateDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Available(); err != nil {
+		return nil, err
+	}
+
+	exec := executor.NewExecutor(stateDir)
+	exec.Backend = b
+	return exec, nil
+}
+
+// newScheduler creates a scheduler with the limits and rate budgets of the
+// config
+func newScheduler(cfg *config.Config, exec *executor.Executor) (*executor.Scheduler, error) {
+	budgets, err := loadBudgets(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return executor.NewScheduler(exec, executor.SchedulerLimits{
+		MaxConcurrent: cfg.Scheduler.MaxConcurrent,
+		MaxPerHost:    cfg.Scheduler.MaxPerHost,
+		PerTool:       cfg.Scheduler.PerTool,
+		StartJitter:   cfg.Scheduler.StartJitter,
+		Budgets:       budgets,
+	}), nil
+}
+
+// runScheduler starts queued sessions until none is pending, printing each
+// start. It returns how many of the total sessions were started and whether
+// the user interrupted; interrupted sessions remain queued.
+func runScheduler(sched *executor.Scheduler, total int) (int, bool, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	started := make(map[string]bool)
+	err := sched.Run(ctx, func(session executor.Session) {
+		attempt := ""
+		if session.Attempts > 1 {
+			attempt = fmt.Sprintf(", attempt %d", session.Attempts)
+		}
+		if started[session.ID] {
+			utils.PrintSuccess(fmt.Sprintf("Restarted session %s (ID: %s%s)%s", session.TmuxSession, session.ID, attempt, shareNote(session)))
+			return
+		}
+		started[session.ID] = true
+		utils.PrintSuccess(fmt.Sprintf("[%d/%d] Started session %s (ID: %s%s)%s", len(started), total, session.TmuxSession, session.ID, attempt, shareNote(session)))
+	})
+	if errors.Is(err, context.Canceled) {
+		fmt.Println()
+		utils.PrintWarning(fmt.Sprintf("Interrupted: %d session(s) started, the rest remain queued in %s", len(started), sched.Executor.StateDir))
+		return len(started), true, nil
+	}
+	if err != nil {
+		return len(started), false, fmt.Errorf("execution failed: %w", err)
+	}
+
+	return len(started), false, nil
+}
+
+// prepareTarget generates the commands for a target and writes the
+// markdown and plain text files to its output directory
+func prepareTarget(cfg *config.Config, target targets.Target) ([]executor.Session, error) {
 	// Parse URL to get domain
-	_, domain, err := utils.ParseURL(target)
+	_, domain, err := utils.ParseURL(target.URL)
 	if err != nil {
-		return fmt.Errorf("invalid URL: %w", err)
+		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
-	domain = utils.SanitizeDomain(domain)
+	domain = utils.TargetKey(domain)
 
 	// Determine output directory
-	var outDir string
-	if outputDir != "" {
-		// User specified output directory: outputDir/domain/
-		outDir = filepath.Join(utils.ExpandPath(outputDir), domain)
-	} else {
-		// No output specified: use domain only
-		baseDir := utils.ExpandPath(cfg.Global.OutputDir)
-		outDir = filepath.Join(baseDir, domain)
-	}
+	outDir := targetOutputDir(cfg, domain)
 
 	// Create output directory
 	if err := utils.EnsureDir(outDir); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	utils.PrintSuccess(fmt.Sprintf("Output directory: %s", outDir))
 
+	// Resolve user variables
+	vars, err := targetVars(cfg, target)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := loadBudgets(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate commands
 	utils.PrintInfo("Generating commands...")
-	gen := generator.New(cfg, target, outDir)
+	gen := generator.New(cfg, target.URL, outDir)
+	gen.SetUserVars(vars)
+	gen.SetBudgets(budgets)
 	sessions, err := gen.Generate(toolsFilter, skipTools)
 	if err != nil {
-		return fmt.Errorf("failed to generate commands: %w", err)
+		return nil, fmt.Errorf("failed to generate commands: %w", err)
 	}
 
 	utils.PrintSuccess(fmt.Sprintf("Generated %d command(s)", len(sessions)))
 
 	// Save markdown file
 	mdGen := generator.MarkdownGenerator{
-		Target:    target,
+		Target:    target.URL,
 		OutputDir: outDir,
 		Sessions:  sessions,
+		Vars:      vars,
 	}
 
 	markdown := mdGen.Generate()
 	mdPath := filepath.Join(outDir, "comandos.md")
 	if err := utils.WriteFile(mdPath, markdown); err != nil {
-		return fmt.Errorf("failed to write markdown: %w", err)
+		return nil, fmt.Errorf("failed to write markdown: %w", err)
 	}
 
 	utils.PrintSuccess(fmt.Sprintf("Markdown saved to: %s", mdPath))
@@ -134,38 +343,10 @@ func runForTarget(cfg *config.Config, target, stateDir string) error {
 	plainText := txtGen.Generate()
 	txtPath := filepath.Join(outDir, "comandos.txt")
 	if err := utils.WriteFile(txtPath, plainText); err != nil {
-		return fmt.Errorf("failed to write plain text commands: %w", err)
+		return nil, fmt.Errorf("failed to write plain text commands: %w", err)
 	}
 
 	utils.PrintSuccess(fmt.Sprintf("Plain text commands saved to: %s", txtPath))
-	fmt.Println()
-
-	// Execute sessions
-	utils.PrintInfo("Executing commands in tmux sessions...")
-	exec := executor.NewExecutor(stateDir)
-
-	// Validate sessions before execution
-	if err := exec.ValidateSessions(sessions); err != nil {
-		return fmt.Errorf("validation failed: %w", err)
-	}
 
-	// Execute all sessions
-	successful, err := exec.ExecuteAll(sessions)
-	if err != nil {
-		return fmt.Errorf("execution failed: %w", err)
-	}
-
-	fmt.Println()
-	utils.PrintSuccess(fmt.Sprintf("Successfully started %d/%d sessions", successful, len(sessions)))
-	fmt.Println()
-	fmt.Println("📋 Session Management:")
-	fmt.Println("   List sessions:      trident-recon list")
-	fmt.Println("   Attach to session:  tmux attach -t <session-name>")
-	fmt.Println("   Kill all sessions:  trident-recon kill-all")
-	fmt.Println()
-	fmt.Printf("📁 Output directory:           %s\n", outDir)
-	fmt.Printf("📄 Markdown file:              %s\n", mdPath)
-	fmt.Printf("📄 Commands file (copy-paste): %s\n", txtPath)
-
-	return nil
+	return sessions, nil
 }
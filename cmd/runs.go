@@ -0,0 +1,360 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/results"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runFilter   string
+	deleteFiles bool
+)
+
+var runsCmd = &cobra.Command{
+	Use:   "runs",
+	Short: "List and manage past runs",
+	Long: `Every 'trident-recon run' gets a run ID that is attached to all of its
+sessions. A run records its targets, profile, config hash, start and end time
+and how many sessions ended in each status.
+
+Run IDs start with the time the run started. Like session IDs, a unique
+prefix is enough.
+
+Examples:
+  trident-recon runs list
+  trident-recon runs show 20250101-120000-ab12
+  trident-recon runs kill 20250101-120000
+  trident-recon runs delete 20250101-120000-ab12 --files`,
+}
+
+var runsListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List past runs",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE:    runRunsList,
+}
+
+var runsShowCmd = &cobra.Command{
+	Use:   "show <run-id>",
+	Short: "Show the sessions and findings of a run",
+	Long: `Show a run with its sessions and the findings they produced.
+
+Findings appear once the sessions were parsed with 'trident-recon ingest'.
+
+Examples:
+  trident-recon runs show 20250101-120000-ab12`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRunsShow,
+}
+
+var runsKillCmd = &cobra.Command{
+	Use:   "kill <run-id>",
+	Short: "Kill the queued and running sessions of a run",
+	Long: `Kill every queued and running session of a run.
+
+The sessions are kept in the state directory with status "killed".
+
+Examples:
+  trident-recon runs kill 20250101-120000-ab12`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRunsKill,
+}
+
+var runsDeleteCmd = &cobra.Command{
+	Use:   "delete <run-id>",
+	Short: "Delete a run with its sessions and findings",
+	Long: `Delete a run from the state directory together with its sessions and
+their findings. Sessions still queued or running are killed first.
+
+Output files and logs are kept unless --files is given.
+
+Examples:
+  trident-recon runs delete 20250101-120000-ab12
+  trident-recon runs delete 20250101-120000-ab12 --files`,
+	Aliases: []string{"rm"},
+	Args:    cobra.ExactArgs(1),
+	RunE:    runRunsDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(runsCmd)
+	runsCmd.AddCommand(runsListCmd)
+	runsCmd.AddCommand(runsShowCmd)
+	runsCmd.AddCommand(runsKillCmd)
+	runsCmd.AddCommand(runsDeleteCmd)
+	runsDeleteCmd.Flags().BoolVar(&deleteFiles, "files", false, "Also delete the output files and logs of the sessions")
+}
+
+func runRunsList(cmd *cobra.Command, args []string) error {
+	sm := executor.NewSessionManager(config.GetStateDir())
+
+	runs, err := sm.ListRuns()
+	if err != nil {
+		return fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	if len(runs) == 0 {
+		utils.PrintInfo("No runs found")
+		return nil
+	}
+
+	fmt.Printf("\n🔱 Trident Recon - Runs (%d)\n\n", len(runs))
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTARTED\tDURATION\tPROFILE\tTARGETS\tSESSIONS")
+	fmt.Fprintln(w, "──\t───────\t────────\t───────\t───────\t────────")
+
+	for _, r := range runs {
+		profile := r.Profile
+		if profile == "" {
+			profile = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.ID,
+			r.StartedAt.Format("2006-01-02 15:04"),
+			formatDuration(runDuration(r)),
+			profile,
+			truncate(describeTargets(r.Targets), 40),
+			formatCounts(r))
+	}
+
+	w.Flush()
+	fmt.Println()
+
+	utils.PrintInfo("Use 'trident-recon runs show <id>' to see the sessions and findings of a run")
+
+	return nil
+}
+
+func runRunsShow(cmd *cobra.Command, args []string) error {
+	stateDir := config.GetStateDir()
+	sm := executor.NewSessionManager(stateDir)
+
+	run, sessions, err := sm.GetRun(args[0])
+	if err != nil {
+		return fmt.Errorf("run not found: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "\n🔱 Run %s\n\n", run.ID)
+	fmt.Fprintf(out, "   Started:   %s\n", run.StartedAt.Format(time.RFC1123))
+	if !run.FinishedAt.IsZero() {
+		fmt.Fprintf(out, "   Finished:  %s (%s)\n", run.FinishedAt.Format(time.RFC1123), formatDuration(runDuration(*run)))
+	}
+	if run.Profile != "" {
+		fmt.Fprintf(out, "   Profile:   %s\n", run.Profile)
+	}
+	fmt.Fprintf(out, "   Config:    %s\n", run.ConfigHash)
+	fmt.Fprintf(out, "   Sessions:  %s\n", formatCounts(*run))
+	fmt.Fprintf(out, "   Targets:   %s\n\n", strings.Join(run.Targets, "\n              "))
+
+	if len(sessions) == 0 {
+		utils.PrintInfo("The sessions of this run were deleted")
+		return nil
+	}
+
+	printSessionsTable(out, sessions)
+	fmt.Fprintln(out)
+
+	findings, err := results.NewStore(stateDir).LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load findings: %w", err)
+	}
+	filter := results.Filter{Sessions: sessionIDs(sessions)}
+
+	return printResultsTable(out, results.Dedupe(filter.Apply(findings)))
+}
+
+func runRunsKill(cmd *cobra.Command, args []string) error {
+	sm := executor.NewSessionManager(config.GetStateDir())
+
+	run, sessions, err := sm.GetRun(args[0])
+	if err != nil {
+		return fmt.Errorf("run not found: %w", err)
+	}
+
+	active := countActive(sessions)
+	if active == 0 {
+		utils.PrintInfo(fmt.Sprintf("Run %s has no queued or running sessions", run.ID))
+		return nil
+	}
+
+	confirm, err := utils.PromptConfirm(fmt.Sprintf("Kill %d session(s) of run %s?", active, run.ID))
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		utils.PrintInfo("Operation cancelled")
+		return nil
+	}
+
+	killed, err := sm.KillRun(run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to kill run: %w", err)
+	}
+
+	utils.PrintSuccess(fmt.Sprintf("Killed %d session(s) of run %s", killed, run.ID))
+
+	return nil
+}
+
+func runRunsDelete(cmd *cobra.Command, args []string) error {
+	stateDir := config.GetStateDir()
+	sm := executor.NewSessionManager(stateDir)
+
+	run, sessions, err := sm.GetRun(args[0])
+	if err != nil {
+		return fmt.Errorf("run not found: %w", err)
+	}
+
+	msg := fmt.Sprintf("Delete run %s with its %d session(s) and their findings?", run.ID, len(sessions))
+	if active := countActive(sessions); active > 0 {
+		msg = fmt.Sprintf("Kill %d active session(s) and delete run %s with its %d session(s) and their findings?", active, run.ID, len(sessions))
+	}
+	if deleteFiles {
+		msg = strings.TrimSuffix(msg, "?") + ", output files and logs?"
+	}
+
+	confirm, err := utils.PromptConfirm(msg)
+	if err != nil {
+		return err
+	}
+	if !confirm {
+		utils.PrintInfo("Operation cancelled")
+		return nil
+	}
+
+	if _, err := sm.KillRun(run.ID); err != nil {
+		return fmt.Errorf("failed to kill run: %w", err)
+	}
+
+	removed, err := results.NewStore(stateDir).DeleteSessions(sessionIDs(sessions))
+	if err != nil {
+		return fmt.Errorf("failed to delete findings: %w", err)
+	}
+
+	for _, s := range sessions {
+		if deleteFiles {
+			if s.OutputFile != "" {
+				os.Remove(s.OutputFile)
+			}
+			for _, log := range s.AttemptLogs() {
+				os.Remove(log.Path)
+			}
+		}
+		if err := executor.Delete(stateDir, s.ID); err != nil && !os.IsNotExist(err) {
+			utils.PrintWarning(fmt.Sprintf("Failed to delete session %s: %v", s.ID, err))
+		}
+	}
+
+	if err := executor.DeleteRun(stateDir, run.ID); err != nil {
+		return fmt.Errorf("failed to delete run: %w", err)
+	}
+
+	utils.PrintSuccess(fmt.Sprintf("Deleted run %s: %d session(s), %d finding(s)", run.ID, len(sessions), removed))
+
+	return nil
+}
+
+// filterRun keeps the sessions of the --run run
+func filterRun(stateDir string, sessions []executor.Session) ([]executor.Session, error) {
+	runID, err := executor.ResolveRunID(stateDir, runFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []executor.Session
+	for _, s := range sessions {
+		if s.RunID == runID {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered, nil
+}
+
+// runSessionIDs returns the IDs of the sessions of the --run run
+func runSessionIDs(stateDir string) ([]string, error) {
+	_, sessions, err := executor.NewSessionManager(stateDir).GetRun(runFilter)
+	if err != nil {
+		return nil, err
+	}
+	return sessionIDs(sessions), nil
+}
+
+// sessionIDs returns the IDs of sessions; never nil, so an empty run
+// matches no findings
+func sessionIDs(sessions []executor.Session) []string {
+	ids := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func countActive(sessions []executor.Session) int {
+	active := 0
+	for _, s := range sessions {
+		if s.Status.IsActive() {
+			active++
+		}
+	}
+	return active
+}
+
+// runDuration returns how long a run took, or has been running so far
+func runDuration(r executor.Run) time.Duration {
+	if r.FinishedAt.IsZero() {
+		return time.Since(r.StartedAt)
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// describeTargets returns the first target and how many others there are
+func describeTargets(targets []string) string {
+	switch len(targets) {
+	case 0:
+		return "-"
+	case 1:
+		return targets[0]
+	default:
+		return fmt.Sprintf("%s (+%d)", targets[0], len(targets)-1)
+	}
+}
+
+// countOrder is the order statuses are listed in
+var countOrder = []executor.Status{
+	executor.StatusRunning,
+	executor.StatusQueued,
+	executor.StatusSucceeded,
+	executor.StatusFailed,
+	executor.StatusTimedOut,
+	executor.StatusKilled,
+	executor.StatusLost,
+	executor.StatusPending,
+}
+
+// formatCounts summarizes the sessions of a run by status, e.g.
+// "12: 3 running, 9 succeeded"
+func formatCounts(r executor.Run) string {
+	var parts []string
+	for _, status := range countOrder {
+		if n := r.Counts[status]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, status))
+		}
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("%d", r.Sessions)
+	}
+	return fmt.Sprintf("%d: %s", r.Sessions, strings.Join(parts, ", "))
+}
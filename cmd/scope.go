@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/scope"
+	"github.com/bc0d3/trident-recon/pkg/targets"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scopeFile   string
+	ignoreScope bool
+)
+
+// addScopeFlags registers the flags understood by applyScope
+func addScopeFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&scopeFile, "scope", "", "Scope file with include/exclude rules (added to the config scope)")
+	cmd.Flags().BoolVar(&ignoreScope, "ignore-scope", false, "Process targets even if they are out of scope")
+}
+
+// loadScope combines the scope section of the config with the --scope file
+func loadScope(cfg *config.Config) (*scope.Scope, error) {
+	s, err := scope.New(cfg.Scope.Include, cfg.Scope.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	if scopeFile != "" {
+		fileScope, err := scope.LoadFile(utils.ExpandPath(scopeFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load scope file: %w", err)
+		}
+		s.Include = append(s.Include, fileScope.Include...)
+		s.Exclude = append(s.Exclude, fileScope.Exclude...)
+	}
+
+	return s, nil
+}
+
+// applyScope drops out-of-scope targets and reports them. It fails when no
+// target is left, unless --ignore-scope was given.
+func applyScope(cfg *config.Config, list []targets.Target) ([]targets.Target, error) {
+	s, err := loadScope(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.IsEmpty() {
+		return list, nil
+	}
+
+	var inScope []targets.Target
+	var skipped int
+	for _, target := range list {
+		ok, reason := s.Check(target.URL)
+		if ok {
+			inScope = append(inScope, target)
+			continue
+		}
+
+		if ignoreScope {
+			utils.PrintWarning(fmt.Sprintf("Out of scope (ignored): %s - %s", target.URL, reason))
+			inScope = append(inScope, target)
+			continue
+		}
+
+		utils.PrintWarning(fmt.Sprintf("Out of scope: %s - %s", target.URL, reason))
+		skipped++
+	}
+
+	if skipped == 0 {
+		return inScope, nil
+	}
+
+	if len(inScope) == 0 {
+		return nil, fmt.Errorf("all %d target(s) are out of scope (use --ignore-scope to override)", len(list))
+	}
+
+	utils.PrintWarning(fmt.Sprintf("Skipping %d out-of-scope target(s) (use --ignore-scope to override)", skipped))
+	return inScope, nil
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// registerSecrets reads the configured secrets so their values are redacted
+// from every file and log trident writes. Secrets that cannot be read are
+// skipped here; run reports them through checkSecrets.
+func registerSecrets(cfg *config.Config) {
+	for _, ref := range cfg.Secrets {
+		if value, err := ref.Resolve(); err == nil {
+			utils.AddRedaction(value)
+		}
+	}
+}
+
+// checkSecrets makes sure every secret the sessions need can be read before
+// anything is started
+func checkSecrets(sessions []executor.Session) error {
+	checked := make(map[string]bool)
+	for _, session := range sessions {
+		for name, ref := range session.Secrets {
+			if checked[name] {
+				continue
+			}
+			checked[name] = true
+
+			if _, err := ref.Resolve(); err != nil {
+				return fmt.Errorf("secret %s (%s) is needed by %s - %s: %w", name, ref, session.Tool, session.CommandName, err)
+			}
+		}
+	}
+	return nil
+}
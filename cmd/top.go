@@ -0,0 +1,680 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/results"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	topInterval     time.Duration
+	topTargetFilter string
+)
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Live full-screen view of all sessions",
+	Long: `Show all sessions in a full-screen view that refreshes on its own, with
+their status, elapsed time, progress, output file size, finding count and
+the last line they printed. The last lines of the selected session are shown below
+the table.
+
+Keys:
+  ↑/↓ j/k PgUp/PgDn   select a session
+  enter               attach if running, otherwise show the log
+  a                   attach to the tmux or screen session
+  l                   show the whole log in $PAGER (default less)
+  x                   kill the session
+  r                   retry the session
+  t / h / u           filter by tool, target or run (empty clears)
+  c                   clear all filters
+  q                   quit
+
+Sessions retried from here are started as slots free up while top runs.
+Finding counts appear once sessions were parsed with 'ingest' or 'watch'.
+
+Examples:
+  trident-recon top
+  trident-recon top --tool ffuf
+  trident-recon top --run 20250101-120000-ab12 --interval 5s`,
+	Args: cobra.NoArgs,
+	RunE: runTop,
+}
+
+func init() {
+	rootCmd.AddCommand(topCmd)
+	topCmd.Flags().DurationVar(&topInterval, "interval", 2*time.Second, "Time between refreshes")
+	topCmd.Flags().StringVar(&toolFilter, "tool", "", "Only sessions of this tool")
+	topCmd.Flags().StringVar(&topTargetFilter, "target", "", "Only sessions whose target contains this")
+	topCmd.Flags().StringVar(&runFilter, "run", "", "Only sessions of this run (a unique prefix is enough)")
+}
+
+func runTop(cmd *cobra.Command, args []string) error {
+	if topInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	// Secrets echoed by a tool are redacted when the config can be read
+	if cfg, err := config.Load(); err == nil {
+		registerSecrets(cfg)
+	}
+
+	stateDir := config.GetStateDir()
+	d := &dashboard{
+		stateDir: stateDir,
+		sm:       executor.NewSessionManager(stateDir),
+		tool:     toolFilter,
+		target:   topTargetFilter,
+		run:      runFilter,
+	}
+	if d.run != "" {
+		runID, err := executor.ResolveRunID(stateDir, d.run)
+		if err != nil {
+			return err
+		}
+		d.run = runID
+	}
+
+	term, err := utils.OpenTerminal()
+	if err != nil {
+		return fmt.Errorf("top needs an interactive terminal: %w", err)
+	}
+	d.term = term
+	defer term.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return d.loop(ctx)
+}
+
+// dashboard is the state of the top view
+type dashboard struct {
+	stateDir string
+	sm       *executor.SessionManager
+	term     *utils.Terminal
+	sched    *executor.Scheduler // Starts sessions retried from the view
+
+	tool, target, run string // Filters
+
+	all      []executor.Session
+	sessions []executor.Session // Sessions passing the filters
+	findings map[string]int     // Finding count per session ID
+	selected string             // ID of the selected session
+	cursor   int                // Index of the selected session
+	offset   int                // Index of the first row shown
+
+	message   string
+	prompt    *topPrompt
+	refreshed time.Time
+	frame     string // Last frame drawn
+}
+
+// topPrompt reads a line at the bottom of the view, or a y/n answer
+type topPrompt struct {
+	label   string
+	value   string
+	confirm bool
+	done    func(string)
+}
+
+func (d *dashboard) loop(ctx context.Context) error {
+	for {
+		if time.Since(d.refreshed) >= topInterval {
+			d.refresh()
+		}
+		d.draw()
+
+		key, err := d.term.ReadKey(200 * time.Millisecond)
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if key != "" && !d.handle(key) {
+			return nil
+		}
+	}
+}
+
+// refresh reloads the sessions and their findings
+func (d *dashboard) refresh() {
+	d.refreshed = time.Now()
+
+	if d.sched != nil {
+		if _, pending, err := d.sched.Tick(); err != nil {
+			d.message = fmt.Sprintf("Scheduling failed: %v", err)
+		} else if pending == 0 {
+			d.sched = nil
+		}
+	}
+
+	sessions, _, err := executor.RefreshAll(d.stateDir)
+	if err != nil {
+		d.message = fmt.Sprintf("Failed to load sessions: %v", err)
+		return
+	}
+	d.all = sessions
+
+	d.findings = make(map[string]int)
+	if findings, err := results.NewStore(d.stateDir).LoadAll(); err == nil {
+		for _, f := range findings {
+			d.findings[f.SessionID]++
+		}
+	}
+
+	d.applyFilters()
+}
+
+// applyFilters picks the sessions to show and keeps the selection on the
+// same session where possible
+func (d *dashboard) applyFilters() {
+	d.sessions = d.sessions[:0]
+	target := strings.ToLower(d.target)
+	for _, s := range d.all {
+		if d.tool != "" && s.Tool != d.tool {
+			continue
+		}
+		if target != "" && !strings.Contains(strings.ToLower(s.Target), target) {
+			continue
+		}
+		if d.run != "" && !strings.HasPrefix(s.RunID, d.run) {
+			continue
+		}
+		d.sessions = append(d.sessions, s)
+	}
+
+	for i, s := range d.sessions {
+		if s.ID == d.selected {
+			d.cursor = i
+			return
+		}
+	}
+	d.move(0)
+}
+
+// move moves the selection by delta rows
+func (d *dashboard) move(delta int) {
+	d.cursor += delta
+	if d.cursor >= len(d.sessions) {
+		d.cursor = len(d.sessions) - 1
+	}
+	if d.cursor < 0 {
+		d.cursor = 0
+	}
+	d.selected = ""
+	if len(d.sessions) > 0 {
+		d.selected = d.sessions[d.cursor].ID
+	}
+}
+
+// current returns the selected session
+func (d *dashboard) current() *executor.Session {
+	if len(d.sessions) == 0 {
+		return nil
+	}
+	return &d.sessions[d.cursor]
+}
+
+// handle acts on a key and reports whether to keep running
+func (d *dashboard) handle(key string) bool {
+	if d.prompt != nil {
+		d.handlePrompt(key)
+		return true
+	}
+
+	d.message = ""
+	_, height := d.term.Size()
+	page := d.tableHeight(height)
+
+	switch key {
+	case "q", utils.KeyCtrlC:
+		return false
+	case utils.KeyUp, "k":
+		d.move(-1)
+	case utils.KeyDown, "j":
+		d.move(1)
+	case utils.KeyPageUp:
+		d.move(-page)
+	case utils.KeyPageDown:
+		d.move(page)
+	case utils.KeyHome:
+		d.move(-len(d.sessions))
+	case utils.KeyEnd:
+		d.move(len(d.sessions))
+	case utils.KeyEnter:
+		if s := d.current(); s != nil && s.Status == executor.StatusRunning {
+			d.attach()
+		} else {
+			d.showLog()
+		}
+	case "a":
+		d.attach()
+	case "l":
+		d.showLog()
+	case "x":
+		d.kill()
+	case "r":
+		d.retry()
+	case "t":
+		d.ask("Tool", d.tool, func(v string) { d.tool = v })
+	case "h":
+		d.ask("Target", d.target, func(v string) { d.target = v })
+	case "u":
+		d.ask("Run", d.run, func(v string) { d.run = v })
+	case "c":
+		d.tool, d.target, d.run = "", "", ""
+		d.applyFilters()
+	}
+	return true
+}
+
+// ask prompts for a filter value
+func (d *dashboard) ask(label, value string, set func(string)) {
+	d.prompt = &topPrompt{label: label, value: value, done: func(v string) {
+		set(strings.TrimSpace(v))
+		d.applyFilters()
+	}}
+}
+
+func (d *dashboard) handlePrompt(key string) {
+	p := d.prompt
+	if p.confirm {
+		d.prompt = nil
+		if key == "y" || key == "Y" {
+			p.done("y")
+		}
+		return
+	}
+
+	switch key {
+	case utils.KeyEnter:
+		d.prompt = nil
+		p.done(p.value)
+	case utils.KeyEscape, utils.KeyCtrlC:
+		d.prompt = nil
+	case utils.KeyBackspace:
+		if runes := []rune(p.value); len(runes) > 0 {
+			p.value = string(runes[:len(runes)-1])
+		}
+	default:
+		if len([]rune(key)) == 1 {
+			p.value += key
+		}
+	}
+}
+
+// attach hands the terminal to the backend session of the selected session
+func (d *dashboard) attach() {
+	s := d.current()
+	if s == nil {
+		return
+	}
+	if s.Status != executor.StatusRunning {
+		d.message = fmt.Sprintf("Session %s is %s, not running", s.ID, s.Status)
+		return
+	}
+
+	d.suspend(func() error {
+		if err := d.sm.AttachToSession(s.ID); err != nil {
+			return fmt.Errorf("failed to attach to %s: %w", s.ID, err)
+		}
+		return nil
+	})
+}
+
+// showLog pages through the whole log of the selected session
+func (d *dashboard) showLog() {
+	s := d.current()
+	if s == nil {
+		return
+	}
+	output, err := d.sm.ReadLog(s)
+	if err != nil {
+		d.message = err.Error()
+		return
+	}
+
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less -R +G"
+	}
+	d.suspend(func() error {
+		cmd := exec.Command("sh", "-c", pager)
+		cmd.Stdin = strings.NewReader(utils.Redact(output))
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	})
+}
+
+// suspend leaves the full-screen view while fn uses the terminal
+func (d *dashboard) suspend(fn func() error) {
+	if err := d.term.Suspend(); err != nil {
+		d.message = err.Error()
+		return
+	}
+	err := fn()
+	if resumeErr := d.term.Resume(); resumeErr != nil && err == nil {
+		err = resumeErr
+	}
+	if err != nil {
+		d.message = err.Error()
+	}
+	d.frame = ""
+	d.refreshed = time.Time{}
+}
+
+// kill asks to kill the selected session
+func (d *dashboard) kill() {
+	s := d.current()
+	if s == nil {
+		return
+	}
+	if !s.Status.IsActive() {
+		d.message = fmt.Sprintf("Session %s is %s, nothing to kill", s.ID, s.Status)
+		return
+	}
+
+	id := s.ID
+	d.prompt = &topPrompt{
+		label:   fmt.Sprintf("Kill %s (%s - %s on %s)? [y/N]", id, s.Tool, s.CommandName, s.Target),
+		confirm: true,
+		done: func(string) {
+			if err := d.sm.KillSession(id); err != nil {
+				d.message = fmt.Sprintf("Failed to kill session: %v", err)
+				return
+			}
+			d.message = fmt.Sprintf("Session %s killed", id)
+			d.refreshed = time.Time{}
+		},
+	}
+}
+
+// retry queues the selected session again; the view starts it within the
+// scheduler limits
+func (d *dashboard) retry() {
+	s := d.current()
+	if s == nil {
+		return
+	}
+	if !s.Status.IsTerminal() {
+		d.message = fmt.Sprintf("Session %s is %s, only ended sessions can be retried", s.ID, s.Status)
+		return
+	}
+
+	sched, err := requeueSessions([]executor.Session{*s})
+	if err != nil {
+		d.message = err.Error()
+		d.frame = ""
+		return
+	}
+	d.sched = sched
+	d.message = fmt.Sprintf("Session %s queued again", s.ID)
+	d.frame = ""
+	d.refreshed = time.Time{}
+}
+
+// tableHeight returns how many session rows fit on the screen
+func (d *dashboard) tableHeight(height int) int {
+	rows := height - 4 - d.tailHeight(height)
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+// tailHeight returns how many log lines of the selected session are shown
+func (d *dashboard) tailHeight(height int) int {
+	lines := (height - 5) / 3
+	if lines < 1 {
+		lines = 1
+	}
+	return lines
+}
+
+// draw renders the view, skipping the write when nothing changed
+func (d *dashboard) draw() {
+	width, height := d.term.Size()
+	rows := d.tableHeight(height)
+
+	if d.cursor < d.offset {
+		d.offset = d.cursor
+	}
+	if d.cursor >= d.offset+rows {
+		d.offset = d.cursor - rows + 1
+	}
+	if last := len(d.sessions) - rows; d.offset > last {
+		d.offset = last
+	}
+	if d.offset < 0 {
+		d.offset = 0
+	}
+
+	var lines []string
+	lines = append(lines, "\x1b[1m"+clip(d.title(), width)+"\x1b[22m")
+
+	cols := topColumns(d.sessions, width)
+	lines = append(lines, "\x1b[4m"+clip(cols.header(), width)+"\x1b[24m")
+
+	for i := d.offset; i < d.offset+rows; i++ {
+		if i >= len(d.sessions) {
+			lines = append(lines, "")
+			continue
+		}
+		s := d.sessions[i]
+		var last string
+		if tail, err := d.sm.TailLog(&s, 1); err == nil && len(tail) > 0 {
+			last = utils.Redact(tail[0])
+		}
+		row := cols.row(s, d.findings[s.ID], last, width)
+		if i == d.cursor {
+			row = "\x1b[7m" + row + "\x1b[27m"
+		}
+		lines = append(lines, row)
+	}
+
+	lines = append(lines, d.tail(width, d.tailHeight(height))...)
+	lines = append(lines, d.footer(width))
+
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString("\x1b[K")
+	}
+	b.WriteString("\x1b[J")
+
+	if frame := b.String(); frame != d.frame {
+		d.frame = frame
+		os.Stdout.WriteString(frame)
+	}
+}
+
+// title summarizes the sessions shown and the filters
+func (d *dashboard) title() string {
+	counts := make(map[executor.Status]int)
+	for _, s := range d.sessions {
+		counts[s.Status]++
+	}
+
+	var parts []string
+	for _, status := range []executor.Status{
+		executor.StatusRunning, executor.StatusQueued, executor.StatusSucceeded, executor.StatusFailed,
+		executor.StatusTimedOut, executor.StatusKilled, executor.StatusLost, executor.StatusPending,
+	} {
+		if n := counts[status]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, status))
+		}
+	}
+
+	title := fmt.Sprintf("trident-recon top · %d session(s)", len(d.sessions))
+	if len(parts) > 0 {
+		title += ": " + strings.Join(parts, ", ")
+	}
+
+	var filters []string
+	for _, f := range []struct{ name, value string }{{"tool", d.tool}, {"target", d.target}, {"run", d.run}} {
+		if f.value != "" {
+			filters = append(filters, f.name+"="+f.value)
+		}
+	}
+	if len(filters) > 0 {
+		title += " · " + strings.Join(filters, " ")
+	}
+	return title + " · " + d.refreshed.Format("15:04:05")
+}
+
+// tail renders the last lines of the selected session
+func (d *dashboard) tail(width, height int) []string {
+	s := d.current()
+	if s == nil {
+		return append([]string{strings.Repeat("─", width)}, make([]string, height)...)
+	}
+
+	header := fmt.Sprintf("── %s · %s - %s · %s ", s.ID, s.Tool, s.CommandName, s.Target)
+	if p := s.Progress; p != nil {
+		header += fmt.Sprintf("· %d/%d requests, %.0f/s, %d errors ", p.Processed, p.Total, p.Rate, p.Errors)
+	}
+	if s.Error != "" {
+		header += "· " + s.Error + " "
+	}
+	if n := width - len([]rune(header)); n > 0 {
+		header += strings.Repeat("─", n)
+	}
+	lines := []string{clip(header, width)}
+
+	tail, err := d.sm.TailLog(s, height)
+	if err != nil {
+		tail = []string{err.Error()}
+	}
+	for len(tail) < height {
+		tail = append(tail, "")
+	}
+	for _, line := range tail {
+		lines = append(lines, clip(utils.Redact(line), width))
+	}
+	return lines
+}
+
+// footer renders the prompt, the last message or the key help
+func (d *dashboard) footer(width int) string {
+	switch {
+	case d.prompt != nil && d.prompt.confirm:
+		return clip(d.prompt.label, width)
+	case d.prompt != nil:
+		return clip(d.prompt.label+" filter (enter to apply, esc to cancel): "+d.prompt.value, width-1) + "█"
+	case d.message != "":
+		return clip(d.message, width)
+	}
+	return "\x1b[2m" + clip("enter attach/log · a attach · l log · x kill · r retry · t/h/u filter tool/target/run · c clear · q quit", width) + "\x1b[22m"
+}
+
+// topTable holds the column widths of the session table
+type topTable struct {
+	tool, command, target int
+}
+
+// topColumns sizes the variable columns to their content, within limits
+func topColumns(sessions []executor.Session, width int) topTable {
+	cols := topTable{tool: len("TOOL"), command: len("COMMAND"), target: len("TARGET")}
+	for _, s := range sessions {
+		cols.tool = max(cols.tool, len(s.Tool))
+		cols.command = max(cols.command, len(s.CommandName))
+		cols.target = max(cols.target, len(s.Target))
+	}
+	cols.tool = min(cols.tool, 14)
+	cols.command = min(cols.command, 20)
+	cols.target = min(cols.target, 32)
+	return cols
+}
+
+func (c topTable) header() string {
+	return fmt.Sprintf("  %-12s  %-*s  %-*s  %-9s  %8s  %-13s  %7s  %5s  %-*s  %s",
+		"ID", c.tool, "TOOL", c.command, "COMMAND", "STATUS", "ELAPSED", "PROGRESS", "OUTPUT", "FOUND", c.target, "TARGET", "LAST OUTPUT")
+}
+
+func (c topTable) row(s executor.Session, findings int, last string, width int) string {
+	found := "-"
+	if findings > 0 {
+		found = fmt.Sprintf("%d", findings)
+	}
+
+	status := fmt.Sprintf("%-9s", s.Status)
+	line := fmt.Sprintf("  %-12s  %-*s  %-*s  %s  %8s  %-13s  %7s  %5s  %-*s  %s",
+		s.ID, c.tool, truncate(s.Tool, c.tool), c.command, truncate(s.CommandName, c.command), status,
+		formatDuration(s.Elapsed()), formatProgress(s), outputSize(s.OutputFile), found, c.target, truncate(s.Target, c.target), last)
+
+	// Color the status once the line is cut to the screen, so the escape
+	// codes do not count towards its width
+	line = clip(line, width)
+	if color := statusColor(s.Status); color != "" {
+		line = strings.Replace(line, status, color+status+"\x1b[39m", 1)
+	}
+	return line
+}
+
+// statusColor returns the escape code a status is shown in
+func statusColor(status executor.Status) string {
+	switch status {
+	case executor.StatusRunning:
+		return "\x1b[36m"
+	case executor.StatusSucceeded:
+		return "\x1b[32m"
+	case executor.StatusFailed, executor.StatusTimedOut, executor.StatusLost:
+		return "\x1b[31m"
+	case executor.StatusKilled:
+		return "\x1b[33m"
+	}
+	return ""
+}
+
+// outputSize returns the size of an output file in a short human form
+func outputSize(path string) string {
+	if path == "" {
+		return "-"
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "-"
+	}
+
+	size := float64(info.Size())
+	for _, unit := range []string{"B", "K", "M", "G"} {
+		if size < 1024 || unit == "G" {
+			if unit == "B" {
+				return fmt.Sprintf("%.0f%s", size, unit)
+			}
+			return fmt.Sprintf("%.1f%s", size, unit)
+		}
+		size /= 1024
+	}
+	return "-"
+}
+
+// clip cuts a line to the screen width
+func clip(s string, width int) string {
+	runes := []rune(s)
+	if width < 0 {
+		width = 0
+	}
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width])
+}
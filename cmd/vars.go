@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/generator"
+	"github.com/bc0d3/trident-recon/pkg/targets"
+	"github.com/spf13/cobra"
+)
+
+var cliVars []string
+
+// addVarFlag registers the repeatable --var flag understood by targetVars
+func addVarFlag(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&cliVars, "var", nil, "Set a template variable {VAR-name} as name=value (repeatable)")
+}
+
+// parseCLIVars parses the --var flags
+func parseCLIVars() (map[string]string, error) {
+	vars := make(map[string]string)
+	for _, pair := range cliVars {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --var %q (expected name=value)", pair)
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+// checkVars validates the config, profile and --var variables before any
+// target is processed
+func checkVars(cfg *config.Config) error {
+	_, err := targetVars(cfg, targets.Target{})
+	return err
+}
+
+// targetVars resolves the user variables of a target. Precedence, lowest
+// first: config vars, profile vars, the target line, --var.
+func targetVars(cfg *config.Config, target targets.Target) (map[string]generator.UserVar, error) {
+	cli, err := parseCLIVars()
+	if err != nil {
+		return nil, err
+	}
+
+	layers := []generator.VarLayer{{Source: generator.VarSourceConfig, Vars: cfg.Vars}}
+	if profileName != "" {
+		layers = append(layers, generator.VarLayer{
+			Source: fmt.Sprintf("%s %s", generator.VarSourceProfile, profileName),
+			Vars:   cfg.Profiles[profileName].Vars,
+		})
+	}
+	layers = append(layers,
+		generator.VarLayer{Source: generator.VarSourceTarget, Vars: target.Vars},
+		generator.VarLayer{Source: generator.VarSourceCLI, Vars: cli},
+	)
+
+	return generator.ResolveUserVars(layers...)
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/notify"
+	"github.com/bc0d3/trident-recon/pkg/results"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchInterval time.Duration
+	watchOnce     bool
+	watchStop     bool
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Supervise sessions in the background",
+	Long: `Keep an eye on all sessions until stopped: record sessions that end, start
+queued sessions within the scheduler limits, parse the output of finished
+sessions into findings and send notifications.
+
+Only one watcher runs per state directory; its PID is kept in watch.pid.
+SIGINT or SIGTERM (or 'watch --stop') stops it after the current pass.
+With --once a single pass is made, e.g. from cron.
+
+Examples:
+  trident-recon watch
+  nohup trident-recon watch > ~/trident-watch.log 2>&1 &
+  trident-recon watch --once
+  trident-recon watch --stop`,
+	Args: cobra.NoArgs,
+	RunE: runWatch,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 10*time.Second, "Time between passes")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "Make a single pass and exit")
+	watchCmd.Flags().BoolVar(&watchStop, "stop", false, "Stop the running watcher")
+}
+
+// watchPIDFile returns the pidfile of the watcher, which it also holds a
+// lock on while running
+func watchPIDFile(stateDir string) string {
+	return filepath.Join(stateDir, "watch.pid")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	stateDir := config.GetStateDir()
+	if watchStop {
+		return stopWatch(stateDir)
+	}
+	if watchInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w (run 'trident-recon init' first)", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	// Keep secret values out of everything written
+	registerSecrets(cfg)
+
+	lock, err := utils.TryLockFile(watchPIDFile(stateDir))
+	if errors.Is(err, utils.ErrLocked) {
+		return fmt.Errorf("a watcher is already running (pid %s)", readWatchPID(stateDir))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock %s: %w", watchPIDFile(stateDir), err)
+	}
+	defer func() {
+		os.Remove(watchPIDFile(stateDir))
+		lock.Unlock()
+	}()
+
+	pidFile := lock.File()
+	if err := pidFile.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := pidFile.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		return err
+	}
+
+	exec, err := newExecutor(cfg, stateDir)
+	if err != nil {
+		return err
+	}
+	sched, err := newScheduler(cfg, exec)
+	if err != nil {
+		return err
+	}
+	notifier, err := notify.New(cfg)
+	if err != nil {
+		return err
+	}
+
+	w := &watcher{
+		stateDir: stateDir,
+		sched:    sched,
+		notifier: notifier,
+		statuses: make(map[string]executor.Status),
+	}
+
+	if watchOnce {
+		return w.pass()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	w.log(utils.PrintInfo, fmt.Sprintf("Watching %s every %s (pid %d)", stateDir, watchInterval, os.Getpid()))
+	for {
+		if err := w.pass(); err != nil {
+			w.log(utils.PrintError, err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			w.log(utils.PrintInfo, "Stopped")
+			return nil
+		case <-time.After(watchInterval):
+		}
+	}
+}
+
+// watcher is the state kept between passes of 'watch'
+type watcher struct {
+	stateDir string
+	sched    *executor.Scheduler
+	notifier *notify.Notifier
+	statuses map[string]executor.Status // Last seen status per session
+}
+
+// pass makes one supervision pass: record ended sessions and start queued
+// ones, report what changed, parse finished outputs and send notifications
+func (w *watcher) pass() error {
+	started, _, err := w.sched.Tick()
+	if err != nil {
+		return fmt.Errorf("scheduling failed: %w", err)
+	}
+	for _, s := range started {
+		w.statuses[s.ID] = s.Status
+		w.log(utils.PrintSuccess, fmt.Sprintf("Started %s - %s on %s (ID: %s)%s", s.Tool, s.CommandName, s.Target, s.ID, shareNote(s)))
+	}
+
+	sessions, err := executor.LoadAll(w.stateDir)
+	if err != nil {
+		return fmt.Errorf("failed to load sessions: %w", err)
+	}
+
+	for _, s := range sessions {
+		last, seen := w.statuses[s.ID]
+		w.statuses[s.ID] = s.Status
+		if seen && last != s.Status {
+			w.log(statusPrinter(s.Status), fmt.Sprintf("%s - %s on %s (ID: %s): %s -> %s", s.Tool, s.CommandName, s.Target, s.ID, last, s.Status))
+		}
+	}
+
+	for _, s := range sessions {
+		if s.NeedsParsing() && utils.FileExists(s.OutputFile) {
+			w.parse(s)
+		}
+	}
+
+	if _, err := w.notifier.Flush(w.stateDir); err != nil {
+		return fmt.Errorf("failed to send notifications: %w", err)
+	}
+	return nil
+}
+
+// parse ingests the output of a finished session and notifies its new
+// findings. Sessions without a parser are marked too, so they are reported
+// once.
+func (w *watcher) parse(s executor.Session) {
+	count, fresh, err := results.Ingest(w.stateDir, s)
+	if err != nil {
+		w.log(utils.PrintWarning, fmt.Sprintf("Not parsing %s (%s - %s): %v", s.ID, s.Tool, s.CommandName, err))
+	} else {
+		w.log(utils.PrintInfo, fmt.Sprintf("Parsed %s (%s - %s): %d finding(s), %d new", s.ID, s.Tool, s.CommandName, count, len(fresh)))
+		w.notifier.NotifyFindings(s, fresh)
+	}
+
+	if err := executor.MarkParsed(w.stateDir, s.ID); err != nil {
+		w.log(utils.PrintWarning, fmt.Sprintf("Failed to mark %s as parsed: %v", s.ID, err))
+	}
+}
+
+// log prints a line with the time, as the watcher usually writes to a file
+func (w *watcher) log(print func(string), msg string) {
+	print(time.Now().Format("2006-01-02 15:04:05") + " " + msg)
+}
+
+// statusPrinter picks how a status change is printed
+func statusPrinter(status executor.Status) func(string) {
+	switch status {
+	case executor.StatusSucceeded:
+		return utils.PrintSuccess
+	case executor.StatusFailed, executor.StatusTimedOut, executor.StatusLost:
+		return utils.PrintError
+	default:
+		return utils.PrintInfo
+	}
+}
+
+// readWatchPID returns the PID recorded by the running watcher
+func readWatchPID(stateDir string) string {
+	data, err := os.ReadFile(watchPIDFile(stateDir))
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// stopWatch asks the running watcher to stop and waits for it
+func stopWatch(stateDir string) error {
+	lock, err := utils.TryLockFile(watchPIDFile(stateDir))
+	if err == nil {
+		// Nobody holds the lock, so any pidfile left is stale
+		os.Remove(watchPIDFile(stateDir))
+		lock.Unlock()
+		utils.PrintInfo("No watcher is running")
+		return nil
+	}
+	if !errors.Is(err, utils.ErrLocked) {
+		return err
+	}
+
+	pid, err := strconv.Atoi(readWatchPID(stateDir))
+	if err != nil {
+		return fmt.Errorf("invalid pid in %s", watchPIDFile(stateDir))
+	}
+	if err := syscall.Kill(pid, syscall.SIGTERM); err != nil {
+		return fmt.Errorf("failed to stop watcher (pid %d): %w", pid, err)
+	}
+
+	// The watcher finishes its current pass first
+	for i := 0; i < 300; i++ {
+		lock, err := utils.TryLockFile(watchPIDFile(stateDir))
+		if err == nil {
+			lock.Unlock()
+			utils.PrintSuccess(fmt.Sprintf("Stopped watcher (pid %d)", pid))
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("watcher (pid %d) did not stop within 30s", pid)
+}
@@ -0,0 +1,80 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Backend names
+const (
+	Tmux    = "tmux"
+	Screen  = "screen"
+	Process = "process"
+)
+
+// ErrNotLogged is returned (wrapped) by Start when the session started but
+// its output could not be recorded
+var ErrNotLogged = errors.New("output is not recorded")
+
+// Backend runs session commands detached from the terminal and keeps track
+// of them by name
+type Backend interface {
+	// Name returns the name the backend is selected by
+	Name() string
+
+	// Available reports why the backend cannot be used, if it cannot
+	Available() error
+
+	// Start runs argv detached as the session name. When logCommand is
+	// set, everything the session prints is piped to it as a shell command,
+	// e.g. "cat >> file".
+	Start(name string, argv []string, logCommand string) error
+
+	// Exists reports whether the session is still alive
+	Exists(name string) bool
+
+	// List returns the names of all live sessions
+	List() ([]string, error)
+
+	// Kill stops the session and everything it started
+	Kill(name string) error
+
+	// Attach hands the terminal over to the session until the user detaches
+	Attach(name string) error
+
+	// Capture returns what the session shows on its screen, including
+	// scrollback where the backend keeps it
+	Capture(name string) (string, error)
+}
+
+// constructors creates the backends by name. Backends that track sessions
+// themselves keep their files in the state directory.
+var constructors = map[string]func(stateDir string) Backend{
+	Tmux:    func(string) Backend { return tmuxBackend{} },
+	Screen:  func(stateDir string) Backend { return screenBackend{stateDir: stateDir} },
+	Process: func(stateDir string) Backend { return processBackend{stateDir: stateDir} },
+}
+
+// New returns the backend with the given name; an empty name is tmux, which
+// sessions recorded before backends existed ran in
+func New(name, stateDir string) (Backend, error) {
+	if name == "" {
+		name = Tmux
+	}
+	construct, ok := constructors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend %q (available: %v)", name, Names())
+	}
+	return construct(stateDir), nil
+}
+
+// Names returns the names of all backends
+func Names() []string {
+	names := make([]string, 0, len(constructors))
+	for name := range constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
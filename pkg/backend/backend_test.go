@@ -0,0 +1,88 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+func TestNew(t *testing.T) {
+	for _, name := range append(Names(), "") {
+		b, err := New(name, t.TempDir())
+		if err != nil {
+			t.Fatalf("New(%q): %v", name, err)
+		}
+		if name != "" && b.Name() != name {
+			t.Errorf("New(%q).Name() = %q", name, b.Name())
+		}
+	}
+	if b, _ := New("", t.TempDir()); b.Name() != Tmux {
+		t.Errorf("default backend is %q, want tmux", b.Name())
+	}
+	if _, err := New("docker", t.TempDir()); err == nil {
+		t.Error("New accepted an unknown backend")
+	}
+}
+
+// waitFor polls cond for up to 5s
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		if cond() {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestProcessBackend(t *testing.T) {
+	dir := t.TempDir()
+	b, err := New(Process, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(dir, "session.log")
+
+	argv := []string{"sh", "-c", `echo "$1"; exec sleep 60`, "sh", "hello world"}
+	if err := b.Start("long", argv, "cat >> "+utils.ShellQuote(log)); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := b.Start("short", []string{"true"}, ""); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if !b.Exists("long") {
+		t.Fatal("started session does not exist")
+	}
+	waitFor(t, "the log", func() bool {
+		data, _ := os.ReadFile(log)
+		return string(data) == "hello world\n"
+	})
+	waitFor(t, "the short session to end", func() bool { return !b.Exists("short") })
+
+	names, err := b.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "long" {
+		t.Errorf("List() = %v, want [long]", names)
+	}
+	if utils.FileExists(filepath.Join(dir, "procs", "short.pid")) {
+		t.Error("List kept the pid file of an ended session")
+	}
+
+	if err := b.Kill("long"); err != nil {
+		t.Fatalf("Kill: %v", err)
+	}
+	if b.Exists("long") {
+		t.Error("killed session still exists")
+	}
+	if err := b.Attach("long"); err == nil {
+		t.Error("Attach succeeded without a terminal")
+	}
+}
@@ -0,0 +1,210 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// processBackend runs sessions as plain background processes, for machines
+// and containers without tmux or screen. Each session gets its own process
+// group, whose ID is kept in <state>/procs/<name>.pid. Sessions have no
+// terminal: they cannot be attached to and their output is only in the log.
+type processBackend struct {
+	stateDir string
+}
+
+// killTimeout is how long Kill waits for a session to exit after SIGTERM
+// before it is killed outright
+const killTimeout = 5 * time.Second
+
+func (processBackend) Name() string { return Process }
+
+func (processBackend) Available() error {
+	if _, err := exec.LookPath("sh"); err != nil {
+		return fmt.Errorf("sh is not available: %w", err)
+	}
+	return nil
+}
+
+func (b processBackend) pidFile(name string) string {
+	return filepath.Join(b.stateDir, "procs", name+".pid")
+}
+
+func (b processBackend) Start(name string, argv []string, logCommand string) error {
+	script := `"$@" >/dev/null 2>&1`
+	if logCommand != "" {
+		script = `"$@" 2>&1 | ` + logCommand
+	}
+
+	// Stdin and stdout default to /dev/null; the new session detaches the
+	// process from the terminal so it outlives trident-recon
+	cmd := exec.Command("sh", append([]string{"-c", script, "sh"}, argv...)...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start process: %w", err)
+	}
+	// Reap it if it ends while this process still runs
+	go cmd.Wait()
+
+	pid := []byte(strconv.Itoa(cmd.Process.Pid) + "\n")
+	if err := os.MkdirAll(filepath.Dir(b.pidFile(name)), 0755); err != nil {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		return err
+	}
+	if err := utils.WriteFileAtomic(b.pidFile(name), pid, 0644); err != nil {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		return fmt.Errorf("failed to record pid: %w", err)
+	}
+	return nil
+}
+
+// pgid returns the process group of a session
+func (b processBackend) pgid(name string) (int, error) {
+	data, err := os.ReadFile(b.pidFile(name))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// Exists reports whether any process of the session's group is alive
+func (b processBackend) Exists(name string) bool {
+	pgid, err := b.pgid(name)
+	if err != nil {
+		return false
+	}
+	return groupAlive(pgid, liveGroups())
+}
+
+// groupAlive reports whether a process group has a process that is not a
+// zombie. Without a list of live groups it falls back to signal 0, which
+// also finds zombies.
+func groupAlive(pgid int, live map[int]bool) bool {
+	if live != nil {
+		return live[pgid]
+	}
+	err := syscall.Kill(-pgid, 0)
+	return err == nil || err == syscall.EPERM
+}
+
+// liveGroups returns the process groups that have a process which is not a
+// zombie, from /proc or else ps, or nil when neither can be read. Ended
+// sessions stay zombies until init reaps them, which the init of many
+// containers never does.
+func liveGroups() map[int]bool {
+	if live, err := procGroups(); err == nil {
+		return live
+	}
+
+	output, err := exec.Command("ps", "-A", "-o", "pgid=,stat=").Output()
+	if err != nil {
+		return nil
+	}
+	live := make(map[int]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[1], "Z") {
+			continue
+		}
+		if pgid, err := strconv.Atoi(fields[0]); err == nil {
+			live[pgid] = true
+		}
+	}
+	return live
+}
+
+// procGroups reads the live process groups from /proc/<pid>/stat, which
+// reads "pid (comm) state ppid pgrp ..."
+func procGroups() (map[int]bool, error) {
+	stats, err := filepath.Glob("/proc/[0-9]*/stat")
+	if err != nil || len(stats) == 0 {
+		return nil, fmt.Errorf("/proc is not available")
+	}
+
+	live := make(map[int]bool)
+	for _, path := range stats {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			// The process ended meanwhile
+			continue
+		}
+		stat := string(data)
+		fields := strings.Fields(stat[strings.LastIndexByte(stat, ')')+1:])
+		if len(fields) < 3 || fields[0] == "Z" {
+			continue
+		}
+		if pgid, err := strconv.Atoi(fields[2]); err == nil {
+			live[pgid] = true
+		}
+	}
+	return live, nil
+}
+
+// List returns the live sessions and removes the pid files of ended ones
+func (b processBackend) List() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(b.stateDir, "procs"))
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	live := liveGroups()
+	names := []string{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".pid")
+		if !ok {
+			continue
+		}
+		if pgid, err := b.pgid(name); err == nil && groupAlive(pgid, live) {
+			names = append(names, name)
+			continue
+		}
+		os.Remove(b.pidFile(name))
+	}
+	return names, nil
+}
+
+func (b processBackend) Kill(name string) error {
+	pgid, err := b.pgid(name)
+	if err != nil {
+		return fmt.Errorf("no process recorded for %s: %w", name, err)
+	}
+	defer os.Remove(b.pidFile(name))
+
+	if err := syscall.Kill(-pgid, syscall.SIGTERM); err != nil {
+		if err == syscall.ESRCH {
+			return nil
+		}
+		return err
+	}
+
+	deadline := time.Now().Add(killTimeout)
+	for time.Now().Before(deadline) {
+		if !groupAlive(pgid, liveGroups()) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err := syscall.Kill(-pgid, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+		return err
+	}
+	return nil
+}
+
+func (processBackend) Attach(name string) error {
+	return fmt.Errorf("sessions of the process backend have no terminal to attach to, follow their log with 'trident-recon logs -f' instead")
+}
+
+func (processBackend) Capture(name string) (string, error) {
+	return "", fmt.Errorf("sessions of the process backend have no screen to capture, their output is only in the log")
+}
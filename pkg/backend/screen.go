@@ -0,0 +1,159 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"syscall"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// screenBackend runs sessions in GNU screen (4.06 or later). screen can
+// only log to a file, so the log goes through a FIFO in <state>/procs that
+// the log command reads from.
+type screenBackend struct {
+	stateDir string
+}
+
+// screenSession matches a session in the output of 'screen -ls', e.g.
+// "	12345.ffuf_abc123	(Detached)"
+var screenSession = regexp.MustCompile(`(?m)^\s+\d+\.(\S+)\s+\(`)
+
+func (screenBackend) Name() string { return Screen }
+
+func (screenBackend) Available() error {
+	if _, err := exec.LookPath("screen"); err != nil {
+		return fmt.Errorf("screen is not installed or not available")
+	}
+	return nil
+}
+
+func (b screenBackend) fifo(name string) string {
+	return filepath.Join(b.stateDir, "procs", name+".fifo")
+}
+
+func (b screenBackend) Start(name string, argv []string, logCommand string) error {
+	args := []string{"-dmS", name}
+
+	var reader *exec.Cmd
+	var logErr error
+	if logCommand != "" {
+		reader, logErr = b.startReader(name, logCommand)
+		if logErr == nil {
+			args = append(args, "-L", "-Logfile", b.fifo(name))
+		}
+	}
+
+	cmd := exec.Command("screen", append(args, argv...)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if reader != nil {
+			syscall.Kill(-reader.Process.Pid, syscall.SIGKILL)
+			os.Remove(b.fifo(name))
+		}
+		return fmt.Errorf("failed to create screen session: %w: %s", err, output)
+	}
+
+	if logErr != nil {
+		return fmt.Errorf("%w: %v", ErrNotLogged, logErr)
+	}
+	if reader != nil {
+		// screen flushes its log every 10s by default
+		exec.Command("screen", "-S", name, "-X", "logfile", "flush", "1").Run()
+	}
+	return nil
+}
+
+// startReader creates the FIFO screen logs to and starts the log command
+// reading from it. The reader ends, and removes the FIFO, when screen
+// closes the log as the session ends.
+func (b screenBackend) startReader(name, logCommand string) (*exec.Cmd, error) {
+	fifo := b.fifo(name)
+	if err := os.MkdirAll(filepath.Dir(fifo), 0755); err != nil {
+		return nil, err
+	}
+	os.Remove(fifo)
+	if err := syscall.Mkfifo(fifo, 0600); err != nil {
+		return nil, err
+	}
+
+	quoted := utils.ShellQuote(fifo)
+	reader := exec.Command("sh", "-c", fmt.Sprintf("%s < %s; rm -f %s", logCommand, quoted, quoted))
+	reader.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := reader.Start(); err != nil {
+		os.Remove(fifo)
+		return nil, err
+	}
+	go reader.Wait()
+	return reader, nil
+}
+
+func (b screenBackend) Exists(name string) bool {
+	names, err := b.List()
+	if err != nil {
+		return false
+	}
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (screenBackend) List() ([]string, error) {
+	// screen -ls exits non-zero even when it lists sessions
+	output, err := exec.Command("screen", "-ls").Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return nil, err
+		}
+	}
+
+	names := []string{}
+	for _, m := range screenSession.FindAllStringSubmatch(string(output), -1) {
+		names = append(names, m[1])
+	}
+	return names, nil
+}
+
+func (screenBackend) Kill(name string) error {
+	return exec.Command("screen", "-S", name, "-X", "quit").Run()
+}
+
+// Attach uses multi-display mode, so sessions attached elsewhere are
+// shared rather than refused
+func (screenBackend) Attach(name string) error {
+	cmd := exec.Command("screen", "-x", name)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// Capture asks screen for a hardcopy of the window with its scrollback,
+// which it writes asynchronously
+func (screenBackend) Capture(name string) (string, error) {
+	file, err := os.CreateTemp("", "trident-hardcopy-*")
+	if err != nil {
+		return "", err
+	}
+	path := file.Name()
+	file.Close()
+	os.Remove(path)
+	defer os.Remove(path)
+
+	if err := exec.Command("screen", "-S", name, "-X", "hardcopy", "-h", path).Run(); err != nil {
+		return "", err
+	}
+	for i := 0; i < 20; i++ {
+		if data, err := os.ReadFile(path); err == nil && len(data) > 0 {
+			return string(data), nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return "", fmt.Errorf("screen did not write a hardcopy of %s", name)
+}
@@ -0,0 +1,52 @@
+package backend
+
+import (
+	"fmt"
+
+	"github.com/bc0d3/trident-recon/pkg/tmux"
+)
+
+// tmuxBackend runs sessions in tmux, recording their pane with pipe-pane
+type tmuxBackend struct{}
+
+func (tmuxBackend) Name() string { return Tmux }
+
+func (tmuxBackend) Available() error {
+	if !tmux.IsTmuxAvailable() {
+		return fmt.Errorf("tmux is not installed or not available")
+	}
+	return nil
+}
+
+func (tmuxBackend) Start(name string, argv []string, logCommand string) error {
+	if err := tmux.CreateSession(name, argv...); err != nil {
+		return fmt.Errorf("failed to create tmux session: %w", err)
+	}
+	if logCommand == "" {
+		return nil
+	}
+	if err := tmux.PipePane(name, logCommand); err != nil {
+		return fmt.Errorf("%w: %v", ErrNotLogged, err)
+	}
+	return nil
+}
+
+func (tmuxBackend) Exists(name string) bool {
+	return tmux.SessionExists(name)
+}
+
+func (tmuxBackend) List() ([]string, error) {
+	return tmux.ListSessions()
+}
+
+func (tmuxBackend) Kill(name string) error {
+	return tmux.KillSession(name)
+}
+
+func (tmuxBackend) Attach(name string) error {
+	return tmux.AttachSession(name)
+}
+
+func (tmuxBackend) Capture(name string) (string, error) {
+	return tmux.CapturePane(name)
+}
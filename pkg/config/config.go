@@ -1,25 +1,126 @@
 package config
 
 import (
-	"fmt"
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/bc0d3/trident-recon/pkg/secrets"
+	"github.com/bc0d3/trident-recon/pkg/utils"
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the main configuration structure
 type Config struct {
-	Global    GlobalConfig          `yaml:"global"`
-	Headers   HeadersConfig         `yaml:"headers"`
-	Tools     map[string]ToolConfig `yaml:"tools"`
-	Wordlists map[string]string     `yaml:"wordlists"`
+	Global        GlobalConfig           `yaml:"global"`
+	Headers       HeadersConfig          `yaml:"headers"`
+	Tools         map[string]ToolConfig  `yaml:"tools"`
+	Wordlists     map[string]string      `yaml:"wordlists"`
+	Scheduler     SchedulerConfig        `yaml:"scheduler"`
+	Budget        BudgetConfig           `yaml:"budget"`
+	Scope         ScopeConfig            `yaml:"scope"`
+	Profiles      map[string]Profile     `yaml:"profiles"`
+	Vars          map[string]string      `yaml:"vars"`    // User variables, rendered as {VAR-name}
+	Secrets       map[string]secrets.Ref `yaml:"secrets"` // Rendered as {SECRET-name}, delivered via the environment
+	Notifications NotificationsConfig    `yaml:"notifications"`
 }
 
 // GlobalConfig contains global settings
 type GlobalConfig struct {
 	OutputDir string `yaml:"output_dir"`
 	IDLength  int    `yaml:"id_length"`
+	Rate      int    `yaml:"rate"`    // Requests per second, rendered as {RATE}
+	Threads   int    `yaml:"threads"` // Threads per tool, rendered as {THREADS}
+	Backend   string `yaml:"backend"` // Where sessions run: tmux (default), screen or process
+}
+
+// DefaultMaxConcurrent caps running sessions when scheduler.max_concurrent
+// is not set
+const DefaultMaxConcurrent = 10
+
+// SchedulerConfig controls how many sessions run at the same time
+type SchedulerConfig struct {
+	MaxConcurrent int            `yaml:"max_concurrent"` // 0 = unlimited, DefaultMaxConcurrent when absent
+	MaxPerHost    int            `yaml:"max_per_host"`
+	PerTool       map[string]int `yaml:"per_tool"`
+	StartJitter   time.Duration  `yaml:"start_jitter"`
+}
+
+// BudgetConfig caps the requests per second and threads sent to a host or
+// a program. Each budget is split among the sessions running against it and
+// rendered as {RATE} and {THREADS}.
+type BudgetConfig struct {
+	PerHost  Budget                   `yaml:"per_host"`
+	Programs map[string]ProgramBudget `yaml:"programs"`
+}
+
+// Budget is a requests per second and threads limit (0 = none)
+type Budget struct {
+	Rate    int `yaml:"rate"`
+	Threads int `yaml:"threads"`
+}
+
+// ProgramBudget is a budget shared by every target in the program's scope
+type ProgramBudget struct {
+	Scope  []string `yaml:"scope"` // Scope rules, e.g. *.example.com
+	Budget `yaml:",inline"`
+}
+
+// ScopeConfig lists the targets that may be scanned. Exclude rules win over
+// include rules; with no include rules everything not excluded is allowed.
+type ScopeConfig struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// NotificationsConfig lists the channels notifications are sent to
+type NotificationsConfig struct {
+	Channels map[string]ChannelConfig `yaml:"channels"`
+}
+
+// Channel types
+const (
+	ChannelWebhook = "webhook" // Generic JSON webhook
+	ChannelSlack   = "slack"   // Slack-compatible incoming webhook
+	ChannelDiscord = "discord" // Discord-compatible webhook
+	ChannelEmail   = "email"   // Email over SMTP
+)
+
+// NotificationEvents are the events channels can subscribe to
+var NotificationEvents = []string{
+	"session.succeeded",
+	"session.failed",
+	"session.timed-out",
+	"session.lost",
+	"run.finished",
+	"finding.new",
+}
+
+// ChannelConfig is a notification channel with its filters and message
+// templates. Filters left empty match everything.
+type ChannelConfig struct {
+	Type      string     `yaml:"type"`       // webhook, slack, discord or email
+	URL       string     `yaml:"url"`        // Webhook URL
+	URLSecret string     `yaml:"url_secret"` // Or the name of a secret holding the URL
+	SMTP      SMTPConfig `yaml:"smtp"`       // Mail server and recipients for email channels
+	Events    []string   `yaml:"events"`     // Events to send (default: all)
+	Tools     []string   `yaml:"tools"`      // Only sessions and findings of these tools
+	Targets   []string   `yaml:"targets"`    // Only targets matching these scope rules
+	MinLevel  string     `yaml:"min_level"`  // Lowest finding level sent: note, warning (default) or error
+	Title     string     `yaml:"title"`      // Template for the title or email subject
+	Body      string     `yaml:"body"`       // Template for the message body
+}
+
+// SMTPConfig is the mail server email notifications are sent through
+type SMTPConfig struct {
+	Host           string   `yaml:"host"`
+	Port           int      `yaml:"port"` // Default 587
+	Username       string   `yaml:"username"`
+	PasswordSecret string   `yaml:"password_secret"` // Name of a secret holding the password
+	From           string   `yaml:"from"`
+	To             []string `yaml:"to"`
 }
 
 // HeadersConfig contains HTTP headers configuration
@@ -37,39 +138,81 @@ type ToolConfig struct {
 
 // CommandTemplate represents a command template
 type CommandTemplate struct {
-	Name           string `yaml:"name"`
-	Description    string `yaml:"description"`
-	Command        string `yaml:"command"`
-	Wordlist       string `yaml:"wordlist"`
-	UseDomainList  bool   `yaml:"use_domain_list"`
+	Name          string        `yaml:"name"`
+	Description   string        `yaml:"description"`
+	Command       string        `yaml:"command"` // Run by bash
+	Args          []string      `yaml:"args"`    // Or run directly as an argv list, without a shell
+	Wordlist      string        `yaml:"wordlist"`
+	UseDomainList bool          `yaml:"use_domain_list"`
+	Timeout       time.Duration `yaml:"timeout"`
+	Retries       int           `yaml:"retries"` // Automatic retries after a failure, timeout or loss
+	Backoff       time.Duration `yaml:"backoff"` // Wait before the first retry, doubled after each one
+}
+
+// Paths given with --config and --state-dir
+var (
+	configPathOverride string
+	stateDirOverride   string
+)
+
+// SetConfigPath makes GetConfigPath return path (from --config)
+func SetConfigPath(path string) {
+	configPathOverride = path
 }
 
-// Load reads and parses the config file
+// SetStateDir makes GetStateDir return dir (from --state-dir)
+func SetStateDir(dir string) {
+	stateDirOverride = dir
+}
+
+// Load reads the user config, merges the project config over it and applies
+// environment overrides
 func Load() (*Config, error) {
-	home, _ := os.UserHomeDir()
-	configPath := filepath.Join(home, ".config", "trident-recon", "config.yaml")
+	cfg, _, err := LoadWithSources()
+	return cfg, err
+}
 
-	data, err := os.ReadFile(configPath)
+// Hash returns a short fingerprint of the effective config, so runs made
+// with different settings can be told apart
+func (c *Config) Hash() string {
+	data, err := yaml.Marshal(c)
 	if err != nil {
-		return nil, fmt.Errorf("config not found at %s: %w", configPath, err)
-	}
-
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("error parsing config: %w", err)
+		return ""
 	}
-
-	return &cfg, nil
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
 }
 
-// GetConfigPath returns the config file path
+// GetConfigPath returns the user config file path: --config, $TRIDENT_CONFIG,
+// $XDG_CONFIG_HOME/trident-recon/config.yaml or ~/.config/trident-recon/config.yaml
 func GetConfigPath() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".config", "trident-recon", "config.yaml")
+	if configPathOverride != "" {
+		return utils.ExpandPath(configPathOverride)
+	}
+	if path := os.Getenv("TRIDENT_CONFIG"); path != "" {
+		return utils.ExpandPath(path)
+	}
+	return filepath.Join(xdgDir("XDG_CONFIG_HOME", ".config"), "trident-recon", "config.yaml")
 }
 
-// GetStateDir returns the state directory path
+// GetStateDir returns the state directory path: --state-dir, $TRIDENT_STATE_DIR,
+// $XDG_STATE_HOME/trident-recon or ~/.local/state/trident-recon
 func GetStateDir() string {
+	if stateDirOverride != "" {
+		return utils.ExpandPath(stateDirOverride)
+	}
+	if dir := os.Getenv("TRIDENT_STATE_DIR"); dir != "" {
+		return utils.ExpandPath(dir)
+	}
+	return filepath.Join(xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state")), "trident-recon")
+}
+
+// xdgDir returns an XDG base directory, falling back to a path in the home
+// directory. Relative values are ignored as the spec requires.
+func xdgDir(env, fallback string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
 	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".local", "state", "trident-recon")
+	return filepath.Join(home, fallback)
 }
@@ -35,6 +35,24 @@ const DefaultConfig = `# Trident Recon Configuration File - OPTIMIZED FOR BUG BO
 #                  Contains list of all domains being scanned (one per line)
 #                  Only available when using -l flag with multiple targets
 #
+# {RATE}         - Requests per second from global.rate or the --profile
+# {THREADS}      - Threads per tool from global.threads or the --profile
+#                  Empty when unset, so templates use {THREADS | default 100}
+#                  With a budget (see budget: below) they are the session's
+#                  share of it instead
+#
+# {VAR-<name>}   - User variable, e.g. {VAR-username} or {VAR-cookie}
+#                  Set under vars: below, in a profile, on a target line
+#                  (https://a.com  vars: cookie="sid=abc" user=bob) or with
+#                  --var name=value. Later sources win:
+#                  config vars < profile vars < target line < --var
+#                  Unset variables are empty, so use {if VAR-cookie} or default
+#
+# {SECRET-<name>} - A secret from the secrets: section, rendered as a
+#                  reference like ${TRIDENT_SECRET_SESSION_COOKIE}. It works
+#                  bare, inside quotes and in header values, but not in args.
+#                  Headers holding one only reach tools via {HEADERS-FILE}
+#
 # ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 # HEADER VARIABLES - Dynamic based on your config.yaml:
 # ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
@@ -57,6 +75,42 @@ const DefaultConfig = `# Trident Recon Configuration File - OPTIMIZED FOR BUG BO
 # {HEADERS-CUSTOM}  - Only custom headers from headers.custom section
 #                     Example: -H "X-Bug-Bounty: hackeroneUser"
 #
+# {HEADERS}         - Every header as "Name: value", for use with join
+#                     Headers holding a secret are left out
+#                     Example: {HEADERS | join ";"}
+#
+# {HEADERS-FILE}    - Path of a private file with every header as
+#                     "Name: value", secrets filled in, removed when the
+#                     session ends. The only way to send a header holding a
+#                     {SECRET-name}: the -H flags above refuse such headers,
+#                     since ps shows a tool's arguments to every local user.
+#                     Example: dirsearch -u {URL} --headers-file {HEADERS-FILE}
+#                              curl -H @{HEADERS-FILE} {URL}
+#
+# ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+# TEMPLATE SYNTAX:
+# ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
+#
+# An unknown placeholder (e.g. a typo like {WORDLST}) or one that is empty
+# stops generation with the tool and command name. Header groups may be empty.
+#
+# Values are quoted for where they appear: bare, inside '...' or inside "..."
+# a target containing ' $( ) ; or a space stays one plain argument.
+#
+# {WORDLIST | basename}          - Helpers: lower, quote, raw, basename, join "sep"
+# {VAR-extra | raw}              - Inserted untouched; only for values you trust
+# {DOMAIN | lower | quote}       - Helpers can be chained
+# {DOMAIN_LIST | default "-"}    - Fallback when the value is empty
+# {if WORDLIST}-w {WORDLIST}{else}-w common.txt{end}
+#                                - Conditionals, also {if !NAME}
+#
+# Other braces (awk '{print $1}', ${HOME}, xargs {}) are left as they are.
+#
+# args: ["httpx", "-u", "{URL}"] - Instead of command:, runs the tool directly
+#                                  without a shell, one placeholder per argument.
+#                                  HEADER-*, HEADERS-*, SECRET-* and raw are
+#                                  not allowed there.
+#
 # Usage Examples:
 #   ffuf -u {URL}/FUZZ {HEADERS-ALL} -w wordlist.txt
 #   gobuster dir -u {URL} {HEADER-User-Agent} {HEADER-X-Bug-Bounty} -w wordlist.txt
@@ -98,12 +152,119 @@ const DefaultConfig = `# Trident Recon Configuration File - OPTIMIZED FOR BUG BO
 # Run commands (execute in tmux):
 #   trident-recon run -u http://example.com
 #   trident-recon run -l targets.txt -o ~/scans/project-name
+#   trident-recon run -u http://example.com --backend process   # without tmux
 #
 # ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
 
 global:
   output_dir: ~/trident-output
   id_length: 12
+  rate: 0                # {RATE} in templates (0 = use each template's default)
+  threads: 0             # {THREADS} in templates (0 = use each template's default)
+  backend: tmux          # Where sessions run: tmux, screen or process (no terminal,
+                         # for CI and boxes without tmux); overridden by --backend
+
+# Scheduler - limits how many sessions 'run' keeps alive at once.
+# Sessions over the limit are saved as "queued" and started as others finish,
+# taking turns between targets so one host does not hog every slot.
+scheduler:
+  max_concurrent: 10     # Global cap on running sessions (0 = no cap)
+  max_per_host: 4        # Cap per target host (0 = no per-host cap)
+  start_jitter: 2s       # Random delay (0-2s) between session starts
+  per_tool:              # Optional cap per tool
+    ffuf: 4
+    feroxbuster: 2
+
+# Rate budgets - requests per second and threads shared by all sessions
+# against one host or one program. Each budget is split evenly among the
+# sessions that can run at once and rendered as {RATE} and {THREADS}; later
+# sessions get a bigger share as earlier ones finish. Only templates with
+# {RATE} can be held to a rate budget: 'run' refuses the others (gobuster,
+# which has no rate option, and hardcoded rates) unless --allow-unthrottled.
+budget:
+  per_host:
+    rate: 0              # Requests per second per host (0 = no budget)
+    threads: 0           # Threads per host (0 = no budget)
+  programs:              # Shared by every target in the program's scope
+    # acme:
+    #   scope: ["acme.com", "*.acme.com"]
+    #   rate: 300
+
+# Targets outside the scope are refused by generate and run
+# (override with --ignore-scope). Rules: example.com, *.example.com,
+# 10.0.0.0/24, example.com:8443, example.com/api (and below, not /apiv2),
+# https://example.com (https only)
+scope:
+  include: []            # Empty = everything not excluded
+  exclude: []
+
+# User variables - available in templates as {VAR-<name>}
+vars:
+  # username: "hunter2"
+  # cookie: "session=..."
+
+# Secrets - cookies and tokens read from an environment variable or a file
+# with mode 0600. 'run' hands them to sessions through the environment, never
+# on the session command line, and their values are redacted from every file
+# written. Headers holding a secret are only sent through {HEADERS-FILE}; a
+# {SECRET-name} used as a tool argument in a template is visible with ps.
+secrets:
+  # session_cookie:
+  #   env: H1_COOKIE
+  # api_token:
+  #   file: ~/.config/trident-recon/api_token
+
+# Notifications - sent when sessions succeed, fail, time out or are lost
+# (session.succeeded, session.failed, session.timed-out, session.lost), when
+# a run finishes (run.finished) and when 'ingest' finds URLs new for a target
+# (finding.new). Filters left out match everything. title and body are Go
+# templates over the event: .Title and .Text hold the default message,
+# .Session, .Run and .Findings the details.
+notifications:
+  channels:
+    # team-slack:
+    #   type: slack                 # webhook, slack, discord or email
+    #   url_secret: slack_webhook   # A secret holding the URL, or url: ...
+    #   events: [session.failed, session.timed-out, run.finished, finding.new]
+    #   targets: ["*.example.com"]  # Scope rules
+    #   tools: [ffuf]
+    #   min_level: warning          # Lowest finding level: note, warning or error
+    # mail:
+    #   type: email
+    #   smtp:
+    #     host: smtp.example.com
+    #     port: 587
+    #     username: alerts@example.com
+    #     password_secret: smtp_password
+    #     from: alerts@example.com
+    #     to: [me@example.com]
+    #   events: [run.finished]
+    #   title: "Run {{.Run.ID}} done"
+    #   body: "{{.Text}}"
+
+# Profiles - overlays selected with --profile <name>.
+# tools/commands pick what runs (everything else is disabled), rate/threads
+# replace the global values, headers, wordlists and vars are merged by name.
+profiles:
+  quick:
+    description: "Fast triage with small wordlists"
+    commands:
+      ffuf: [quickhits, common, backup-files]
+      gobuster: [dir-enum-fast]
+    threads: 50
+    rate: 50
+  deep:
+    description: "Large wordlists and recursion"
+    tools: [ffuf, feroxbuster, dirsearch]
+    threads: 100
+  api:
+    description: "API endpoints, docs and GraphQL only"
+    commands:
+      ffuf: [api-endpoints, api-endpoints-v2, swagger-docs, graphql-endpoints]
+      feroxbuster: [api-scan]
+    headers:
+      default:
+        Accept: "application/json"
 
 headers:
   default:
@@ -113,6 +274,7 @@ headers:
     Accept-Encoding: "gzip, deflate"
   custom:
     - "X-Bug-Bounty: hackeroneUser"
+    # - "Cookie: {SECRET-session_cookie}"   # only usable through {HEADERS-FILE}
     # Add any custom headers here. Each will be available as {HEADER-name}
     # Example: - "X-Custom-Header: value"
 
@@ -148,22 +310,22 @@ tools:
       # ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
       - name: "quickhits"
         description: "Fast scan with quickhits wordlist (immediate findings)"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -t 100 -rate 100 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-quickhits.json -of json -ac"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -t {THREADS | default 100} -rate {RATE | default 100} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-quickhits.json -of json -ac"
         wordlist: quickhits
 
       - name: "common"
         description: "Common paths and files (dirb common)"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -t 100 -rate 100 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-common.json -of json -ac"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -t {THREADS | default 100} -rate {RATE | default 100} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-common.json -of json -ac"
         wordlist: common
 
       - name: "raft-small-dirs"
         description: "Raft small directories wordlist"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -t 100 -rate 150 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-raft-small-dirs.json -of json -ac"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -t {THREADS | default 100} -rate {RATE | default 150} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-raft-small-dirs.json -of json -ac"
         wordlist: raft-small-dirs
 
       - name: "raft-small-words"
         description: "Raft small words wordlist"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -t 100 -rate 150 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-raft-small-words.json -of json -ac"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -t {THREADS | default 100} -rate {RATE | default 150} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-raft-small-words.json -of json -ac"
         wordlist: raft-small-words
 
       # ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
@@ -171,17 +333,17 @@ tools:
       # ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
       - name: "raft-medium-dirs"
         description: "Raft medium directories with recursion"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404,403 -fs 0 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-raft-medium-dirs.json -of json -t 100 -rate 200 -ac -recursion -recursion-depth 2"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404,403 -fs 0 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-raft-medium-dirs.json -of json -t {THREADS | default 100} -rate {RATE | default 200} -ac -recursion -recursion-depth 2"
         wordlist: raft-medium-dirs
 
       - name: "raft-medium-words"
         description: "Raft medium words with extensions"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -e .php,.html,.js -t 100 -rate 200 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-raft-medium-words.json -of json -ac"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -e .php,.html,.js -t {THREADS | default 100} -rate {RATE | default 200} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-raft-medium-words.json -of json -ac"
         wordlist: raft-medium-words
 
       - name: "raft-medium-files"
         description: "Raft medium files with multiple extensions"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -e .php,.asp,.aspx,.jsp,.html,.js,.txt,.json,.xml,.bak,.old,.zip,.tar.gz,.sql,.db,.config,.env,.log -t 100 -rate 200 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-raft-medium-files.json -of json"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -e .php,.asp,.aspx,.jsp,.html,.js,.txt,.json,.xml,.bak,.old,.zip,.tar.gz,.sql,.db,.config,.env,.log -t {THREADS | default 100} -rate {RATE | default 200} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-raft-medium-files.json -of json"
         wordlist: raft-medium-files
 
       # ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
@@ -189,40 +351,43 @@ tools:
       # ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
       - name: "big"
         description: "Big wordlist comprehensive scan"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -fs 0 -t 80 -rate 150 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-big.json -of json -ac"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -fs 0 -t {THREADS | default 80} -rate {RATE | default 150} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-big.json -of json -ac"
         wordlist: big
 
       - name: "raft-large-dirs"
         description: "Raft large directories extensive scan"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -fs 0 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-raft-large-dirs.json -of json -t 80 -rate 150 -ac"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -fs 0 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-raft-large-dirs.json -of json -t {THREADS | default 80} -rate {RATE | default 150} -ac"
         wordlist: raft-large-dirs
 
       - name: "raft-large-files"
         description: "Raft large files with extensions"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -e .php,.asp,.aspx,.jsp,.html,.js,.txt,.json,.xml,.bak,.old -t 80 -rate 150 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-raft-large-files.json -of json"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -e .php,.asp,.aspx,.jsp,.html,.js,.txt,.json,.xml,.bak,.old -t {THREADS | default 80} -rate {RATE | default 150} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-raft-large-files.json -of json"
         wordlist: raft-large-files
+        timeout: 4h  # Optional: stop the session and mark it timed-out after this long
+        # retries: 2  # Optional: start it again up to this many times when it fails, times out or is lost
+        # backoff: 1m  # Optional: wait before the first retry, doubled for each further one
 
       # ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
       # API DISCOVERY - API endpoints and documentation
       # ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
       - name: "api-endpoints"
         description: "API endpoints discovery (main list)"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -H 'Content-Type: application/json' -mc all -fc 404 -t 100 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-api.json -of json"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -H 'Content-Type: application/json' -mc all -fc 404 -t {THREADS | default 100}{if RATE} -rate {RATE}{end} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-api.json -of json"
         wordlist: api
 
       - name: "api-endpoints-v2"
         description: "API endpoints discovery (extended list)"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -H 'Content-Type: application/json' -mc all -fc 404 -t 100 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-api-v2.json -of json"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -H 'Content-Type: application/json' -mc all -fc 404 -t {THREADS | default 100}{if RATE} -rate {RATE}{end} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-api-v2.json -of json"
         wordlist: api-v2
 
       - name: "swagger-docs"
         description: "Swagger/OpenAPI documentation discovery"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -t 100 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-swagger.json -of json"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -t {THREADS | default 100}{if RATE} -rate {RATE}{end} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-swagger.json -of json"
         wordlist: swagger
 
       - name: "graphql-endpoints"
         description: "GraphQL endpoints discovery"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -H 'Content-Type: application/json' -mc all -fc 404 -t 100 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-graphql.json -of json"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -H 'Content-Type: application/json' -mc all -fc 404 -t {THREADS | default 100}{if RATE} -rate {RATE}{end} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-graphql.json -of json"
         wordlist: graphql
 
       # ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
@@ -230,17 +395,17 @@ tools:
       # ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
       - name: "parameter-fuzzing"
         description: "GET parameter fuzzing"
-        command: "ffuf -u {URL}?FUZZ=test -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -fs 0 -t 100 -rate 200 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-params.json -of json -ac"
+        command: "ffuf -u {URL}?FUZZ=test -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -fs 0 -t {THREADS | default 100} -rate {RATE | default 200} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-params.json -of json -ac"
         wordlist: params
 
       - name: "php-files"
         description: "Common PHP filenames discovery"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -t 100 -rate 150 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-php.json -of json -ac"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -t {THREADS | default 100} -rate {RATE | default 150} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-php.json -of json -ac"
         wordlist: php
 
       - name: "backup-files"
         description: "Backup and sensitive files discovery"
-        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -e .bak,.backup,.old,.swp,~,.git,.env,.sql,.db,.config,.log -t 100 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-backups.json -of json"
+        command: "ffuf -u {URL}/FUZZ -w {WORDLIST} {HEADERS-ALL} -mc all -fc 404 -e .bak,.backup,.old,.swp,~,.git,.env,.sql,.db,.config,.log -t {THREADS | default 100}{if RATE} -rate {RATE}{end} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-backups.json -of json"
         wordlist: backups
 
       # ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
@@ -248,17 +413,17 @@ tools:
       # ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━
       - name: "vhost-top5k"
         description: "Virtual host enumeration (top 5000)"
-        command: "ffuf -u {URL} -w {WORDLIST} {HEADERS-ALL} -H 'Host: FUZZ.{DOMAIN}' -mc all -fc 404 -fs 0 -t 100 -rate 150 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-vhost-top5k.json -of json"
+        command: "ffuf -u {URL} -w {WORDLIST} {HEADERS-ALL} -H 'Host: FUZZ.{DOMAIN}' -mc all -fc 404 -fs 0 -t {THREADS | default 100} -rate {RATE | default 150} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-vhost-top5k.json -of json"
         wordlist: subdomain-top5000
 
       - name: "vhost-top20k"
         description: "Virtual host enumeration (top 20000)"
-        command: "ffuf -u {URL} -w {WORDLIST} {HEADERS-ALL} -H 'Host: FUZZ.{DOMAIN}' -mc all -fc 404 -fs 0 -t 100 -rate 150 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-vhost-top20k.json -of json"
+        command: "ffuf -u {URL} -w {WORDLIST} {HEADERS-ALL} -H 'Host: FUZZ.{DOMAIN}' -mc all -fc 404 -fs 0 -t {THREADS | default 100} -rate {RATE | default 150} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-vhost-top20k.json -of json"
         wordlist: subdomain-top20000
 
       - name: "vhost-namelist"
         description: "Virtual host enumeration (namelist)"
-        command: "ffuf -u {URL} -w {WORDLIST} {HEADERS-ALL} -H 'Host: FUZZ.{DOMAIN}' -mc all -fc 404 -fs 0 -t 100 -rate 150 -o {OUTPUT_DIR}/ffuf-{DOMAIN}-vhost-namelist.json -of json"
+        command: "ffuf -u {URL} -w {WORDLIST} {HEADERS-ALL} -H 'Host: FUZZ.{DOMAIN}' -mc all -fc 404 -fs 0 -t {THREADS | default 100} -rate {RATE | default 150} -o {OUTPUT_DIR}/ffuf-{DOMAIN}-vhost-namelist.json -of json"
         wordlist: vhosts
 
   gobuster:
@@ -267,42 +432,42 @@ tools:
     commands:
       - name: "dir-enum-fast"
         description: "Fast directory enumeration"
-        command: "gobuster dir -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/gobuster-{DOMAIN}-dirs.txt -t 100 -k -e -q --no-error -s '200,204,301,302,307,401,403'"
+        command: "gobuster dir -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/gobuster-{DOMAIN}-dirs.txt -t {THREADS | default 100} -k -e -q --no-error -s '200,204,301,302,307,401,403'"
         wordlist: raft-medium-dirs
 
       - name: "dir-enum-extensions"
         description: "Directory enumeration with multiple extensions"
-        command: "gobuster dir -u {URL} -w {WORDLIST} -x php,asp,aspx,jsp,html,js,txt,json,xml,bak,zip,tar.gz,sql -o {OUTPUT_DIR}/gobuster-{DOMAIN}-ext.txt -t 100 -k -e -q --no-error"
+        command: "gobuster dir -u {URL} -w {WORDLIST} -x php,asp,aspx,jsp,html,js,txt,json,xml,bak,zip,tar.gz,sql -o {OUTPUT_DIR}/gobuster-{DOMAIN}-ext.txt -t {THREADS | default 100} -k -e -q --no-error"
         wordlist: raft-medium-files
 
       - name: "dir-enum-big"
         description: "Extensive directory enumeration"
-        command: "gobuster dir -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/gobuster-{DOMAIN}-big.txt -t 80 -k -e -q --no-error -x php,html,txt"
+        command: "gobuster dir -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/gobuster-{DOMAIN}-big.txt -t {THREADS | default 80} -k -e -q --no-error -x php,html,txt"
         wordlist: raft-large-dirs
 
       - name: "api-endpoints"
         description: "API endpoint enumeration"
-        command: "gobuster dir -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/gobuster-{DOMAIN}-api.txt -t 100 -k -e -q --no-error -x json,xml"
+        command: "gobuster dir -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/gobuster-{DOMAIN}-api.txt -t {THREADS | default 100} -k -e -q --no-error -x json,xml"
         wordlist: api
 
       - name: "vhost-enum"
         description: "Virtual host enumeration"
-        command: "gobuster vhost -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/gobuster-{DOMAIN}-vhosts.txt -t 100 -k --append-domain -r"
+        command: "gobuster vhost -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/gobuster-{DOMAIN}-vhosts.txt -t {THREADS | default 100} -k --append-domain -r"
         wordlist: subdomain-top5000
 
       - name: "dns-enum"
         description: "DNS subdomain enumeration"
-        command: "gobuster dns -d {DOMAIN} -w {WORDLIST} -o {OUTPUT_DIR}/gobuster-{DOMAIN}-dns.txt -t 100"
+        command: "gobuster dns -d {DOMAIN} -w {WORDLIST} -o {OUTPUT_DIR}/gobuster-{DOMAIN}-dns.txt -t {THREADS | default 100}"
         wordlist: subdomain-top20000
 
       - name: "sensitive-files"
         description: "Search for sensitive files"
-        command: "gobuster dir -u {URL} -w {WORDLIST} -x bak,backup,old,swp,env,git,sql,db,config,log -o {OUTPUT_DIR}/gobuster-{DOMAIN}-sensitive.txt -t 100 -k -e -q"
+        command: "gobuster dir -u {URL} -w {WORDLIST} -x bak,backup,old,swp,env,git,sql,db,config,log -o {OUTPUT_DIR}/gobuster-{DOMAIN}-sensitive.txt -t {THREADS | default 100} -k -e -q"
         wordlist: backups
 
       - name: "bypass-filtering"
         description: "Attempt bypass with custom patterns"
-        command: "gobuster dir -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/gobuster-{DOMAIN}-bypass.txt -t 100 -k -e -q --no-error -H 'X-Original-URL: /' -H 'X-Rewrite-URL: /'"
+        command: "gobuster dir -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/gobuster-{DOMAIN}-bypass.txt -t {THREADS | default 100} -k -e -q --no-error -H 'X-Original-URL: /' -H 'X-Rewrite-URL: /'"
         wordlist: common
 
   dirsearch:
@@ -311,47 +476,47 @@ tools:
     commands:
       - name: "default-scan"
         description: "Default fast scan with common wordlist"
-        command: "dirsearch -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-default.txt -t 100 --random-agent -i 200,204,301,302,307,401,403 -q"
+        command: "dirsearch -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-default.txt -t {THREADS | default 100}{if RATE} --max-rate {RATE}{end} --random-agent -i 200,204,301,302,307,401,403 -q"
         wordlist: raft-medium-dirs
 
       - name: "recursive-scan"
         description: "Recursive directory scanning"
-        command: "dirsearch -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-recursive.txt -t 100 -R 2 --random-agent -i 200,204,301,302,307,401,403 -q"
+        command: "dirsearch -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-recursive.txt -t {THREADS | default 100}{if RATE} --max-rate {RATE}{end} -R 2 --random-agent -i 200,204,301,302,307,401,403 -q"
         wordlist: raft-medium-dirs
 
       - name: "deep-recursive"
         description: "Deep recursive scan (finds more endpoints)"
-        command: "dirsearch -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-deep.txt -t 80 --deep-recursive --random-agent -i 200,204,301,302,307,401,403 -q"
+        command: "dirsearch -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-deep.txt -t {THREADS | default 80}{if RATE} --max-rate {RATE}{end} --deep-recursive --random-agent -i 200,204,301,302,307,401,403 -q"
         wordlist: raft-medium-words
 
       - name: "multi-extension"
         description: "Scan with multiple important extensions"
-        command: "dirsearch -u {URL} -w {WORDLIST} -e php,asp,aspx,jsp,html,js,txt,json,xml,yml,yaml,bak,old,zip,tar.gz,sql,db,config,env,log -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-multi-ext.txt -t 100 --random-agent -q"
+        command: "dirsearch -u {URL} -w {WORDLIST} -e php,asp,aspx,jsp,html,js,txt,json,xml,yml,yaml,bak,old,zip,tar.gz,sql,db,config,env,log -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-multi-ext.txt -t {THREADS | default 100}{if RATE} --max-rate {RATE}{end} --random-agent -q"
         wordlist: raft-medium-files
 
       - name: "backup-files"
         description: "Search for backup and sensitive files"
-        command: "dirsearch -u {URL} -w {WORDLIST} -e bak,backup,old,swp,save,copy,orig,tmp,~ -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-backups.txt -t 100 --random-agent --suffixes=~ --prefixes=. -q"
+        command: "dirsearch -u {URL} -w {WORDLIST} -e bak,backup,old,swp,save,copy,orig,tmp,~ -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-backups.txt -t {THREADS | default 100}{if RATE} --max-rate {RATE}{end} --random-agent --suffixes=~ --prefixes=. -q"
         wordlist: backups
 
       - name: "api-scan"
         description: "API endpoint discovery"
-        command: "dirsearch -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-api.txt -t 100 --random-agent -i 200,201,204,301,302,401,403 -q"
+        command: "dirsearch -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-api.txt -t {THREADS | default 100}{if RATE} --max-rate {RATE}{end} --random-agent -i 200,201,204,301,302,401,403 -q"
         wordlist: api
 
       - name: "config-files"
         description: "Search for configuration files"
-        command: "dirsearch -u {URL} -w {WORDLIST} -e config,conf,cfg,ini,env,xml,yml,yaml,json,properties -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-configs.txt -t 100 --random-agent -q"
+        command: "dirsearch -u {URL} -w {WORDLIST} -e config,conf,cfg,ini,env,xml,yml,yaml,json,properties -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-configs.txt -t {THREADS | default 100}{if RATE} --max-rate {RATE}{end} --random-agent -q"
         wordlist: common
 
       - name: "large-scan"
         description: "Comprehensive scan with large wordlist"
-        command: "dirsearch -u {URL} -w {WORDLIST} -e php,html,txt,js,json -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-large.txt -t 80 --random-agent -R 1 -q"
+        command: "dirsearch -u {URL} -w {WORDLIST} -e php,html,txt,js,json -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-large.txt -t {THREADS | default 80}{if RATE} --max-rate {RATE}{end} --random-agent -R 1 -q"
         wordlist: raft-large-dirs
 
       - name: "exclude-sizes"
         description: "Scan excluding common false positive sizes"
-        command: "dirsearch -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-filtered.txt -t 100 --random-agent --exclude-sizes=0B -q"
+        command: "dirsearch -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/dirsearch-{DOMAIN}-filtered.txt -t {THREADS | default 100}{if RATE} --max-rate {RATE}{end} --random-agent --exclude-sizes=0B -q"
         wordlist: raft-medium-dirs
 
   feroxbuster:
@@ -360,52 +525,52 @@ tools:
     commands:
       - name: "fast-scan"
         description: "Fast scan with auto-tune"
-        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-fast.txt -t 100 -k --auto-tune -s 200,204,301,302,307,401,403 -q"
+        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-fast.txt -t {THREADS | default 100}{if RATE} --rate-limit {RATE}{end} -k --auto-tune -s 200,204,301,302,307,401,403 -q"
         wordlist: raft-medium-dirs
 
       - name: "recursive-scan"
         description: "Recursive scan with intelligent depth"
-        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-recursive.txt -t 100 -k -d 2 --auto-tune -s 200,204,301,302,307,401,403 -q"
+        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-recursive.txt -t {THREADS | default 100}{if RATE} --rate-limit {RATE}{end} -k -d 2 --auto-tune -s 200,204,301,302,307,401,403 -q"
         wordlist: raft-medium-dirs
 
       - name: "deep-recursive"
         description: "Deep recursive scan with word collection"
-        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-deep.txt -t 80 -k -d 3 --auto-tune --collect-words --extract-links -s 200,204,301,302,307,401,403 -q"
+        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-deep.txt -t {THREADS | default 80}{if RATE} --rate-limit {RATE}{end} -k -d 3 --auto-tune --collect-words --extract-links -s 200,204,301,302,307,401,403 -q"
         wordlist: raft-medium-words
 
       - name: "extensions-scan"
         description: "Scan with multiple extensions"
-        command: "feroxbuster -u {URL} -w {WORDLIST} -x php,asp,aspx,jsp,html,js,txt,json,xml,yml,bak,old -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-ext.txt -t 100 -k --auto-tune -q"
+        command: "feroxbuster -u {URL} -w {WORDLIST} -x php,asp,aspx,jsp,html,js,txt,json,xml,yml,bak,old -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-ext.txt -t {THREADS | default 100}{if RATE} --rate-limit {RATE}{end} -k --auto-tune -q"
         wordlist: raft-medium-files
 
       - name: "backup-discovery"
         description: "Discover backup files automatically"
-        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-backups.txt -t 100 -k --collect-backups --auto-tune -s 200,204,301,302,401,403 -q"
+        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-backups.txt -t {THREADS | default 100}{if RATE} --rate-limit {RATE}{end} -k --collect-backups --auto-tune -s 200,204,301,302,401,403 -q"
         wordlist: common
 
       - name: "smart-scan"
         description: "Smart scan with link extraction and word collection"
-        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-smart.txt -t 100 -k -d 2 --auto-tune --collect-words --extract-links --collect-backups -q"
+        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-smart.txt -t {THREADS | default 100}{if RATE} --rate-limit {RATE}{end} -k -d 2 --auto-tune --collect-words --extract-links --collect-backups -q"
         wordlist: raft-medium-dirs
 
       - name: "large-scan"
         description: "Comprehensive scan with large wordlist"
-        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-large.txt -t 80 -k -d 2 --auto-tune --rate-limit 200 -q"
+        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-large.txt -t {THREADS | default 80} -k -d 2 --auto-tune --rate-limit {RATE | default 200} -q"
         wordlist: raft-large-dirs
 
       - name: "filtered-scan"
         description: "Scan with intelligent size filtering"
-        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-filtered.txt -t 100 -k --auto-tune --filter-size 0 -C 404 -q"
+        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-filtered.txt -t {THREADS | default 100}{if RATE} --rate-limit {RATE}{end} -k --auto-tune --filter-size 0 -C 404 -q"
         wordlist: raft-medium-dirs
 
       - name: "api-scan"
         description: "API endpoint discovery"
-        command: "feroxbuster -u {URL} -w {WORDLIST} -x json,xml -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-api.txt -t 100 -k --auto-tune -s 200,201,204,401,403 -q"
+        command: "feroxbuster -u {URL} -w {WORDLIST} -x json,xml -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-api.txt -t {THREADS | default 100}{if RATE} --rate-limit {RATE}{end} -k --auto-tune -s 200,201,204,401,403 -q"
         wordlist: api
 
       - name: "thorough-scan"
         description: "Thorough scan for maximum coverage"
-        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-thorough.txt -t 80 -k -d 3 --auto-tune --collect-words --extract-links --collect-backups --rate-limit 150 -x php,html,js,txt,json,xml,bak,old -q"
+        command: "feroxbuster -u {URL} -w {WORDLIST} -o {OUTPUT_DIR}/feroxbuster-{DOMAIN}-thorough.txt -t {THREADS | default 80} -k -d 3 --auto-tune --collect-words --extract-links --collect-backups --rate-limit {RATE | default 150} -x php,html,js,txt,json,xml,bak,old -q"
         wordlist: raft-large-files
 
 # Notes:
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigName is the project-local config merged over the user config.
+// It is looked up in the working directory and its parents.
+const ProjectConfigName = ".trident.yaml"
+
+// EnvPrefix starts the environment variables that override config values,
+// e.g. TRIDENT_GLOBAL_OUTPUT_DIR or TRIDENT_SCHEDULER_MAX_CONCURRENT
+const EnvPrefix = "TRIDENT_"
+
+// SourceDefault marks values no layer set
+const SourceDefault = "default"
+
+// Sources records which layer set each config value, keyed by dotted path
+// such as "scheduler.max_concurrent"
+type Sources map[string]string
+
+// Lookup returns the source of a value
+func (s Sources) Lookup(path string) string {
+	if source, ok := s[path]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// LoadWithSources loads the config like Load and also returns where each
+// value came from. Layers, lowest precedence first: the user config, the
+// project config, TRIDENT_* environment variables.
+func LoadWithSources() (*Config, Sources, error) {
+	sources := Sources{}
+
+	configPath := GetConfigPath()
+	merged, err := readLayer(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("config not found at %s: %w", configPath, err)
+	}
+	markSources(merged, "", configPath, sources)
+
+	if projectPath := FindProjectConfig(); projectPath != "" && projectPath != configPath {
+		layer, err := readLayer(projectPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading project config %s: %w", projectPath, err)
+		}
+		mergeNodes(merged, layer, "", projectPath, sources)
+	}
+
+	// Fields start at their defaults so only keys absent from every layer
+	// keep them; an explicit 0 means no limit
+	cfg := Config{Scheduler: SchedulerConfig{MaxConcurrent: DefaultMaxConcurrent}}
+	if err := merged.Decode(&cfg); err != nil {
+		return nil, nil, fmt.Errorf("error parsing config: %w", err)
+	}
+
+	if err := applyEnv(&cfg, sources); err != nil {
+		return nil, nil, err
+	}
+
+	return &cfg, sources, nil
+}
+
+// FindProjectConfig returns the nearest .trident.yaml in the working
+// directory or its parents, or "" if there is none
+func FindProjectConfig() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	for {
+		path := filepath.Join(dir, ProjectConfigName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// DumpEffective renders the config as YAML with the source of every value
+// as a line comment
+func (c *Config) DumpEffective(sources Sources) ([]byte, error) {
+	var root yaml.Node
+	if err := root.Encode(c); err != nil {
+		return nil, err
+	}
+
+	annotate(&root, "", sources)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), encoder.Close()
+}
+
+// readLayer reads a YAML file and returns its top-level mapping
+func readLayer(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: top level must be a mapping", path)
+	}
+	return root, nil
+}
+
+// mergeNodes deep-merges overlay into base. Mappings are merged key by key;
+// scalars and lists in the overlay replace the base value.
+func mergeNodes(base, overlay *yaml.Node, path, source string, sources Sources) {
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+		childPath := joinPath(path, key.Value)
+
+		existing := mappingValue(base, key.Value)
+		if existing != nil && existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+			mergeNodes(existing, value, childPath, source, sources)
+			continue
+		}
+
+		for p := range sources {
+			if p == childPath || strings.HasPrefix(p, childPath+".") {
+				delete(sources, p)
+			}
+		}
+		markSources(value, childPath, source, sources)
+
+		if existing != nil {
+			*existing = *value
+		} else {
+			base.Content = append(base.Content, key, value)
+		}
+	}
+}
+
+// markSources records source for every value under node. Lists count as a
+// single value since they are replaced as a whole.
+func markSources(node *yaml.Node, path, source string, sources Sources) {
+	if node.Kind != yaml.MappingNode || len(node.Content) == 0 {
+		sources[path] = source
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		markSources(node.Content[i+1], joinPath(path, node.Content[i].Value), source, sources)
+	}
+}
+
+// applyEnv overrides scalar values with TRIDENT_* environment variables.
+// The config is round-tripped through YAML so every field, set or not, has
+// a variable named after its path.
+func applyEnv(cfg *Config, sources Sources) error {
+	var root yaml.Node
+	if err := root.Encode(cfg); err != nil {
+		return err
+	}
+
+	changed := false
+	walkScalars(&root, "", func(node *yaml.Node, path string) {
+		name := EnvName(path)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return
+		}
+
+		node.Value = value
+		node.Style = 0
+		if node.Tag != "!!str" {
+			// Let YAML resolve numbers, booleans and durations again
+			node.Tag = ""
+		}
+		sources[path] = "env " + name
+		changed = true
+	})
+
+	if !changed {
+		return nil
+	}
+
+	var updated Config
+	if err := root.Decode(&updated); err != nil {
+		return fmt.Errorf("invalid %s* environment override: %w", EnvPrefix, err)
+	}
+	*cfg = updated
+	return nil
+}
+
+// EnvName returns the environment variable that overrides a config path
+func EnvName(path string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, path)
+	return EnvPrefix + name
+}
+
+// walkScalars calls fn for every scalar in nested mappings, skipping lists
+func walkScalars(node *yaml.Node, path string, fn func(*yaml.Node, string)) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			walkScalars(node.Content[i+1], joinPath(path, node.Content[i].Value), fn)
+		}
+	case yaml.ScalarNode:
+		fn(node, path)
+	}
+}
+
+// annotate adds the source of each value as a line comment
+func annotate(node *yaml.Node, path string, sources Sources) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		childPath := joinPath(path, key.Value)
+
+		switch {
+		case value.Kind == yaml.MappingNode && len(value.Content) > 0:
+			annotate(value, childPath, sources)
+		case value.Kind == yaml.ScalarNode:
+			value.LineComment = sources.Lookup(childPath)
+		default:
+			key.LineComment = sources.Lookup(childPath)
+		}
+	}
+}
+
+// mappingValue returns the value for key in a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,303 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseLayer parses YAML text into its top-level mapping
+func parseLayer(t *testing.T, text string) *yaml.Node {
+	t.Helper()
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &doc); err != nil {
+		t.Fatalf("yaml.Unmarshal(%q): %v", text, err)
+	}
+	return doc.Content[0]
+}
+
+// decodeNode decodes a node into plain maps and lists for comparison
+func decodeNode(t *testing.T, node *yaml.Node) map[string]interface{} {
+	t.Helper()
+
+	var value map[string]interface{}
+	if err := node.Decode(&value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestMergeNodes(t *testing.T) {
+	tests := []struct {
+		name        string
+		base        string
+		overlay     string
+		want        string
+		wantSources Sources
+	}{
+		{
+			name:        "scalar replaced",
+			base:        "a: 1\nb: 2",
+			overlay:     "b: 3",
+			want:        "a: 1\nb: 3",
+			wantSources: Sources{"a": "user", "b": "project"},
+		},
+		{
+			name:        "new key added",
+			base:        "a: 1",
+			overlay:     "b: 2",
+			want:        "a: 1\nb: 2",
+			wantSources: Sources{"a": "user", "b": "project"},
+		},
+		{
+			name:    "mappings merged key by key",
+			base:    "global: {output_dir: /out, rate: 5}\nscheduler: {max_per_host: 4}",
+			overlay: "global: {rate: 10, threads: 8}",
+			want:    "global: {output_dir: /out, rate: 10, threads: 8}\nscheduler: {max_per_host: 4}",
+			wantSources: Sources{
+				"global.output_dir":      "user",
+				"global.rate":            "project",
+				"global.threads":         "project",
+				"scheduler.max_per_host": "user",
+			},
+		},
+		{
+			name:        "lists replaced as a whole",
+			base:        "scope: {include: [a.com, b.com], exclude: [x.a.com]}",
+			overlay:     "scope: {include: [c.com]}",
+			want:        "scope: {include: [c.com], exclude: [x.a.com]}",
+			wantSources: Sources{"scope.include": "project", "scope.exclude": "user"},
+		},
+		{
+			name:        "mapping replaced by a scalar",
+			base:        "vars: {a: 1, b: 2}",
+			overlay:     "vars: null",
+			want:        "vars: null",
+			wantSources: Sources{"vars": "project"},
+		},
+		{
+			name:        "scalar replaced by a mapping",
+			base:        "vars: null",
+			overlay:     "vars: {a: 1}",
+			want:        "vars: {a: 1}",
+			wantSources: Sources{"vars.a": "project"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources := Sources{}
+			base := parseLayer(t, tt.base)
+			markSources(base, "", "user", sources)
+			mergeNodes(base, parseLayer(t, tt.overlay), "", "project", sources)
+
+			if got, want := decodeNode(t, base), decodeNode(t, parseLayer(t, tt.want)); !reflect.DeepEqual(got, want) {
+				t.Errorf("merged = %v, want %v", got, want)
+			}
+			if !reflect.DeepEqual(sources, tt.wantSources) {
+				t.Errorf("sources = %v, want %v", sources, tt.wantSources)
+			}
+		})
+	}
+}
+
+func TestMarkSources(t *testing.T) {
+	tests := []struct {
+		text string
+		want Sources
+	}{
+		{"a: 1", Sources{"a": "file"}},
+		{"global: {rate: 1, threads: 2}", Sources{"global.rate": "file", "global.threads": "file"}},
+		{"scope: {include: [a.com, b.com]}", Sources{"scope.include": "file"}},
+		{"vars: {}", Sources{"vars": "file"}},
+		{"tools: {ffuf: {enabled: true}}", Sources{"tools.ffuf.enabled": "file"}},
+	}
+
+	for _, tt := range tests {
+		sources := Sources{}
+		markSources(parseLayer(t, tt.text), "", "file", sources)
+		if !reflect.DeepEqual(sources, tt.want) {
+			t.Errorf("%q: sources = %v, want %v", tt.text, sources, tt.want)
+		}
+	}
+
+	if got := (Sources{}).Lookup("global.rate"); got != SourceDefault {
+		t.Errorf("Lookup of an unset path = %q, want %q", got, SourceDefault)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	for path, want := range map[string]string{
+		"global.output_dir":        "TRIDENT_GLOBAL_OUTPUT_DIR",
+		"scheduler.max_concurrent": "TRIDENT_SCHEDULER_MAX_CONCURRENT",
+		"tools.ffuf.enabled":       "TRIDENT_TOOLS_FFUF_ENABLED",
+		"vars.api-key":             "TRIDENT_VARS_API_KEY",
+		"wordlists.Common.v2":      "TRIDENT_WORDLISTS_COMMON_V2",
+	} {
+		if got := EnvName(path); got != want {
+			t.Errorf("EnvName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	newConfig := func() *Config {
+		return &Config{
+			Global:    GlobalConfig{OutputDir: "/out", Rate: 5},
+			Scheduler: SchedulerConfig{MaxConcurrent: 10},
+			Tools:     map[string]ToolConfig{"ffuf": {Enabled: true, TmuxPrefix: "ff_"}},
+			Vars:      map[string]string{"api-key": "config"},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		check       func(*Config) bool
+		wantSources Sources
+		wantErr     bool
+	}{
+		{
+			name:        "no overrides",
+			check:       func(c *Config) bool { return reflect.DeepEqual(c, newConfig()) },
+			wantSources: Sources{},
+		},
+		{
+			name:        "string",
+			env:         map[string]string{"TRIDENT_GLOBAL_OUTPUT_DIR": "/env"},
+			check:       func(c *Config) bool { return c.Global.OutputDir == "/env" && c.Global.Rate == 5 },
+			wantSources: Sources{"global.output_dir": "env TRIDENT_GLOBAL_OUTPUT_DIR"},
+		},
+		{
+			name:        "number set to zero",
+			env:         map[string]string{"TRIDENT_SCHEDULER_MAX_CONCURRENT": "0"},
+			check:       func(c *Config) bool { return c.Scheduler.MaxConcurrent == 0 },
+			wantSources: Sources{"scheduler.max_concurrent": "env TRIDENT_SCHEDULER_MAX_CONCURRENT"},
+		},
+		{
+			name:        "duration of an unset field",
+			env:         map[string]string{"TRIDENT_SCHEDULER_START_JITTER": "3s"},
+			check:       func(c *Config) bool { return c.Scheduler.StartJitter == 3*time.Second },
+			wantSources: Sources{"scheduler.start_jitter": "env TRIDENT_SCHEDULER_START_JITTER"},
+		},
+		{
+			name: "map entries",
+			env:  map[string]string{"TRIDENT_TOOLS_FFUF_ENABLED": "false", "TRIDENT_VARS_API_KEY": "env"},
+			check: func(c *Config) bool {
+				return !c.Tools["ffuf"].Enabled && c.Tools["ffuf"].TmuxPrefix == "ff_" && c.Vars["api-key"] == "env"
+			},
+			wantSources: Sources{
+				"tools.ffuf.enabled": "env TRIDENT_TOOLS_FFUF_ENABLED",
+				"vars.api-key":       "env TRIDENT_VARS_API_KEY",
+			},
+		},
+		{
+			name:    "invalid value",
+			env:     map[string]string{"TRIDENT_GLOBAL_RATE": "fast"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, sources := newConfig(), Sources{}
+			err := applyEnv(cfg, sources)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(cfg) {
+				t.Errorf("config after overrides: %+v", cfg)
+			}
+			if !reflect.DeepEqual(sources, tt.wantSources) {
+				t.Errorf("sources = %v, want %v", sources, tt.wantSources)
+			}
+		})
+	}
+}
+
+func TestConfigAndStatePathPrecedence(t *testing.T) {
+	home := t.TempDir()
+
+	tests := []struct {
+		name      string
+		flag      string
+		env       string
+		xdg       string
+		wantConf  string
+		wantState string
+	}{
+		{
+			name:      "flag",
+			flag:      "/flag/path",
+			env:       "/env/path",
+			xdg:       "/xdg",
+			wantConf:  "/flag/path",
+			wantState: "/flag/path",
+		},
+		{
+			name:      "environment",
+			env:       "/env/path",
+			xdg:       "/xdg",
+			wantConf:  "/env/path",
+			wantState: "/env/path",
+		},
+		{
+			name:      "XDG",
+			xdg:       "/xdg",
+			wantConf:  "/xdg/trident-recon/config.yaml",
+			wantState: "/xdg/trident-recon",
+		},
+		{
+			name:      "relative XDG ignored",
+			xdg:       "relative",
+			wantConf:  filepath.Join(home, ".config", "trident-recon", "config.yaml"),
+			wantState: filepath.Join(home, ".local", "state", "trident-recon"),
+		},
+		{
+			name:      "default",
+			wantConf:  filepath.Join(home, ".config", "trident-recon", "config.yaml"),
+			wantState: filepath.Join(home, ".local", "state", "trident-recon"),
+		},
+		{
+			name:      "flag with tilde",
+			flag:      "~/custom",
+			wantConf:  filepath.Join(home, "custom"),
+			wantState: filepath.Join(home, "custom"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", home)
+			t.Setenv("TRIDENT_CONFIG", tt.env)
+			t.Setenv("TRIDENT_STATE_DIR", tt.env)
+			t.Setenv("XDG_CONFIG_HOME", tt.xdg)
+			t.Setenv("XDG_STATE_HOME", tt.xdg)
+			SetConfigPath(tt.flag)
+			SetStateDir(tt.flag)
+			defer SetConfigPath("")
+			defer SetStateDir("")
+
+			if got := GetConfigPath(); got != tt.wantConf {
+				t.Errorf("GetConfigPath() = %q, want %q", got, tt.wantConf)
+			}
+			if got := GetStateDir(); got != tt.wantState {
+				t.Errorf("GetStateDir() = %q, want %q", got, tt.wantState)
+			}
+		})
+	}
+}
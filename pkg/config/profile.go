@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile is a named overlay applied on top of the config with --profile
+type Profile struct {
+	Description string              `yaml:"description"`
+	Tools       []string            `yaml:"tools"`    // Only run these tools
+	Commands    map[string][]string `yaml:"commands"` // Only run these commands of a tool
+	Rate        int                 `yaml:"rate"`     // Overrides global.rate
+	Threads     int                 `yaml:"threads"`  // Overrides global.threads
+	Headers     HeadersConfig       `yaml:"headers"`
+	Wordlists   map[string]string   `yaml:"wordlists"`
+	Vars        map[string]string   `yaml:"vars"` // Override the config vars
+}
+
+// ProfileNames returns the names of all profiles, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyProfile overlays a profile on the config.
+//
+// Tools named in tools or commands are enabled and every other tool is
+// disabled; a tool listed under commands keeps only those commands. Rate and
+// threads replace the global values, default headers and wordlists are
+// merged by name, and custom headers replace the configured ones.
+func (c *Config) ApplyProfile(name string) error {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		if len(c.Profiles) == 0 {
+			return fmt.Errorf("profile %q not found (no profiles configured)", name)
+		}
+		return fmt.Errorf("profile %q not found (available: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+
+	// Select tools
+	selected := make(map[string]bool)
+	for _, toolName := range profile.Tools {
+		selected[toolName] = true
+	}
+	for toolName := range profile.Commands {
+		selected[toolName] = true
+	}
+	for toolName := range selected {
+		if _, ok := c.Tools[toolName]; !ok {
+			return fmt.Errorf("profile %s: tool %s not found in config", name, toolName)
+		}
+	}
+	if len(selected) > 0 {
+		for toolName, tool := range c.Tools {
+			tool.Enabled = selected[toolName]
+			c.Tools[toolName] = tool
+		}
+	}
+
+	// Select commands
+	for toolName, commandNames := range profile.Commands {
+		tool := c.Tools[toolName]
+		var commands []CommandTemplate
+		for _, commandName := range commandNames {
+			cmd, ok := findCommand(tool.Commands, commandName)
+			if !ok {
+				return fmt.Errorf("profile %s: tool %s has no command %s", name, toolName, commandName)
+			}
+			commands = append(commands, cmd)
+		}
+		tool.Commands = commands
+		c.Tools[toolName] = tool
+	}
+
+	// Override rates and threads
+	if profile.Rate > 0 {
+		c.Global.Rate = profile.Rate
+	}
+	if profile.Threads > 0 {
+		c.Global.Threads = profile.Threads
+	}
+
+	// Merge headers and wordlists
+	if len(profile.Headers.Default) > 0 && c.Headers.Default == nil {
+		c.Headers.Default = make(map[string]string)
+	}
+	for key, value := range profile.Headers.Default {
+		c.Headers.Default[key] = value
+	}
+	if len(profile.Headers.Custom) > 0 {
+		c.Headers.Custom = profile.Headers.Custom
+	}
+
+	if len(profile.Wordlists) > 0 && c.Wordlists == nil {
+		c.Wordlists = make(map[string]string)
+	}
+	for key, path := range profile.Wordlists {
+		c.Wordlists[key] = path
+	}
+
+	return nil
+}
+
+func findCommand(commands []CommandTemplate, name string) (CommandTemplate, bool) {
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd, true
+		}
+	}
+	return CommandTemplate{}, false
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func profileConfig() *Config {
+	return &Config{
+		Global: GlobalConfig{Rate: 50, Threads: 10},
+		Headers: HeadersConfig{
+			Default: map[string]string{"User-Agent": "trident", "Accept": "*/*"},
+			Custom:  []string{"X-Config: 1"},
+		},
+		Tools: map[string]ToolConfig{
+			"ffuf":      {Enabled: true, Commands: []CommandTemplate{{Name: "quick"}, {Name: "deep"}, {Name: "api"}}},
+			"gobuster":  {Enabled: true, Commands: []CommandTemplate{{Name: "dir"}}},
+			"dirsearch": {Enabled: false, Commands: []CommandTemplate{{Name: "default"}}},
+		},
+		Wordlists: map[string]string{"common": "/lists/common.txt", "big": "/lists/big.txt"},
+	}
+}
+
+// enabledTools returns the enabled tools with their command names
+func enabledTools(c *Config) map[string][]string {
+	enabled := make(map[string][]string)
+	for name, tool := range c.Tools {
+		if !tool.Enabled {
+			continue
+		}
+		var commands []string
+		for _, cmd := range tool.Commands {
+			commands = append(commands, cmd.Name)
+		}
+		enabled[name] = commands
+	}
+	return enabled
+}
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		check   func(t *testing.T, c *Config)
+	}{
+		{
+			name:    "empty profile changes nothing",
+			profile: Profile{Description: "no-op"},
+			check: func(t *testing.T, c *Config) {
+				if want := profileConfig(); !reflect.DeepEqual(c.Global, want.Global) || !reflect.DeepEqual(c.Tools, want.Tools) ||
+					!reflect.DeepEqual(c.Headers, want.Headers) || !reflect.DeepEqual(c.Wordlists, want.Wordlists) {
+					t.Errorf("config changed: %+v", c)
+				}
+			},
+		},
+		{
+			name:    "tools enabled and disabled",
+			profile: Profile{Tools: []string{"dirsearch"}},
+			check: func(t *testing.T, c *Config) {
+				want := map[string][]string{"dirsearch": {"default"}}
+				if got := enabledTools(c); !reflect.DeepEqual(got, want) {
+					t.Errorf("enabled tools = %v, want %v", got, want)
+				}
+			},
+		},
+		{
+			name:    "commands selected",
+			profile: Profile{Tools: []string{"gobuster"}, Commands: map[string][]string{"ffuf": {"api", "quick"}}},
+			check: func(t *testing.T, c *Config) {
+				want := map[string][]string{"ffuf": {"api", "quick"}, "gobuster": {"dir"}}
+				if got := enabledTools(c); !reflect.DeepEqual(got, want) {
+					t.Errorf("enabled tools = %v, want %v", got, want)
+				}
+			},
+		},
+		{
+			name:    "rate and threads overridden",
+			profile: Profile{Rate: 5, Threads: 2},
+			check: func(t *testing.T, c *Config) {
+				if c.Global.Rate != 5 || c.Global.Threads != 2 {
+					t.Errorf("rate %d threads %d, want 5 and 2", c.Global.Rate, c.Global.Threads)
+				}
+			},
+		},
+		{
+			name:    "only rate overridden",
+			profile: Profile{Rate: 5},
+			check: func(t *testing.T, c *Config) {
+				if c.Global.Rate != 5 || c.Global.Threads != 10 {
+					t.Errorf("rate %d threads %d, want 5 and 10", c.Global.Rate, c.Global.Threads)
+				}
+			},
+		},
+		{
+			name: "headers merged and replaced",
+			profile: Profile{Headers: HeadersConfig{
+				Default: map[string]string{"User-Agent": "stealth", "X-Program": "acme"},
+				Custom:  []string{"X-Profile: 1", "X-Other: 2"},
+			}},
+			check: func(t *testing.T, c *Config) {
+				wantDefault := map[string]string{"User-Agent": "stealth", "Accept": "*/*", "X-Program": "acme"}
+				if !reflect.DeepEqual(c.Headers.Default, wantDefault) {
+					t.Errorf("default headers = %v, want %v", c.Headers.Default, wantDefault)
+				}
+				if want := []string{"X-Profile: 1", "X-Other: 2"}; !reflect.DeepEqual(c.Headers.Custom, want) {
+					t.Errorf("custom headers = %q, want %q", c.Headers.Custom, want)
+				}
+			},
+		},
+		{
+			name:    "wordlists merged",
+			profile: Profile{Wordlists: map[string]string{"big": "/lists/huge.txt", "api": "/lists/api.txt"}},
+			check: func(t *testing.T, c *Config) {
+				want := map[string]string{"common": "/lists/common.txt", "big": "/lists/huge.txt", "api": "/lists/api.txt"}
+				if !reflect.DeepEqual(c.Wordlists, want) {
+					t.Errorf("wordlists = %v, want %v", c.Wordlists, want)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := profileConfig()
+			cfg.Profiles = map[string]Profile{"test": tt.profile}
+			if err := cfg.ApplyProfile("test"); err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestApplyProfileToEmptyMaps(t *testing.T) {
+	cfg := &Config{
+		Tools: map[string]ToolConfig{"ffuf": {Enabled: true}},
+		Profiles: map[string]Profile{"test": {
+			Headers:   HeadersConfig{Default: map[string]string{"X-Program": "acme"}},
+			Wordlists: map[string]string{"api": "/lists/api.txt"},
+		}},
+	}
+	if err := cfg.ApplyProfile("test"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Headers.Default["X-Program"] != "acme" || cfg.Wordlists["api"] != "/lists/api.txt" {
+		t.Errorf("headers %v and wordlists %v not filled in", cfg.Headers.Default, cfg.Wordlists)
+	}
+}
+
+func TestApplyProfileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles map[string]Profile
+		apply    string
+		wantErr  string
+	}{
+		{
+			name:    "no profiles",
+			apply:   "fast",
+			wantErr: `profile "fast" not found (no profiles configured)`,
+		},
+		{
+			name:     "unknown profile",
+			profiles: map[string]Profile{"stealth": {}, "deep": {}},
+			apply:    "fast",
+			wantErr:  `profile "fast" not found (available: deep, stealth)`,
+		},
+		{
+			name:     "unknown tool",
+			profiles: map[string]Profile{"fast": {Tools: []string{"nuclei"}}},
+			apply:    "fast",
+			wantErr:  "profile fast: tool nuclei not found in config",
+		},
+		{
+			name:     "unknown command",
+			profiles: map[string]Profile{"fast": {Commands: map[string][]string{"ffuf": {"slow"}}}},
+			apply:    "fast",
+			wantErr:  "profile fast: tool ffuf has no command slow",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := profileConfig()
+			cfg.Profiles = tt.profiles
+			err := cfg.ApplyProfile(tt.apply)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ApplyProfile(%q) = %v, want %q", tt.apply, err, tt.wantErr)
+			}
+		})
+	}
+}
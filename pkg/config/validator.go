@@ -3,6 +3,12 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
+	"text/template"
+
+	"github.com/bc0d3/trident-recon/pkg/backend"
+	"github.com/bc0d3/trident-recon/pkg/scope"
+	"github.com/bc0d3/trident-recon/pkg/secrets"
 )
 
 // Validate validates the configuration
@@ -16,11 +22,77 @@ func (c *Config) Validate() error {
 		c.Global.IDLength = 12 // default value
 	}
 
+	if c.Global.Rate < 0 {
+		return fmt.Errorf("global.rate cannot be negative")
+	}
+	if c.Global.Threads < 0 {
+		return fmt.Errorf("global.threads cannot be negative")
+	}
+	if c.Global.Backend != "" && !containsString(backend.Names(), c.Global.Backend) {
+		return fmt.Errorf("global.backend %q is not one of %v", c.Global.Backend, backend.Names())
+	}
+
+	// Validate scheduler limits
+	if c.Scheduler.MaxConcurrent < 0 {
+		return fmt.Errorf("scheduler.max_concurrent cannot be negative")
+	}
+	if c.Scheduler.MaxPerHost < 0 {
+		return fmt.Errorf("scheduler.max_per_host cannot be negative")
+	}
+	if c.Scheduler.StartJitter < 0 {
+		return fmt.Errorf("scheduler.start_jitter cannot be negative")
+	}
+	for toolName, limit := range c.Scheduler.PerTool {
+		if limit < 0 {
+			return fmt.Errorf("scheduler.per_tool.%s cannot be negative", toolName)
+		}
+	}
+
+	// Validate rate budgets
+	if err := c.Budget.PerHost.validate("budget.per_host"); err != nil {
+		return err
+	}
+	for name, program := range c.Budget.Programs {
+		path := "budget.programs." + name
+		if err := program.Budget.validate(path); err != nil {
+			return err
+		}
+		if program.Rate == 0 && program.Threads == 0 {
+			return fmt.Errorf("%s: rate or threads is required", path)
+		}
+		if len(program.Scope) == 0 {
+			return fmt.Errorf("%s: scope is required", path)
+		}
+		if _, err := scope.New(program.Scope, nil); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	// Validate scope rules
+	if _, err := scope.New(c.Scope.Include, c.Scope.Exclude); err != nil {
+		return err
+	}
+
+	// Validate secret references (values are only read when needed)
+	for name, ref := range c.Secrets {
+		if err := secrets.ValidateName(name); err != nil {
+			return err
+		}
+		if err := ref.Validate(); err != nil {
+			return fmt.Errorf("secrets.%s: %w", name, err)
+		}
+	}
+
+	// Validate notification channels
+	if err := c.ValidateNotifications(); err != nil {
+		return err
+	}
+
 	// Validate wordlists existence (warn only)
 	for name, path := range c.Wordlists {
 		expandedPath := os.ExpandEnv(path)
 		if _, err := os.Stat(expandedPath); os.IsNotExist(err) {
-			fmt.Printf("Warning: wordlist '%s' not found at %s\n", name, expandedPath)
+			fmt.Fprintf(os.Stderr, "Warning: wordlist '%s' not found at %s\n", name, expandedPath)
 		}
 	}
 
@@ -41,9 +113,18 @@ func (c *Config) Validate() error {
 				if cmd.Name == "" {
 					return fmt.Errorf("tool %s: command %d has no name", toolName, i)
 				}
-				if cmd.Command == "" {
+				if cmd.Command == "" && len(cmd.Args) == 0 {
 					return fmt.Errorf("tool %s: command %s has no command template", toolName, cmd.Name)
 				}
+				if cmd.Command != "" && len(cmd.Args) > 0 {
+					return fmt.Errorf("tool %s: command %s: set either command or args, not both", toolName, cmd.Name)
+				}
+				if cmd.Retries < 0 {
+					return fmt.Errorf("tool %s: command %s: retries cannot be negative", toolName, cmd.Name)
+				}
+				if cmd.Backoff < 0 {
+					return fmt.Errorf("tool %s: command %s: backoff cannot be negative", toolName, cmd.Name)
+				}
 			}
 		}
 	}
@@ -51,6 +132,78 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+func (b Budget) validate(path string) error {
+	if b.Rate < 0 {
+		return fmt.Errorf("%s.rate cannot be negative", path)
+	}
+	if b.Threads < 0 {
+		return fmt.Errorf("%s.threads cannot be negative", path)
+	}
+	return nil
+}
+
+// ValidateNotifications validates the notification channels only, for
+// commands that send notifications without needing the rest of the config
+func (c *Config) ValidateNotifications() error {
+	for name, channel := range c.Notifications.Channels {
+		if err := channel.validate("notifications.channels."+name, c.Secrets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ch ChannelConfig) validate(path string, secretRefs map[string]secrets.Ref) error {
+	switch ch.Type {
+	case ChannelWebhook, ChannelSlack, ChannelDiscord:
+		if ch.URL == "" && ch.URLSecret == "" {
+			return fmt.Errorf("%s: url or url_secret is required", path)
+		}
+		if ch.URL != "" && ch.URLSecret != "" {
+			return fmt.Errorf("%s: set either url or url_secret, not both", path)
+		}
+		if _, ok := secretRefs[ch.URLSecret]; ch.URLSecret != "" && !ok {
+			return fmt.Errorf("%s.url_secret: unknown secret %q", path, ch.URLSecret)
+		}
+	case ChannelEmail:
+		if ch.SMTP.Host == "" || ch.SMTP.From == "" || len(ch.SMTP.To) == 0 {
+			return fmt.Errorf("%s: smtp.host, smtp.from and smtp.to are required", path)
+		}
+		if ch.SMTP.Port < 0 {
+			return fmt.Errorf("%s.smtp.port cannot be negative", path)
+		}
+		if _, ok := secretRefs[ch.SMTP.PasswordSecret]; ch.SMTP.PasswordSecret != "" && !ok {
+			return fmt.Errorf("%s.smtp.password_secret: unknown secret %q", path, ch.SMTP.PasswordSecret)
+		}
+	default:
+		return fmt.Errorf("%s: unknown type %q (use webhook, slack, discord or email)", path, ch.Type)
+	}
+
+	for _, event := range ch.Events {
+		if !containsString(NotificationEvents, event) {
+			return fmt.Errorf("%s: unknown event %q (known: %s)", path, event, strings.Join(NotificationEvents, ", "))
+		}
+	}
+
+	switch ch.MinLevel {
+	case "", "note", "warning", "error":
+	default:
+		return fmt.Errorf("%s: unknown min_level %q (use note, warning or error)", path, ch.MinLevel)
+	}
+
+	if _, err := scope.New(ch.Targets, nil); err != nil {
+		return fmt.Errorf("%s.targets: %w", path, err)
+	}
+
+	for field, text := range map[string]string{"title": ch.Title, "body": ch.Body} {
+		if _, err := template.New(field).Parse(text); err != nil {
+			return fmt.Errorf("%s.%s: %w", path, field, err)
+		}
+	}
+
+	return nil
+}
+
 // GetEnabledTools returns a list of enabled tool names
 func (c *Config) GetEnabledTools() []string {
 	var enabled []string
@@ -70,3 +223,12 @@ func (c *Config) GetToolConfig(toolName string) (*ToolConfig, error) {
 	}
 	return &tool, nil
 }
+
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMaxConcurrent(t *testing.T) {
+	const base = "global: {output_dir: /tmp/out}\ntools: {echo: {enabled: true, tmux_prefix: echo_, commands: [{name: hi, command: echo hi}]}}\n"
+	tests := []struct {
+		yaml    string
+		want    int
+		wantErr bool
+	}{
+		{yaml: base, want: DefaultMaxConcurrent},
+		{yaml: base + "scheduler: {max_per_host: 2}\n", want: DefaultMaxConcurrent},
+		{yaml: base + "scheduler: {max_concurrent: 0}\n", want: 0},
+		{yaml: base + "scheduler: {max_concurrent: 3}\n", want: 3},
+		{yaml: base + "scheduler: {max_concurrent: -1}\n", wantErr: true},
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	SetConfigPath(path)
+	defer SetConfigPath("")
+
+	for _, tt := range tests {
+		if err := os.WriteFile(path, []byte(tt.yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Load(%q): %v", tt.yaml, err)
+		}
+		err = cfg.Validate()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected an error", tt.yaml)
+			}
+			continue
+		}
+		if err != nil || cfg.Scheduler.MaxConcurrent != tt.want {
+			t.Errorf("%q: max_concurrent = %d, %v; want %d", tt.yaml, cfg.Scheduler.MaxConcurrent, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package executor
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/bc0d3/trident-recon/pkg/scope"
+)
+
+// Tokens that {RATE} and {THREADS} render to when a budget covers the
+// target. They are replaced with the session's share of the budget when the
+// session starts, see Session.Argv. Both are plain shell words, so they are
+// safe in any quoting context and in argv templates.
+const (
+	RateToken    = "@TRIDENT_RATE@"
+	ThreadsToken = "@TRIDENT_THREADS@"
+)
+
+// Budget is a number of requests per second and of threads shared by the
+// sessions running against a host or a program (0 = no budget)
+type Budget struct {
+	Rate    int
+	Threads int
+}
+
+// ProgramBudget is a budget shared by every target in a program's scope
+type ProgramBudget struct {
+	Name  string
+	Scope *scope.Scope
+	Budget
+}
+
+// Budgets are the rate budgets sessions draw from
+type Budgets struct {
+	PerHost  Budget
+	Programs []ProgramBudget
+}
+
+// budgetPool is one budget and the key its usage is counted under
+type budgetPool struct {
+	key    string
+	host   bool
+	budget Budget
+}
+
+// pools returns the budgets a target draws from: its host's and those of
+// every program whose scope includes it
+func (b Budgets) pools(target string) []budgetPool {
+	var pools []budgetPool
+	if b.PerHost.Rate > 0 || b.PerHost.Threads > 0 {
+		pools = append(pools, budgetPool{key: "host " + targetHost(target), host: true, budget: b.PerHost})
+	}
+	for _, program := range b.Programs {
+		if ok, _ := program.Scope.Check(target); ok {
+			pools = append(pools, budgetPool{key: "program " + program.Name, budget: program.Budget})
+		}
+	}
+	return pools
+}
+
+// Covers reports whether the rate and the threads of a target are budgeted
+func (b Budgets) Covers(target string) (rate, threads bool) {
+	for _, pool := range b.pools(target) {
+		rate = rate || pool.budget.Rate > 0
+		threads = threads || pool.budget.Threads > 0
+	}
+	return rate, threads
+}
+
+// Unthrottled returns the sessions a rate budget covers whose command does
+// not take {RATE}, so nothing limits how fast they send requests
+func (b Budgets) Unthrottled(sessions []Session) []Session {
+	var unthrottled []Session
+	for _, session := range sessions {
+		if rate, _ := b.Covers(session.Target); rate && !session.usesRate() {
+			unthrottled = append(unthrottled, session)
+		}
+	}
+	return unthrottled
+}
+
+// usesRate reports whether the session's command takes a budgeted rate
+func (s *Session) usesRate() bool {
+	return s.usesToken(RateToken)
+}
+
+// usesThreads reports whether the session's command takes budgeted threads
+func (s *Session) usesThreads() bool {
+	return s.usesToken(ThreadsToken)
+}
+
+func (s *Session) usesToken(token string) bool {
+	if strings.Contains(s.Command, token) {
+		return true
+	}
+	for _, arg := range s.Args {
+		if strings.Contains(arg, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// budgeted reports whether the session draws from a budget
+func (s *Session) budgeted() bool {
+	return s.usesRate() || s.usesThreads()
+}
+
+// fillTokens replaces the budget tokens with the session's share
+func (s *Session) fillTokens(text string) string {
+	return strings.NewReplacer(
+		RateToken, strconv.Itoa(s.Rate),
+		ThreadsToken, strconv.Itoa(s.Threads),
+	).Replace(text)
+}
+
+// budgetUsage tracks how much of each budget is held by running sessions
+// and how many sessions want a share of it
+type budgetUsage struct {
+	budgets       Budgets
+	rateDemand    map[string]int // Sessions running or queued per pool that take a rate
+	threadsDemand map[string]int // Sessions running or queued per pool that take threads
+	rate          map[string]int // Rate held by running sessions per pool
+	threads       map[string]int // Threads held by running sessions per pool
+}
+
+func newBudgetUsage(budgets Budgets) *budgetUsage {
+	return &budgetUsage{
+		budgets:       budgets,
+		rateDemand:    make(map[string]int),
+		threadsDemand: make(map[string]int),
+		rate:          make(map[string]int),
+		threads:       make(map[string]int),
+	}
+}
+
+// want counts a session that is running or waiting to run
+func (u *budgetUsage) want(session Session) {
+	if !session.budgeted() {
+		return
+	}
+	for _, pool := range u.budgets.pools(session.Target) {
+		if session.usesRate() {
+			u.rateDemand[pool.key]++
+		}
+		if session.usesThreads() {
+			u.threadsDemand[pool.key]++
+		}
+	}
+}
+
+// hold counts the share of a running session
+func (u *budgetUsage) hold(session Session) {
+	if !session.budgeted() {
+		return
+	}
+	for _, pool := range u.budgets.pools(session.Target) {
+		if session.usesRate() {
+			u.rate[pool.key] += session.Rate
+		}
+		if session.usesThreads() {
+			u.threads[pool.key] += session.Threads
+		}
+	}
+}
+
+// share returns the rate and threads a session may start with: an even
+// split of every budget it draws from among the sessions that can run at
+// once, limited to what running sessions left over. ok is false when a
+// budget is used up. Values the session does not draw from are returned
+// as they are.
+func (u *budgetUsage) share(session Session, limits SchedulerLimits) (rate, threads int, ok bool) {
+	rate, threads = session.Rate, session.Threads
+	rateSet, threadsSet := false, false
+
+	for _, pool := range u.budgets.pools(session.Target) {
+		if session.usesRate() && pool.budget.Rate > 0 {
+			n := pool.concurrent(u.rateDemand[pool.key], limits)
+			grant := splitBudget(pool.budget.Rate, u.rate[pool.key], n)
+			if !rateSet || grant < rate {
+				rate, rateSet = grant, true
+			}
+		}
+		if session.usesThreads() && pool.budget.Threads > 0 {
+			n := pool.concurrent(u.threadsDemand[pool.key], limits)
+			grant := splitBudget(pool.budget.Threads, u.threads[pool.key], n)
+			if !threadsSet || grant < threads {
+				threads, threadsSet = grant, true
+			}
+		}
+	}
+
+	if (rateSet && rate < 1) || (threadsSet && threads < 1) {
+		return session.Rate, session.Threads, false
+	}
+	return rate, threads, true
+}
+
+// concurrent returns how many of the sessions wanting a share of the pool
+// can run at the same time
+func (p budgetPool) concurrent(demand int, limits SchedulerLimits) int {
+	if p.host && limits.MaxPerHost > 0 && demand > limits.MaxPerHost {
+		demand = limits.MaxPerHost
+	}
+	if limits.MaxConcurrent > 0 && demand > limits.MaxConcurrent {
+		demand = limits.MaxConcurrent
+	}
+	return demand
+}
+
+// splitBudget returns an even share of total among n sessions (at least
+// 1), limited to what is left after held
+func splitBudget(total, held, n int) int {
+	share := total
+	if n > 1 {
+		share = total / n
+	}
+	if share < 1 {
+		share = 1
+	}
+	if left := total - held; share > left {
+		share = left
+	}
+	return share
+}
+
+// PlanBudgets sets the share every session gets when they all run at once.
+// Generated command files use it, so commands run by hand stay within the
+// budgets; the scheduler assigns the actual share when a session starts.
+func PlanBudgets(sessions []Session, budgets Budgets) {
+	usage := newBudgetUsage(budgets)
+	for _, session := range sessions {
+		usage.want(session)
+	}
+	for i := range sessions {
+		if !sessions[i].budgeted() {
+			continue
+		}
+		sessions[i].Rate, sessions[i].Threads, _ = usage.share(sessions[i], SchedulerLimits{})
+	}
+}
+
+// targetHost returns the host a target URL points at
+func targetHost(target string) string {
+	return sessionHost(Session{Target: target})
+}
@@ -0,0 +1,105 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/bc0d3/trident-recon/pkg/scope"
+)
+
+func budgetedSession(target string) Session {
+	return Session{Target: target, Command: "ffuf -rate " + RateToken + " -t " + ThreadsToken}
+}
+
+func TestPlanBudgetsSplitsPerHost(t *testing.T) {
+	sessions := []Session{
+		budgetedSession("https://a.example.com"),
+		budgetedSession("https://a.example.com/api"),
+		budgetedSession("https://b.example.com"),
+		{Target: "https://a.example.com", Command: "ffuf -rate 500"},
+	}
+	PlanBudgets(sessions, Budgets{PerHost: Budget{Rate: 100, Threads: 9}})
+
+	for i, want := range []struct{ rate, threads int }{{50, 4}, {50, 4}, {100, 9}, {0, 0}} {
+		if sessions[i].Rate != want.rate || sessions[i].Threads != want.threads {
+			t.Errorf("session %d: got rate %d threads %d, want %d and %d", i, sessions[i].Rate, sessions[i].Threads, want.rate, want.threads)
+		}
+	}
+
+	if got := sessions[0].Argv()[2]; got != "ffuf -rate 50 -t 4" {
+		t.Errorf("Argv() = %q", got)
+	}
+}
+
+func TestBudgetShareRebalances(t *testing.T) {
+	program, err := scope.New([]string{"*.example.com"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	budgets := Budgets{
+		PerHost:  Budget{Rate: 100},
+		Programs: []ProgramBudget{{Name: "acme", Scope: program, Budget: Budget{Rate: 60}}},
+	}
+	limits := SchedulerLimits{MaxPerHost: 2}
+
+	a := budgetedSession("https://a.example.com")
+	b := budgetedSession("https://b.example.com")
+
+	// Three sessions against a, one against b: the program budget is the
+	// tighter one and is split four ways
+	usage := newBudgetUsage(budgets)
+	for _, s := range []Session{a, a, a, b} {
+		usage.want(s)
+	}
+	rate, _, ok := usage.share(a, limits)
+	if !ok || rate != 15 {
+		t.Fatalf("first share = %d, %v, want 15", rate, ok)
+	}
+
+	// Once the program budget is held, nothing more can start
+	running := a
+	running.Rate = 60
+	usage.hold(running)
+	if _, _, ok := usage.share(b, limits); ok {
+		t.Error("share granted from a used up budget")
+	}
+
+	// With one session left against the host, it gets the whole program budget
+	usage = newBudgetUsage(budgets)
+	usage.want(a)
+	if rate, _, ok := usage.share(a, limits); !ok || rate != 60 {
+		t.Errorf("last share = %d, %v, want 60", rate, ok)
+	}
+}
+
+func TestTargetHost(t *testing.T) {
+	for target, want := range map[string]string{
+		"https://example.com:8443/app": "example.com",
+		"http://[2001:db8::1]":         "2001:db8::1",
+		"http://[2001:db8::2]:8080":    "2001:db8::2",
+	} {
+		if got := targetHost(target); got != want {
+			t.Errorf("targetHost(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
+
+func TestUnthrottled(t *testing.T) {
+	sessions := []Session{
+		budgetedSession("https://a.example.com"),
+		{Target: "https://a.example.com", Command: "gobuster dir -t " + ThreadsToken},
+		{Target: "https://b.example.com", Command: "gobuster dir -t 10"},
+	}
+
+	if got := (Budgets{PerHost: Budget{Threads: 10}}).Unthrottled(sessions); len(got) != 0 {
+		t.Errorf("a threads budget reported %d unthrottled sessions", len(got))
+	}
+
+	program, err := scope.New([]string{"a.example.com"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Budgets{Programs: []ProgramBudget{{Name: "a", Scope: program, Budget: Budget{Rate: 50}}}}.Unthrottled(sessions)
+	if len(got) != 1 || got[0].Command != sessions[1].Command {
+		t.Errorf("Unthrottled() = %v, want the gobuster session against a.example.com", got)
+	}
+}
@@ -0,0 +1,162 @@
+package executor
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// EventRunFinished is recorded when the last session of a run ends
+const EventRunFinished = "run.finished"
+
+// SessionEvent returns the event recorded when a session ends with status,
+// e.g. "session.failed"
+func SessionEvent(status Status) string {
+	return "session." + string(status)
+}
+
+// Event is a session or run outcome waiting to be notified. Events are
+// recorded by whichever command notices the outcome, so they are kept in
+// the state directory until a command with the notification config
+// delivers them.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Time      time.Time `json:"time"`
+	Session   *Session  `json:"session,omitempty"`
+	Run       *Run      `json:"run,omitempty"`
+	Delivered []string  `json:"delivered,omitempty"` // Channels the event was sent to
+	Attempts  int       `json:"attempts,omitempty"`  // Deliveries tried so far
+}
+
+// eventsDir returns the directory pending events are kept in
+func eventsDir(stateDir string) string {
+	return filepath.Join(stateDir, "events")
+}
+
+// LockEvents takes the lock held while events are delivered, so two
+// commands never send the same event
+func LockEvents(stateDir string) (*utils.FileLock, error) {
+	lock, err := utils.LockFile(filepath.Join(stateDir, "events.lock"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock events: %w", err)
+	}
+	return lock, nil
+}
+
+// Save saves the event to the state directory
+func (e *Event) Save(stateDir string) error {
+	return writeState(filepath.Join(eventsDir(stateDir), e.ID+".json"), e)
+}
+
+// DeleteEvent removes a delivered event
+func DeleteEvent(stateDir, id string) error {
+	return os.Remove(filepath.Join(eventsDir(stateDir), id+".json"))
+}
+
+// HasEvents reports whether any event is waiting to be delivered
+func HasEvents(stateDir string) bool {
+	entries, err := os.ReadDir(eventsDir(stateDir))
+	return err == nil && len(entries) > 0
+}
+
+// LoadEvents loads the pending events, oldest first
+func LoadEvents(stateDir string) ([]Event, error) {
+	entries, err := os.ReadDir(eventsDir(stateDir))
+	if os.IsNotExist(err) {
+		return []Event{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		path := filepath.Join(eventsDir(stateDir), entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var event Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			utils.PrintWarning(fmt.Sprintf("Dropping unreadable event %s: %v", path, err))
+			os.Remove(path)
+			continue
+		}
+		events = append(events, event)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID < events[j].ID
+	})
+	return events, nil
+}
+
+// recordEvent adds an event to the outbox. Notifications are secondary, so
+// failures are only reported.
+func recordEvent(stateDir string, event Event) {
+	suffix := make([]byte, 3)
+	rand.Read(suffix)
+	event.Time = time.Now()
+	event.ID = strings.Replace(event.Time.Format("20060102-150405.000000"), ".", "-", 1) + "-" + hex.EncodeToString(suffix)
+
+	if err := event.Save(stateDir); err != nil {
+		utils.PrintWarning(fmt.Sprintf("Failed to record %s event: %v", event.Type, err))
+	}
+}
+
+// recordOutcome records the event of a session that ended. Failures that
+// are retried automatically are not reported until the last attempt.
+func recordOutcome(stateDir string, s Session) {
+	if _, retry := s.retryAt(); retry {
+		return
+	}
+
+	switch s.Status {
+	case StatusSucceeded, StatusFailed, StatusTimedOut, StatusLost:
+		recordEvent(stateDir, Event{Type: SessionEvent(s.Status), Session: &s})
+	}
+}
+
+// updateRuns refreshes the records of the given runs from their sessions
+// and records an event for every run that just finished. Callers hold the
+// state lock, so each run finishes only once.
+func updateRuns(stateDir string, sessions []Session, runIDs map[string]bool) error {
+	for id := range runIDs {
+		if id == "" {
+			continue
+		}
+
+		run, err := LoadRun(stateDir, id)
+		if err != nil {
+			// The run was deleted
+			continue
+		}
+
+		wasFinished := !run.FinishedAt.IsZero()
+		if !run.Refresh(sessions) {
+			continue
+		}
+		if err := run.Save(stateDir); err != nil {
+			return fmt.Errorf("failed to save run %s: %w", run.ID, err)
+		}
+
+		if !wasFinished && !run.FinishedAt.IsZero() {
+			recordEvent(stateDir, Event{Type: EventRunFinished, Run: run})
+		}
+	}
+	return nil
+}
@@ -1,22 +1,32 @@
 package executor
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
-	"github.com/bc0d3/trident-recon/pkg/tmux"
+	"github.com/bc0d3/trident-recon/pkg/backend"
+	"github.com/bc0d3/trident-recon/pkg/secrets"
 	"github.com/bc0d3/trident-recon/pkg/utils"
 )
 
-// Executor executes commands in tmux sessions
+// Executor executes commands in sessions of an execution backend. Sessions
+// that were queued in a backend start in that one; Backend is for sessions
+// that have none yet.
 type Executor struct {
 	StateDir string
+	Backend  backend.Backend
 }
 
-// NewExecutor creates a new executor
+// NewExecutor creates a new executor that runs sessions in tmux
 func NewExecutor(stateDir string) *Executor {
+	b, _ := backend.New(backend.Tmux, stateDir)
 	return &Executor{
 		StateDir: stateDir,
+		Backend:  b,
 	}
 }
 
@@ -27,35 +37,187 @@ func (e *Executor) Execute(session *Session) error {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Check if tmux is available
-	if !tmux.IsTmuxAvailable() {
-		return fmt.Errorf("tmux is not installed or not available")
+	// Check if the backend is available
+	b, err := e.backendFor(session)
+	if err != nil {
+		return err
+	}
+	if err := b.Available(); err != nil {
+		return err
 	}
 
 	// Check if session already exists
-	if tmux.SessionExists(session.TmuxSession) {
-		return fmt.Errorf("tmux session %s already exists", session.TmuxSession)
+	if b.Exists(session.TmuxSession) {
+		return fmt.Errorf("%s session %s already exists", b.Name(), session.TmuxSession)
 	}
+	session.Backend = b.Name()
 
 	// Set started time
+	if err := session.Transition(StatusRunning); err != nil {
+		return err
+	}
 	session.StartedAt = time.Now()
-	session.Status = "running"
 
-	// Create tmux session
-	if err := tmux.CreateSession(session.TmuxSession, session.Command); err != nil {
-		return fmt.Errorf("failed to create tmux session: %w", err)
+	// Clear any exit status left over from an earlier session with this ID
+	os.Remove(ExitFilePath(e.StateDir, session.ID))
+
+	// Prepare the output log of this attempt
+	session.LogFile = LogFilePath(session.OutputDir, session.Tool, session.ID, session.Attempts)
+	if err := utils.EnsureDir(filepath.Dir(session.LogFile)); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	os.Remove(session.LogFile)
+
+	// Stage secrets for the session, outside of its command line
+	if err := e.stageSecrets(session); err != nil {
+		return err
+	}
+
+	// Start the session, recording everything the tool prints
+	argv := append([]string{"bash", "-c", e.wrapCommand(session), "trident-recon"}, session.Argv()...)
+	if err := b.Start(session.TmuxSession, argv, e.logCommand(session)); err != nil {
+		if !errors.Is(err, backend.ErrNotLogged) {
+			removeStaged(e.StateDir, session.ID)
+			return err
+		}
+		utils.PrintWarning(fmt.Sprintf("Failed to capture output of %s: %v", session.TmuxSession, err))
+		session.LogFile = ""
 	}
 
 	// Save session metadata
 	if err := session.Save(e.StateDir); err != nil {
-		// Try to cleanup tmux session if metadata save fails
-		tmux.KillSession(session.TmuxSession)
+		// Try to cleanup the session if metadata save fails
+		b.Kill(session.TmuxSession)
 		return fmt.Errorf("failed to save session metadata: %w", err)
 	}
 
 	return nil
 }
 
+// stageSecrets resolves the secrets a session needs and writes them to its
+// env file, which the wrapped command sources and deletes, and writes its
+// headers file. The values never appear in the session's command line or
+// the session state.
+func (e *Executor) stageSecrets(session *Session) error {
+	if !session.stagesEnv() {
+		return nil
+	}
+
+	env := make(map[string]string, len(session.Secrets)+1)
+	var values []string
+	refs := make([]string, 0, 2*len(session.Secrets))
+	for name, ref := range session.Secrets {
+		value, err := ref.Resolve()
+		if err != nil {
+			return fmt.Errorf("secret %s (%s): %w", name, ref, err)
+		}
+		utils.AddRedaction(value)
+		env[name] = value
+		values = append(values, value)
+		refs = append(refs, "${"+name+"}", value)
+	}
+
+	if len(session.HeaderLines) > 0 {
+		// Only references to the session's own secrets are filled in
+		fill := strings.NewReplacer(refs...)
+		lines := make([]string, len(session.HeaderLines))
+		for i, line := range session.HeaderLines {
+			lines[i] = fill.Replace(line)
+		}
+
+		path := secrets.HeadersFilePath(e.StateDir, session.ID)
+		if err := secrets.WriteHeadersFile(path, lines); err != nil {
+			return fmt.Errorf("failed to stage headers: %w", err)
+		}
+		env[secrets.HeadersFileEnv] = path
+	}
+
+	if err := secrets.WriteEnvFile(secrets.EnvFilePath(e.StateDir, session.ID), env); err != nil {
+		removeStaged(e.StateDir, session.ID)
+		return fmt.Errorf("failed to stage secrets: %w", err)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	if err := secrets.WriteValuesFile(secrets.RedactFilePath(e.StateDir, session.ID), values); err != nil {
+		removeStaged(e.StateDir, session.ID)
+		return fmt.Errorf("failed to stage secrets: %w", err)
+	}
+	return nil
+}
+
+// stagesEnv reports whether a session gets an env file: it uses secrets or
+// the headers file
+func (s *Session) stagesEnv() bool {
+	return len(s.Secrets) > 0 || len(s.HeaderLines) > 0
+}
+
+// removeStaged removes the files staged for a session. The session removes
+// them itself, this cleans up after sessions that failed to start or were
+// killed.
+func removeStaged(stateDir, id string) {
+	os.Remove(secrets.EnvFilePath(stateDir, id))
+	os.Remove(secrets.RedactFilePath(stateDir, id))
+	os.Remove(secrets.HeadersFilePath(stateDir, id))
+}
+
+// RedactLogCommand is the hidden trident-recon command that filters the
+// pane output of sessions using secrets
+const RedactLogCommand = "redact-log"
+
+// logCommand returns the command the backend pipes the session output to.
+// Sessions using secrets are filtered through "trident-recon redact-log",
+// which reads the values from a private file rather than its arguments.
+func (e *Executor) logCommand(session *Session) string {
+	logFile := utils.ShellQuote(session.LogFile)
+	if len(session.Secrets) == 0 {
+		return "cat >> " + logFile
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		utils.PrintWarning(fmt.Sprintf("Secrets printed by %s will not be redacted from its log: %v", session.ID, err))
+		return "cat >> " + logFile
+	}
+	return fmt.Sprintf("%s %s %s >> %s", utils.ShellQuote(exe), RedactLogCommand,
+		utils.ShellQuote(secrets.RedactFilePath(e.StateDir, session.ID)), logFile)
+}
+
+// wrapCommand returns the script that runs the session. The session's argv
+// is passed as the script's arguments ("$@"), so it is never re-parsed by
+// the shell. The exit code and finish time are written to the session's
+// exit file once it ends, the time with sub-second precision where date
+// has %N. When the session has a log file, the script waits (up to 2s) for
+// the log command to open it so the first lines of output are not lost.
+// Staged secrets are loaded into the environment and their file removed
+// before the command starts; the headers file is removed once it ends.
+func (e *Executor) wrapCommand(session *Session) string {
+	command := `"$@"`
+	if session.Timeout > 0 {
+		command = fmt.Sprintf(`timeout %ds "$@"`, int(session.Timeout.Seconds()))
+	}
+
+	if session.stagesEnv() {
+		envFile := utils.ShellQuote(secrets.EnvFilePath(e.StateDir, session.ID))
+		command = fmt.Sprintf(`. %s; rm -f %s; %s`, envFile, envFile, command)
+	}
+
+	cleanup := ""
+	if len(session.HeaderLines) > 0 {
+		cleanup = fmt.Sprintf(" rm -f %s;", utils.ShellQuote(secrets.HeadersFilePath(e.StateDir, session.ID)))
+	}
+
+	if session.LogFile != "" {
+		command = fmt.Sprintf(`for _ in 1 2 3 4 5 6 7 8 9 10; do [ -e %s ] && break; sleep 0.2; done; %s`,
+			utils.ShellQuote(session.LogFile), command)
+	}
+
+	// The finish time has nanoseconds where date supports %N, else seconds
+	finishTime := `t=$(date +%s.%N); case $t in *[!0-9.]*|*.) t=$(date +%s);; esac`
+	return fmt.Sprintf(`%s; code=$?;%s %s; printf '%%d %%s\n' "$code" "$t" > %s`,
+		command, cleanup, finishTime, utils.ShellQuote(ExitFilePath(e.StateDir, session.ID)))
+}
+
 // ExecuteAll executes multiple sessions
 func (e *Executor) ExecuteAll(sessions []Session) (int, error) {
 	successful := 0
@@ -79,27 +241,46 @@ func (e *Executor) ExecuteAll(sessions []Session) (int, error) {
 
 // ValidateSessions validates that all sessions can be executed
 func (e *Executor) ValidateSessions(sessions []Session) error {
-	// Check if tmux is available
-	if !tmux.IsTmuxAvailable() {
-		return fmt.Errorf("tmux is not installed or not available")
-	}
+	// Existing session names of every backend the sessions run in
+	existing := make(map[string]map[string]bool)
+	for _, session := range sessions {
+		b, err := e.backendFor(&session)
+		if err != nil {
+			return err
+		}
 
-	// Check for session name conflicts
-	existingSessions, err := tmux.ListSessions()
-	if err != nil {
-		existingSessions = []string{}
-	}
+		conflicts, ok := existing[b.Name()]
+		if !ok {
+			// Check if the backend is available
+			if err := b.Available(); err != nil {
+				return err
+			}
 
-	conflicts := make(map[string]bool)
-	for _, existing := range existingSessions {
-		conflicts[existing] = true
-	}
+			// Check for session name conflicts
+			names, err := b.List()
+			if err != nil {
+				names = []string{}
+			}
+			conflicts = make(map[string]bool)
+			for _, name := range names {
+				conflicts[name] = true
+			}
+			existing[b.Name()] = conflicts
+		}
 
-	for _, session := range sessions {
 		if conflicts[session.TmuxSession] {
-			return fmt.Errorf("session %s already exists in tmux", session.TmuxSession)
+			return fmt.Errorf("session %s already exists in %s", session.TmuxSession, b.Name())
 		}
 	}
 
 	return nil
 }
+
+// backendFor returns the backend a session starts in: the one it was
+// queued in, or else the executor's
+func (e *Executor) backendFor(session *Session) (backend.Backend, error) {
+	if session.Backend == "" || session.Backend == e.Backend.Name() {
+		return e.Backend, nil
+	}
+	return backend.New(session.Backend, e.StateDir)
+}
@@ -0,0 +1,61 @@
+package executor
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bc0d3/trident-recon/pkg/secrets"
+)
+
+func TestHeadersFileKeepsSecretsOutOfArgv(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not available")
+	}
+	t.Setenv("TEST_SESSION", "s3cr3t-value")
+
+	e := &Executor{StateDir: t.TempDir()}
+	if err := os.MkdirAll(filepath.Dir(ExitFilePath(e.StateDir, "abc")), 0755); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	s := Session{
+		ID:          "abc",
+		Command:     `stat -c %a "${TRIDENT_HEADERS_FILE}" > ` + out + `; cat "${TRIDENT_HEADERS_FILE}" >> ` + out,
+		HeaderLines: []string{"Accept: */*", "Cookie: sid=${TRIDENT_SECRET_SESSION}; ${TRIDENT_SECRET_OTHER}"},
+		Secrets:     map[string]secrets.Ref{"TRIDENT_SECRET_SESSION": {Env: "TEST_SESSION"}},
+	}
+
+	if err := e.stageSecrets(&s); err != nil {
+		t.Fatal(err)
+	}
+	argv := s.Argv()
+	for _, arg := range append(argv, e.wrapCommand(&s)) {
+		if strings.Contains(arg, "s3cr3t") {
+			t.Fatalf("secret in the session's argv: %q", arg)
+		}
+	}
+
+	script := e.wrapCommand(&s)
+	if err := exec.Command("bash", append([]string{"-c", script, "trident-recon"}, argv...)...).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only references to the session's own secrets are filled in
+	want := "600\nAccept: */*\nCookie: sid=s3cr3t-value; ${TRIDENT_SECRET_OTHER}\n"
+	if string(data) != want {
+		t.Errorf("the tool read %q, want %q", data, want)
+	}
+
+	for _, path := range []string{secrets.HeadersFilePath(e.StateDir, "abc"), secrets.EnvFilePath(e.StateDir, "abc")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed when the session ended", path)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// LogFilePath returns where the output of an attempt of a session is
+// recorded. Every attempt keeps its own log, so a retry does not erase the
+// output of the attempt that failed: the first is <tool>-<id>.log, later
+// ones <tool>-<id>.<attempt>.log.
+func LogFilePath(outputDir, tool, id string, attempt int) string {
+	name := fmt.Sprintf("%s-%s.log", tool, id)
+	if attempt > 1 {
+		name = fmt.Sprintf("%s-%s.%d.log", tool, id, attempt)
+	}
+	return filepath.Join(outputDir, "logs", name)
+}
+
+// AttemptLog is the recorded output of one attempt of a session
+type AttemptLog struct {
+	Attempt int
+	Path    string
+}
+
+// AttemptLogs returns the logs recorded for the attempts of a session,
+// oldest first
+func (s *Session) AttemptLogs() []AttemptLog {
+	var logs []AttemptLog
+	for attempt := 1; attempt <= max(s.Attempts, 1); attempt++ {
+		path := LogFilePath(s.OutputDir, s.Tool, s.ID, attempt)
+		if _, err := os.Stat(path); err == nil {
+			logs = append(logs, AttemptLog{Attempt: attempt, Path: path})
+		}
+	}
+	return logs
+}
+
+// ReadLog returns the recorded output of the latest attempt of a session.
+// Sessions started before logging existed, or whose output could not be
+// recorded, fall back to the screen of their backend session, which only
+// works while it is alive.
+func (sm *SessionManager) ReadLog(session *Session) (string, error) {
+	if session.LogFile != "" {
+		data, err := os.ReadFile(session.LogFile)
+		if err == nil {
+			return string(data), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+
+	b, err := session.sessionBackend(sm.StateDir)
+	if err != nil {
+		return "", err
+	}
+	if b.Exists(session.TmuxSession) {
+		return b.Capture(session.TmuxSession)
+	}
+
+	return "", fmt.Errorf("no log recorded for session %s and its %s session is gone", session.ID, b.Name())
+}
+
+// FollowLog copies the session log to w and keeps copying new output until
+// the session stops running or ctx is cancelled
+func (sm *SessionManager) FollowLog(ctx context.Context, session *Session, w io.Writer) error {
+	if session.LogFile == "" {
+		return fmt.Errorf("session %s has no log file to follow", session.ID)
+	}
+
+	b, err := session.sessionBackend(sm.StateDir)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(session.LogFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for {
+		if _, err := io.Copy(w, file); err != nil {
+			return err
+		}
+
+		if !b.Exists(session.TmuxSession) {
+			// Drain whatever was written right before the session ended
+			_, err := io.Copy(w, file)
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// tailBytes is how much of the end of a log TailLog reads
+const tailBytes = 16 * 1024
+
+// escapeSequence matches terminal control sequences (colors, cursor
+// movement, window titles) that tools print along with their output
+var escapeSequence = regexp.MustCompile(`\x1b(\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[@-Z\\-_])`)
+
+// TailLog returns up to n of the last non-empty lines a session printed,
+// as they last appeared on screen: control sequences are removed and of a
+// progress line redrawn with carriage returns only the final state is kept
+func (sm *SessionManager) TailLog(session *Session, n int) ([]string, error) {
+	var output string
+	if session.LogFile != "" {
+		data, err := readTail(session.LogFile, tailBytes)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		output = string(data)
+	} else {
+		b, err := session.sessionBackend(sm.StateDir)
+		if err != nil {
+			return nil, err
+		}
+		if b.Exists(session.TmuxSession) {
+			captured, err := b.Capture(session.TmuxSession)
+			if err != nil {
+				return nil, err
+			}
+			output = captured
+		}
+	}
+
+	lines := screenLines(output)
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
+
+// readTail reads at most size bytes from the end of a file
+func readTail(path string, size int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size() - size
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(file)
+}
+
+// screenLines turns raw terminal output into the non-empty lines it shows
+func screenLines(output string) []string {
+	output = escapeSequence.ReplaceAllString(output, "")
+
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if i := strings.LastIndex(line, "\r"); i >= 0 {
+			line = line[i+1:]
+		}
+		line = strings.Map(func(r rune) rune {
+			switch {
+			case r == '\t':
+				return ' '
+			case r < ' ' || r == 0x7f:
+				return -1
+			}
+			return r
+		}, line)
+		if line = strings.TrimRight(line, " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScreenLines(t *testing.T) {
+	output := "\x1b]0;ffuf\x07\x1b[1;32mstarting\x1b[0m\r\n" +
+		"\r\n" +
+		":: Progress: [10/100] ::\r\x1b[2K:: Progress: [100/100] ::\n" +
+		"admin\t[Status: 301]\n"
+
+	want := []string{"starting", ":: Progress: [100/100] ::", "admin [Status: 301]"}
+	if got := screenLines(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("screenLines() = %q, want %q", got, want)
+	}
+}
+
+func TestTailLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ffuf.log")
+	var b strings.Builder
+	for i := 0; i < 5000; i++ {
+		b.WriteString("line\n")
+	}
+	b.WriteString("second to last\nlast\n")
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sm := NewSessionManager(t.TempDir())
+	lines, err := sm.TailLog(&Session{ID: "abc", LogFile: path}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"second to last", "last"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("TailLog() = %q, want %q", lines, want)
+	}
+}
+
+func TestAttemptLogs(t *testing.T) {
+	dir := t.TempDir()
+	s := &Session{ID: "abc", Tool: "ffuf", OutputDir: dir, Attempts: 3}
+
+	if got := LogFilePath(dir, "ffuf", "abc", 1); got != filepath.Join(dir, "logs", "ffuf-abc.log") {
+		t.Errorf("first attempt logs to %s", got)
+	}
+
+	// The second attempt left no log
+	if err := os.MkdirAll(filepath.Join(dir, "logs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, attempt := range []int{1, 3} {
+		if err := os.WriteFile(LogFilePath(dir, "ffuf", "abc", attempt), []byte("out\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []AttemptLog{
+		{Attempt: 1, Path: filepath.Join(dir, "logs", "ffuf-abc.log")},
+		{Attempt: 3, Path: filepath.Join(dir, "logs", "ffuf-abc.3.log")},
+	}
+	if got := s.AttemptLogs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("AttemptLogs() = %v, want %v", got, want)
+	}
+}
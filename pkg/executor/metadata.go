@@ -1,41 +1,105 @@
 package executor
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/backend"
+	"github.com/bc0d3/trident-recon/pkg/secrets"
+	"github.com/bc0d3/trident-recon/pkg/utils"
 )
 
 // Session represents a command execution session
 type Session struct {
-	ID          string    `json:"id"`
-	Tool        string    `json:"tool"`
-	CommandName string    `json:"command_name"`
-	Target      string    `json:"target"`
-	TmuxSession string    `json:"tmux_session"`
-	Command     string    `json:"command"`
-	OutputDir   string    `json:"output_dir"`
-	OutputFile  string    `json:"output_file"`
-	Wordlist    string    `json:"wordlist"`
-	StartedAt   time.Time `json:"started_at"`
-	Status      string    `json:"status"`
+	Version     int           `json:"version"` // Schema version of the session file
+	ID          string        `json:"id"`
+	RunID       string        `json:"run_id,omitempty"` // The run that started the session
+	Tool        string        `json:"tool"`
+	CommandName string        `json:"command_name"`
+	Target      string        `json:"target"`
+	TmuxSession string        `json:"tmux_session"`      // Name of the session in its backend
+	Backend     string        `json:"backend,omitempty"` // Backend the session runs in, tmux when empty
+	Command     string        `json:"command"`
+	Args        []string      `json:"args,omitempty"` // Set for argv templates, run without a shell
+	OutputDir   string        `json:"output_dir"`
+	OutputFile  string        `json:"output_file"`
+	LogFile     string        `json:"log_file,omitempty"`
+	Wordlist    string        `json:"wordlist"`
+	Timeout     time.Duration `json:"timeout,omitempty"`
+	Retries     int           `json:"retries,omitempty"`  // Automatic retries after a failure
+	Backoff     time.Duration `json:"backoff,omitempty"`  // Wait before the first retry, doubled after each one
+	Attempts    int           `json:"attempts,omitempty"` // Times the session was started
+	Rate        int           `json:"rate,omitempty"`     // Share of the rate budget, filled in for {RATE}
+	Threads     int           `json:"threads,omitempty"`  // Share of the threads budget, filled in for {THREADS}
+	QueuedAt    time.Time     `json:"queued_at"`
+	QueuePos    int           `json:"queue_pos,omitempty"`
+	StartedAt   time.Time     `json:"started_at"`
+	FinishedAt  time.Time     `json:"finished_at"`
+	Duration    time.Duration `json:"duration"`
+	ParsedAt    time.Time     `json:"parsed_at"` // When the output was last parsed into findings
+	Progress    *Progress     `json:"progress,omitempty"`
+	ExitCode    *int          `json:"exit_code,omitempty"`
+	Status      Status        `json:"status"`
+	Error       string        `json:"error,omitempty"`
+
+	// Secrets maps the environment variables the command reads to where
+	// their values come from. Values are resolved when the session starts.
+	Secrets map[string]secrets.Ref `json:"secrets,omitempty"`
+
+	// HeaderLines are written to the session's headers file when it starts,
+	// with ${TRIDENT_SECRET_...} references replaced by the secret values.
+	// Set for commands using {HEADERS-FILE}.
+	HeaderLines []string `json:"header_lines,omitempty"`
+}
+
+// sessionBackend returns the backend the session was started in
+func (s *Session) sessionBackend(stateDir string) (backend.Backend, error) {
+	return backend.New(s.Backend, stateDir)
 }
 
-// Save saves session metadata to disk
+// Argv returns the program and arguments the session runs: the argv list of
+// an args template, or bash running the command. Budgeted rates and threads
+// are filled in with the session's share.
+func (s *Session) Argv() []string {
+	if len(s.Args) > 0 {
+		argv := make([]string, len(s.Args))
+		for i, arg := range s.Args {
+			argv[i] = s.fillTokens(arg)
+		}
+		return argv
+	}
+	return []string{"bash", "-c", s.fillTokens(s.Command)}
+}
+
+// Save saves session metadata to disk, replacing the file atomically
 func (s *Session) Save(stateDir string) error {
-	jobsDir := filepath.Join(stateDir, "jobs")
-	if err := os.MkdirAll(jobsDir, 0755); err != nil {
+	s.Version = SchemaVersion
+	return writeState(filepath.Join(stateDir, "jobs", s.ID+".json"), s)
+}
+
+// NeedsParsing reports whether a session has ended since its output was
+// last parsed into findings
+func (s *Session) NeedsParsing() bool {
+	return s.Status.IsTerminal() && s.OutputFile != "" && s.ParsedAt.Before(s.FinishedAt)
+}
+
+// MarkParsed records that the output of a session was parsed
+func MarkParsed(stateDir, id string) error {
+	lock, err := lockState(stateDir)
+	if err != nil {
 		return err
 	}
+	defer lock.Unlock()
 
-	data, err := json.MarshalIndent(s, "", "  ")
+	session, err := Load(stateDir, id)
 	if err != nil {
 		return err
 	}
-
-	filename := filepath.Join(jobsDir, s.ID+".json")
-	return os.WriteFile(filename, data, 0644)
+	session.ParsedAt = time.Now()
+	return session.Save(stateDir)
 }
 
 // Load loads a session from disk
@@ -46,45 +110,80 @@ func Load(stateDir, id string) (*Session, error) {
 		return nil, err
 	}
 
-	var session Session
-	if err := json.Unmarshal(data, &session); err != nil {
+	session, err := decodeSession(data)
+	if err != nil {
 		return nil, err
 	}
 
 	return &session, nil
 }
 
-// LoadAll loads all sessions from disk
+// LoadAll loads all sessions from disk. Unchanged files are served from the
+// session index; files that cannot be parsed are moved aside with a
+// warning.
 func LoadAll(stateDir string) ([]Session, error) {
 	jobsDir := filepath.Join(stateDir, "jobs")
 
-	// Check if jobs directory exists
-	if _, err := os.Stat(jobsDir); os.IsNotExist(err) {
+	entries, err := os.ReadDir(jobsDir)
+	if os.IsNotExist(err) {
 		return []Session{}, nil
 	}
-
-	entries, err := os.ReadDir(jobsDir)
 	if err != nil {
 		return nil, err
 	}
 
+	index := loadIndex(stateDir)
+	fresh := &sessionIndex{Version: SchemaVersion, Entries: make(map[string]indexEntry)}
+	changed := false
+
 	var sessions []Session
 	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			// Deleted since the directory was read
+			continue
+		}
+
+		if session, ok := index.lookup(name, info); ok {
+			fresh.Entries[name] = index.Entries[name]
+			sessions = append(sessions, session)
 			continue
 		}
 
-		data, err := os.ReadFile(filepath.Join(jobsDir, entry.Name()))
+		path := filepath.Join(jobsDir, name)
+		readAt := time.Now()
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
 		if err != nil {
+			utils.PrintWarning(fmt.Sprintf("Skipping unreadable session file %s: %v", path, err))
 			continue
 		}
 
-		var session Session
-		if err := json.Unmarshal(data, &session); err != nil {
+		session, err := decodeSession(data)
+		if errors.Is(err, errNewerSchema) {
+			utils.PrintWarning(fmt.Sprintf("Skipping %s: %v", path, err))
+			continue
+		}
+		if err != nil {
+			quarantine(path, err)
 			continue
 		}
 
+		fresh.Entries[name] = indexEntry{ModTime: info.ModTime(), Size: info.Size(), ReadAt: readAt, Session: session}
 		sessions = append(sessions, session)
+		changed = true
+	}
+
+	// The index is only a cache; failing to update it costs speed, not data
+	if changed || len(fresh.Entries) != len(index.Entries) {
+		writeState(indexPath(stateDir), fresh)
 	}
 
 	return sessions, nil
@@ -93,5 +192,7 @@ func LoadAll(stateDir string) ([]Session, error) {
 // Delete deletes a session from disk
 func Delete(stateDir, id string) error {
 	filename := filepath.Join(stateDir, "jobs", id+".json")
+	os.Remove(ExitFilePath(stateDir, id))
+	removeStaged(stateDir, id)
 	return os.Remove(filename)
 }
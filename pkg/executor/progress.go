@@ -0,0 +1,204 @@
+package executor
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Progress is how far a tool got, as last shown in its output
+type Progress struct {
+	Processed int64     `json:"processed"`        // Requests made so far
+	Total     int64     `json:"total,omitempty"`  // Requests planned, 0 when unknown
+	Rate      float64   `json:"rate,omitempty"`   // Requests per second
+	Errors    int64     `json:"errors,omitempty"` // Errors the tool reported
+	UpdatedAt time.Time `json:"updated_at"`       // When the output was read
+}
+
+// Percent returns how much of the work is done, when the total is known
+func (p *Progress) Percent() (float64, bool) {
+	if p.Total <= 0 {
+		return 0, false
+	}
+	percent := float64(p.Processed) / float64(p.Total) * 100
+	if percent > 100 {
+		percent = 100
+	}
+	return percent, true
+}
+
+// ETA returns how long the rest of the work takes at the current rate
+func (p *Progress) ETA() (time.Duration, bool) {
+	if p.Total <= 0 || p.Rate <= 0 || p.Processed >= p.Total {
+		return 0, false
+	}
+	seconds := float64(p.Total-p.Processed) / p.Rate
+	return time.Duration(seconds * float64(time.Second)), true
+}
+
+// progressParser finds the latest progress in the lines a tool printed
+type progressParser func(lines []string) (Progress, bool)
+
+// progressParsers maps a tool to the parser for its progress output
+var progressParsers = map[string]progressParser{
+	"ffuf":        parseFfufProgress,
+	"gobuster":    parseGobusterProgress,
+	"feroxbuster": parseFeroxbusterProgress,
+	"dirsearch":   parseDirsearchProgress,
+}
+
+var (
+	// :: Progress: [1234/4614] :: Job [1/1] :: 523 req/sec :: Duration: [0:00:02] :: Errors: 0 ::
+	ffufProgress = regexp.MustCompile(`:: Progress: \[(\d+)/(\d+)\]`)
+	ffufRate     = regexp.MustCompile(`(\d+) req/sec`)
+	ffufErrors   = regexp.MustCompile(`Errors: (\d+)`)
+
+	// Progress: 1234 / 4615 (26.74%)
+	gobusterProgress = regexp.MustCompile(`Progress: (\d+) / (\d+)`)
+
+	// [####>-------] - 12s   1234/30000   2m   found:5   errors:3
+	// [####>-------] - 12s   1000/30000   100/s   http://example.com/
+	feroxbusterOverall   = regexp.MustCompile(`\] - \S+\s+(\d+)/(\d+)\s+\S+\s+found:\d+\s+errors:(\d+)`)
+	feroxbusterDirectory = regexp.MustCompile(`\] - \S+\s+\d+/\d+\s+(\d+)/s\s`)
+
+	// 45.67% - 2345/5136 - 230/s - job:1/1 - errors:0
+	// |███▍| 2345/10000 [23%] in 10s (230.1/s) job:1/1 errors:0
+	dirsearchJob    = regexp.MustCompile(`job:\s*\d+/\d+`)
+	dirsearchCounts = regexp.MustCompile(`(\d+)/(\d+)`)
+	dirsearchRate   = regexp.MustCompile(`([\d.]+)/s\b`)
+	dirsearchErrors = regexp.MustCompile(`errors:\s*(\d+)`)
+)
+
+func parseFfufProgress(lines []string) (Progress, bool) {
+	for i := len(lines) - 1; i >= 0; i-- {
+		m := ffufProgress.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		p := Progress{Processed: parseInt(m[1]), Total: parseInt(m[2])}
+		if m := ffufRate.FindStringSubmatch(lines[i]); m != nil {
+			p.Rate = float64(parseInt(m[1]))
+		}
+		if m := ffufErrors.FindStringSubmatch(lines[i]); m != nil {
+			p.Errors = parseInt(m[1])
+		}
+		return p, true
+	}
+	return Progress{}, false
+}
+
+func parseGobusterProgress(lines []string) (Progress, bool) {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if m := gobusterProgress.FindStringSubmatch(lines[i]); m != nil {
+			return Progress{Processed: parseInt(m[1]), Total: parseInt(m[2])}, true
+		}
+	}
+	return Progress{}, false
+}
+
+// parseFeroxbusterProgress reads the overall bar; the rate is the sum of
+// the per-directory bars drawn below it
+func parseFeroxbusterProgress(lines []string) (Progress, bool) {
+	for i := len(lines) - 1; i >= 0; i-- {
+		m := feroxbusterOverall.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		p := Progress{Processed: parseInt(m[1]), Total: parseInt(m[2]), Errors: parseInt(m[3])}
+		for _, line := range lines[i+1:] {
+			if m := feroxbusterDirectory.FindStringSubmatch(line); m != nil {
+				p.Rate += float64(parseInt(m[1]))
+			}
+		}
+		return p, true
+	}
+	return Progress{}, false
+}
+
+func parseDirsearchProgress(lines []string) (Progress, bool) {
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := lines[i]
+		if !dirsearchJob.MatchString(line) {
+			continue
+		}
+		m := dirsearchCounts.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		p := Progress{Processed: parseInt(m[1]), Total: parseInt(m[2])}
+		if m := dirsearchRate.FindStringSubmatch(line); m != nil {
+			p.Rate, _ = strconv.ParseFloat(m[1], 64)
+		}
+		if m := dirsearchErrors.FindStringSubmatch(line); m != nil {
+			p.Errors = parseInt(m[1])
+		}
+		return p, true
+	}
+	return Progress{}, false
+}
+
+func parseInt(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+// progressTool returns the tool whose progress format a session prints:
+// its configured tool name, or the program its command starts with
+func (s *Session) progressTool() string {
+	if _, ok := progressParsers[s.Tool]; ok {
+		return s.Tool
+	}
+	if fields := strings.Fields(s.Command); len(fields) > 0 {
+		return filepath.Base(fields[0])
+	}
+	return ""
+}
+
+// updateProgress reads the progress of a running session from its log, or
+// from the screen of its backend session when it has none, and reports
+// whether it changed
+func (s *Session) updateProgress(stateDir string, alive bool) bool {
+	parse, ok := progressParsers[s.progressTool()]
+	if !ok {
+		return false
+	}
+
+	var output string
+	if s.LogFile != "" {
+		data, err := readTail(s.LogFile, tailBytes)
+		if err != nil {
+			return false
+		}
+		output = string(data)
+	} else if alive {
+		b, err := s.sessionBackend(stateDir)
+		if err != nil {
+			return false
+		}
+		captured, err := b.Capture(s.TmuxSession)
+		if err != nil {
+			return false
+		}
+		output = captured
+	}
+
+	p, ok := parse(screenLines(output))
+	if !ok {
+		return false
+	}
+	if s.Progress != nil && s.Progress.Processed == p.Processed && s.Progress.Total == p.Total && s.Progress.Errors == p.Errors {
+		return false
+	}
+
+	p.UpdatedAt = time.Now()
+	if p.Rate == 0 && !s.StartedAt.IsZero() {
+		// Tools that print no rate get the average since the start
+		if elapsed := p.UpdatedAt.Sub(s.StartedAt).Seconds(); elapsed > 0 {
+			p.Rate = float64(p.Processed) / elapsed
+		}
+	}
+	s.Progress = &p
+	return true
+}
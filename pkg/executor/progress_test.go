@@ -0,0 +1,89 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProgressParsers(t *testing.T) {
+	tests := []struct {
+		tool   string
+		output string
+		want   Progress
+	}{
+		{
+			tool: "ffuf",
+			output: "\r\x1b[2K:: Progress: [100/4614] :: Job [1/1] :: 480 req/sec :: Duration: [0:00:01] :: Errors: 0 ::" +
+				"\r\x1b[2Kadmin                   [Status: 301, Size: 0, Words: 1, Lines: 1]\n" +
+				"\r\x1b[2K:: Progress: [2307/4614] :: Job [1/1] :: 523 req/sec :: Duration: [0:00:04] :: Errors: 7 ::",
+			want: Progress{Processed: 2307, Total: 4614, Rate: 523, Errors: 7},
+		},
+		{
+			tool:   "gobuster",
+			output: "/admin (Status: 301) [Size: 0]\n\rProgress: 120 / 4615 (2.60%)\rProgress: 1000 / 4000 (25.00%)",
+			want:   Progress{Processed: 1000, Total: 4000},
+		},
+		{
+			tool: "feroxbuster",
+			output: "200      GET       10l       20w      300c http://example.com/login\n" +
+				"\x1b[2K[####>---------------] - 12s     1234/30000   2m      found:5       errors:3\n" +
+				"\x1b[2K[####>---------------] - 12s      1000/20000   100/s   http://example.com/\n" +
+				"\x1b[2K[#>------------------] - 2s        234/10000   50/s    http://example.com/api/\n",
+			want: Progress{Processed: 1234, Total: 30000, Rate: 150, Errors: 3},
+		},
+		{
+			tool:   "dirsearch",
+			output: "[12:00:01] 200 -  1KB - /login\n45.67% - 2345/5136 - 230/s - job:1/1 - errors:2",
+			want:   Progress{Processed: 2345, Total: 5136, Rate: 230, Errors: 2},
+		},
+		{
+			tool:   "dirsearch",
+			output: "|███▍      | 2345/10000 [23%] in 10s (230.5/s) job:1/1 errors:0",
+			want:   Progress{Processed: 2345, Total: 10000, Rate: 230.5},
+		},
+	}
+
+	for _, tt := range tests {
+		got, ok := progressParsers[tt.tool](screenLines(tt.output))
+		if !ok || got != tt.want {
+			t.Errorf("%s: got %+v (%v), want %+v", tt.tool, got, ok, tt.want)
+		}
+	}
+}
+
+func TestProgressPercentAndETA(t *testing.T) {
+	p := Progress{Processed: 250, Total: 1000, Rate: 50}
+	if percent, ok := p.Percent(); !ok || percent != 25 {
+		t.Errorf("Percent() = %v, %v", percent, ok)
+	}
+	if eta, ok := p.ETA(); !ok || eta != 15*time.Second {
+		t.Errorf("ETA() = %v, %v", eta, ok)
+	}
+
+	p = Progress{Processed: 250}
+	if _, ok := p.Percent(); ok {
+		t.Error("Percent() without a total")
+	}
+}
+
+func TestUpdateProgressFromLog(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "gobuster.log")
+	if err := os.WriteFile(log, []byte("\rProgress: 100 / 1000 (10.00%)"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// gobuster prints no rate, so it is averaged since the start
+	s := Session{ID: "abc", Tool: "dirs", Command: "gobuster dir -u https://example.com", LogFile: log,
+		Status: StatusRunning, StartedAt: time.Now().Add(-10 * time.Second)}
+	if !s.updateProgress(t.TempDir(), false) {
+		t.Fatal("progress not read")
+	}
+	if s.Progress.Processed != 100 || s.Progress.Total != 1000 || s.Progress.Rate < 9 || s.Progress.Rate > 10.1 {
+		t.Errorf("progress = %+v", s.Progress)
+	}
+	if s.updateProgress(t.TempDir(), false) {
+		t.Error("unchanged progress reported as changed")
+	}
+}
@@ -0,0 +1,171 @@
+package executor
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// Run groups the sessions started by one 'trident-recon run'
+type Run struct {
+	Version    int            `json:"version"` // Schema version of the run file
+	ID         string         `json:"id"`
+	Targets    []string       `json:"targets"`
+	Profile    string         `json:"profile,omitempty"`
+	ConfigHash string         `json:"config_hash"`
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt time.Time      `json:"finished_at"` // Zero until every session has ended
+	Sessions   int            `json:"sessions"`
+	Counts     map[Status]int `json:"counts"` // Sessions per status as of the last refresh
+}
+
+// NewRunID returns a new run ID. IDs start with the start time, so they
+// sort in the order runs were started.
+func NewRunID() string {
+	suffix := make([]byte, 2)
+	rand.Read(suffix)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// runFilePath returns where a run record is stored
+func runFilePath(stateDir, id string) string {
+	return filepath.Join(stateDir, "runs", id+".json")
+}
+
+// Save saves the run record to disk, replacing the file atomically
+func (r *Run) Save(stateDir string) error {
+	r.Version = SchemaVersion
+	return writeState(runFilePath(stateDir, r.ID), r)
+}
+
+// Refresh updates the counts and finish time from the run's sessions and
+// reports whether anything changed. A run is not finished while a session
+// waits for an automatic retry.
+func (r *Run) Refresh(sessions []Session) bool {
+	counts := make(map[Status]int)
+	var finished time.Time
+	done := true
+	for _, s := range sessions {
+		if s.RunID != r.ID {
+			continue
+		}
+		counts[s.Status]++
+		if _, retry := s.retryAt(); retry || !s.Status.IsTerminal() {
+			done = false
+		}
+		if s.FinishedAt.After(finished) {
+			finished = s.FinishedAt
+		}
+	}
+	if !done {
+		finished = time.Time{}
+	}
+
+	changed := !finished.Equal(r.FinishedAt) || len(counts) != len(r.Counts)
+	for status, n := range counts {
+		if r.Counts[status] != n {
+			changed = true
+		}
+	}
+
+	r.Counts = counts
+	r.FinishedAt = finished
+	return changed
+}
+
+// Active reports whether any session of the run is queued or running
+func (r *Run) Active() bool {
+	return r.Counts[StatusQueued] > 0 || r.Counts[StatusRunning] > 0
+}
+
+// LoadRun loads a run record from disk
+func LoadRun(stateDir, id string) (*Run, error) {
+	data, err := os.ReadFile(runFilePath(stateDir, id))
+	if err != nil {
+		return nil, err
+	}
+
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, err
+	}
+	if run.Version > SchemaVersion {
+		return nil, fmt.Errorf("run %s is schema version %d: %w", run.ID, run.Version, errNewerSchema)
+	}
+
+	return &run, nil
+}
+
+// LoadRuns loads every run record, oldest first
+func LoadRuns(stateDir string) ([]Run, error) {
+	entries, err := os.ReadDir(filepath.Join(stateDir, "runs"))
+	if os.IsNotExist(err) {
+		return []Run{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []Run
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		run, err := LoadRun(stateDir, strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			utils.PrintWarning(fmt.Sprintf("Skipping unreadable run file %s: %v", entry.Name(), err))
+			continue
+		}
+		runs = append(runs, *run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.Before(runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+// DeleteRun removes a run record from disk
+func DeleteRun(stateDir, id string) error {
+	return os.Remove(runFilePath(stateDir, id))
+}
+
+// ResolveRunID expands a unique run ID prefix to the full run ID
+func ResolveRunID(stateDir, prefix string) (string, error) {
+	if prefix == "" {
+		return "", fmt.Errorf("empty run ID")
+	}
+
+	runs, err := LoadRuns(stateDir)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	for _, r := range runs {
+		if r.ID == prefix {
+			return r.ID, nil
+		}
+		if strings.HasPrefix(r.ID, prefix) {
+			matches = append(matches, r.ID)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no run matches %q", prefix)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("run ID %q is ambiguous, matches: %s", prefix, strings.Join(matches, ", "))
+	}
+}
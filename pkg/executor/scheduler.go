@@ -0,0 +1,321 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// SchedulerLimits caps how many sessions may run at the same time and the
+// rate budgets they share
+type SchedulerLimits struct {
+	MaxConcurrent int            // Global cap (0 = unlimited)
+	MaxPerHost    int            // Cap per target host (0 = unlimited)
+	PerTool       map[string]int // Cap per tool (missing or 0 = unlimited)
+	StartJitter   time.Duration  // Random delay between two session starts
+	Budgets       Budgets        // Rate and threads split among running sessions
+}
+
+// Scheduler starts queued sessions as running ones finish
+type Scheduler struct {
+	Executor     *Executor
+	Limits       SchedulerLimits
+	PollInterval time.Duration
+}
+
+// NewScheduler creates a new scheduler
+func NewScheduler(exec *Executor, limits SchedulerLimits) *Scheduler {
+	return &Scheduler{
+		Executor:     exec,
+		Limits:       limits,
+		PollInterval: 2 * time.Second,
+	}
+}
+
+// Enqueue marks sessions as queued in the executor's backend and saves them
+// to the state directory
+func (s *Scheduler) Enqueue(sessions []Session) error {
+	lock, err := lockState(s.Executor.StateDir)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	now := time.Now()
+	for i := range sessions {
+		if err := sessions[i].Transition(StatusQueued); err != nil {
+			return err
+		}
+		sessions[i].QueuedAt = now
+		sessions[i].QueuePos = i
+		sessions[i].Backend = s.Executor.Backend.Name()
+		if err := sessions[i].Save(s.Executor.StateDir); err != nil {
+			return fmt.Errorf("failed to queue session %s: %w", sessions[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// Requeue puts sessions that ended back in the queue, to run again with the
+// same command and output paths, in the backend set on the given sessions.
+// Sessions are reloaded first, so one that was started by another process
+// in the meantime is reported as running.
+func (s *Scheduler) Requeue(sessions []Session) error {
+	lock, err := lockState(s.Executor.StateDir)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	current, _, err := refreshAll(s.Executor.StateDir)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]Session, len(current))
+	for _, session := range current {
+		byID[session.ID] = session
+	}
+
+	for i := range sessions {
+		session, ok := byID[sessions[i].ID]
+		if !ok {
+			return fmt.Errorf("session %s no longer exists", sessions[i].ID)
+		}
+		if err := session.Requeue(); err != nil {
+			return err
+		}
+		session.Backend = sessions[i].Backend
+		if err := session.Save(s.Executor.StateDir); err != nil {
+			return fmt.Errorf("failed to queue session %s: %w", session.ID, err)
+		}
+		sessions[i] = session
+	}
+	return nil
+}
+
+// Tick performs a single scheduling pass: it starts as many queued sessions
+// as the limits allow and returns the sessions it started and how many are
+// still pending. Pending sessions are those waiting in the queue, waiting
+// out the backoff of an automatic retry, or running with retries left.
+// Budgeted sessions get their share of what the running ones left over, so
+// shares grow again as sessions finish. With a start jitter at most one
+// session is started per pass; Run waits out the jitter before the next.
+// The state lock is held throughout, so concurrent schedulers never start
+// the same session twice.
+func (s *Scheduler) Tick() ([]Session, int, error) {
+	lock, err := lockState(s.Executor.StateDir)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer lock.Unlock()
+
+	sessions, active, err := refreshAll(s.Executor.StateDir)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	usage := newSlotUsage()
+	budget := newBudgetUsage(s.Limits.Budgets)
+	var queued []Session
+	waiting := 0
+	for _, session := range sessions {
+		switch {
+		case session.Status == StatusQueued:
+			queued = append(queued, session)
+			budget.want(session)
+		case session.Status == StatusRunning && active[session.TmuxSession]:
+			usage.add(session)
+			budget.want(session)
+			budget.hold(session)
+			if session.Attempts <= session.Retries {
+				waiting++
+			}
+		default:
+			at, ok := session.retryAt()
+			if !ok {
+				continue
+			}
+			if now.Before(at) {
+				waiting++
+				continue
+			}
+
+			utils.PrintWarning(fmt.Sprintf("Retrying %s - %s (%s, retry %d of %d)", session.Tool, session.CommandName, session.Status, session.Attempts, session.Retries))
+			if err := session.Requeue(); err != nil {
+				return nil, 0, err
+			}
+			if err := session.Save(s.Executor.StateDir); err != nil {
+				return nil, 0, fmt.Errorf("failed to requeue session %s: %w", session.ID, err)
+			}
+			queued = append(queued, session)
+			budget.want(session)
+		}
+	}
+
+	var started []Session
+	remaining := 0
+	failed := make(map[string]bool)
+	for _, session := range roundRobin(queued) {
+		if !s.canStart(session, usage) {
+			remaining++
+			continue
+		}
+
+		if len(started) > 0 && s.Limits.StartJitter > 0 {
+			remaining++
+			continue
+		}
+
+		rate, threads, ok := budget.share(session, s.Limits)
+		if !ok {
+			remaining++
+			continue
+		}
+		session.Rate, session.Threads = rate, threads
+
+		session.Attempts++
+		if err := s.Executor.Execute(&session); err != nil {
+			utils.PrintError(fmt.Sprintf("Failed to execute %s - %s: %v", session.Tool, session.CommandName, err))
+			session.Status = StatusFailed
+			session.Error = err.Error()
+			session.FinishedAt = time.Now()
+			if err := session.Save(s.Executor.StateDir); err != nil {
+				return started, remaining, fmt.Errorf("failed to save session %s: %w", session.ID, err)
+			}
+			recordOutcome(s.Executor.StateDir, session)
+			failed[session.RunID] = true
+			continue
+		}
+
+		usage.add(session)
+		budget.hold(session)
+		started = append(started, session)
+		if session.Attempts <= session.Retries {
+			waiting++
+		}
+	}
+
+	// Sessions that could not be started may have ended their run
+	if len(failed) > 0 {
+		current, err := LoadAll(s.Executor.StateDir)
+		if err != nil {
+			return started, remaining, err
+		}
+		if err := updateRuns(s.Executor.StateDir, current, failed); err != nil {
+			return started, remaining, err
+		}
+	}
+
+	return started, remaining + waiting, nil
+}
+
+// Run keeps scheduling until no session is pending or ctx is cancelled.
+// onStart is called for every session that gets started.
+func (s *Scheduler) Run(ctx context.Context, onStart func(Session)) error {
+	for {
+		started, remaining, err := s.Tick()
+		if err != nil {
+			return err
+		}
+
+		if onStart != nil {
+			for _, session := range started {
+				onStart(session)
+			}
+		}
+
+		if remaining == 0 {
+			return nil
+		}
+
+		// Space out starts by the jitter rather than the poll interval
+		wait := s.PollInterval
+		if len(started) > 0 && s.Limits.StartJitter > 0 {
+			wait = time.Duration(rand.Int63n(int64(s.Limits.StartJitter)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (s *Scheduler) canStart(session Session, usage *slotUsage) bool {
+	if s.Limits.MaxConcurrent > 0 && usage.total >= s.Limits.MaxConcurrent {
+		return false
+	}
+	if s.Limits.MaxPerHost > 0 && usage.perHost[sessionHost(session)] >= s.Limits.MaxPerHost {
+		return false
+	}
+	if limit := s.Limits.PerTool[session.Tool]; limit > 0 && usage.perTool[session.Tool] >= limit {
+		return false
+	}
+	return true
+}
+
+// slotUsage counts running sessions globally, per tool and per host
+type slotUsage struct {
+	total   int
+	perTool map[string]int
+	perHost map[string]int
+}
+
+func newSlotUsage() *slotUsage {
+	return &slotUsage{
+		perTool: make(map[string]int),
+		perHost: make(map[string]int),
+	}
+}
+
+func (u *slotUsage) add(session Session) {
+	u.total++
+	u.perTool[session.Tool]++
+	u.perHost[sessionHost(session)]++
+}
+
+// sessionHost returns the host a session is pointed at
+func sessionHost(session Session) string {
+	_, domain, err := utils.ParseURL(session.Target)
+	if err != nil {
+		return session.Target
+	}
+	return utils.SanitizeDomain(domain)
+}
+
+// roundRobin orders queued sessions so that targets take turns: the oldest
+// session of every target comes first, then the second oldest, and so on.
+func roundRobin(queued []Session) []Session {
+	sort.SliceStable(queued, func(i, j int) bool {
+		if !queued[i].QueuedAt.Equal(queued[j].QueuedAt) {
+			return queued[i].QueuedAt.Before(queued[j].QueuedAt)
+		}
+		return queued[i].QueuePos < queued[j].QueuePos
+	})
+
+	var targets []string
+	byTarget := make(map[string][]Session)
+	for _, session := range queued {
+		if _, ok := byTarget[session.Target]; !ok {
+			targets = append(targets, session.Target)
+		}
+		byTarget[session.Target] = append(byTarget[session.Target], session)
+	}
+
+	ordered := make([]Session, 0, len(queued))
+	for round := 0; len(ordered) < len(queued); round++ {
+		for _, target := range targets {
+			if round < len(byTarget[target]) {
+				ordered = append(ordered, byTarget[target][round])
+			}
+		}
+	}
+
+	return ordered
+}
@@ -0,0 +1,185 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/backend"
+)
+
+func TestTickStartsSessionsInTheirBackend(t *testing.T) {
+	stateDir := t.TempDir()
+	newExecutor := func(name string) *Executor {
+		b, err := backend.New(name, stateDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &Executor{StateDir: stateDir, Backend: b}
+	}
+
+	// Queued by a run with the process backend
+	sessions := []Session{{
+		ID:          "abc123",
+		Tool:        "ffuf",
+		CommandName: "quick",
+		Command:     "true",
+		Target:      "https://example.com",
+		OutputDir:   filepath.Join(stateDir, "out"),
+		TmuxSession: "ff_abc123",
+		Status:      StatusPending,
+	}}
+	if err := NewScheduler(newExecutor(backend.Process), SchedulerLimits{}).Enqueue(sessions); err != nil {
+		t.Fatal(err)
+	}
+
+	// Started by a watch configured for tmux
+	started, _, err := NewScheduler(newExecutor(backend.Tmux), SchedulerLimits{}).Tick()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(started) != 1 || started[0].Backend != backend.Process {
+		t.Fatalf("Tick() started %+v, want the session in the process backend", started)
+	}
+	if _, err := os.Stat(filepath.Join(stateDir, "procs", "ff_abc123.pid")); err != nil {
+		t.Errorf("session was not started as a process: %v", err)
+	}
+
+	saved, err := Load(stateDir, "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Backend != backend.Process {
+		t.Errorf("saved backend = %q, want %q", saved.Backend, backend.Process)
+	}
+}
+
+// queueSleepers queues one long-running session per tool and target pair in
+// the process backend and kills them when the test ends
+func queueSleepers(t *testing.T, stateDir string, jobs [][2]string) *Executor {
+	t.Helper()
+
+	b, err := backend.New(backend.Process, stateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := &Executor{StateDir: stateDir, Backend: b}
+
+	sessions := make([]Session, len(jobs))
+	for i, job := range jobs {
+		id := fmt.Sprintf("s%02d", i)
+		sessions[i] = Session{
+			ID:          id,
+			Tool:        job[0],
+			CommandName: "sleep",
+			Command:     "sleep 30",
+			Target:      job[1],
+			OutputDir:   filepath.Join(stateDir, "out"),
+			TmuxSession: job[0] + "_" + id,
+			Status:      StatusPending,
+		}
+	}
+	if err := NewScheduler(exec, SchedulerLimits{}).Enqueue(sessions); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { NewSessionManager(stateDir).KillAllSessions("") })
+	return exec
+}
+
+func TestTickRespectsLimits(t *testing.T) {
+	const a, b = "https://a.example.com", "https://b.example.com"
+	tests := []struct {
+		name        string
+		limits      SchedulerLimits
+		jobs        [][2]string
+		wantStarted []string // Tools and targets of the started sessions
+	}{
+		{
+			name:        "global cap",
+			limits:      SchedulerLimits{MaxConcurrent: 2},
+			jobs:        [][2]string{{"ffuf", a}, {"ffuf", a}, {"ffuf", a}},
+			wantStarted: []string{"ffuf " + a, "ffuf " + a},
+		},
+		{
+			name:        "no global cap",
+			limits:      SchedulerLimits{},
+			jobs:        [][2]string{{"ffuf", a}, {"ffuf", a}, {"ffuf", a}},
+			wantStarted: []string{"ffuf " + a, "ffuf " + a, "ffuf " + a},
+		},
+		{
+			name:        "per-host cap",
+			limits:      SchedulerLimits{MaxPerHost: 1},
+			jobs:        [][2]string{{"ffuf", a}, {"gobuster", a}, {"ffuf", b}, {"gobuster", b}},
+			wantStarted: []string{"ffuf " + a, "ffuf " + b},
+		},
+		{
+			name:        "per-tool cap",
+			limits:      SchedulerLimits{PerTool: map[string]int{"ffuf": 1}},
+			jobs:        [][2]string{{"ffuf", a}, {"ffuf", b}, {"gobuster", a}},
+			wantStarted: []string{"ffuf " + a, "gobuster " + a},
+		},
+		{
+			name:        "start jitter",
+			limits:      SchedulerLimits{StartJitter: time.Second},
+			jobs:        [][2]string{{"ffuf", a}, {"ffuf", b}, {"gobuster", a}},
+			wantStarted: []string{"ffuf " + a},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stateDir := t.TempDir()
+			sched := NewScheduler(queueSleepers(t, stateDir, tt.jobs), tt.limits)
+
+			started, pending, err := sched.Tick()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, session := range started {
+				got = append(got, session.Tool+" "+session.Target)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.wantStarted) {
+				t.Errorf("started %q, want %q", got, tt.wantStarted)
+			}
+			if want := len(tt.jobs) - len(tt.wantStarted); pending != want {
+				t.Errorf("pending = %d, want %d", pending, want)
+			}
+
+			// Running sessions keep their slots
+			if tt.limits.StartJitter > 0 {
+				return
+			}
+			if again, _, err := sched.Tick(); err != nil || len(again) != 0 {
+				t.Errorf("second Tick() started %d session(s), %v; want none", len(again), err)
+			}
+		})
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	now := time.Now()
+	queued := []Session{
+		{ID: "a1", Target: "a", QueuedAt: now, QueuePos: 0},
+		{ID: "a2", Target: "a", QueuedAt: now, QueuePos: 1},
+		{ID: "a3", Target: "a", QueuedAt: now, QueuePos: 2},
+		{ID: "b1", Target: "b", QueuedAt: now, QueuePos: 3},
+		{ID: "c2", Target: "c", QueuedAt: now.Add(time.Second), QueuePos: 1},
+		{ID: "c1", Target: "c", QueuedAt: now.Add(time.Second), QueuePos: 0},
+		{ID: "old", Target: "b", QueuedAt: now.Add(-time.Minute), QueuePos: 9},
+	}
+
+	var got []string
+	for _, session := range roundRobin(queued) {
+		got = append(got, session.ID)
+	}
+	want := []string{"old", "a1", "c1", "b1", "a2", "c2", "a3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("roundRobin() = %q, want %q", got, want)
+	}
+}
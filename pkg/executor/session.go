@@ -2,11 +2,12 @@ package executor
 
 import (
 	"fmt"
-
-	"github.com/bc0d3/trident-recon/pkg/tmux"
+	"os"
+	"strings"
+	"time"
 )
 
-// SessionManager manages tmux sessions
+// SessionManager manages the sessions started by executors
 type SessionManager struct {
 	StateDir string
 }
@@ -18,80 +19,201 @@ func NewSessionManager(stateDir string) *SessionManager {
 	}
 }
 
-// ListSessions lists all active sessions
+// ListSessions lists all sessions with an up to date status
 func (sm *SessionManager) ListSessions(toolFilter string) ([]Session, error) {
-	// Load all saved sessions
-	sessions, err := LoadAll(sm.StateDir)
+	// Load all saved sessions and record any that have finished
+	sessions, _, err := RefreshAll(sm.StateDir)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get active tmux sessions
-	tmuxSessions, err := tmux.ListSessions()
-	if err != nil {
-		// If no tmux sessions, return empty list
-		tmuxSessions = []string{}
-	}
-
-	// Create map of active tmux sessions
-	activeSessions := make(map[string]bool)
-	for _, ts := range tmuxSessions {
-		activeSessions[ts] = true
-	}
-
 	// Filter sessions
-	var activeTridentSessions []Session
+	var filtered []Session
 	for _, s := range sessions {
-		// Check if session is still active in tmux
-		if !activeSessions[s.TmuxSession] {
-			s.Status = "completed"
-		} else {
-			s.Status = "running"
-		}
-
 		// Apply tool filter
 		if toolFilter != "" && s.Tool != toolFilter {
 			continue
 		}
 
-		activeTridentSessions = append(activeTridentSessions, s)
+		filtered = append(filtered, s)
 	}
 
-	return activeTridentSessions, nil
+	return filtered, nil
 }
 
-// KillSession kills a specific session
+// KillSession kills a specific session and marks it as killed
 func (sm *SessionManager) KillSession(id string) error {
+	// Hold the lock so a scheduler cannot start the session meanwhile
+	lock, err := lockState(sm.StateDir)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
 	// Load session metadata
-	session, err := Load(sm.StateDir, id)
+	session, err := sm.GetSession(id)
 	if err != nil {
 		return fmt.Errorf("session not found: %w", err)
 	}
 
-	// Kill tmux session
-	if tmux.SessionExists(session.TmuxSession) {
-		if err := tmux.KillSession(session.TmuxSession); err != nil {
-			return fmt.Errorf("failed to kill tmux session: %w", err)
+	b, err := session.sessionBackend(sm.StateDir)
+	if err != nil {
+		return err
+	}
+
+	// Record the real outcome if the session already ended on its own
+	exists := b.Exists(session.TmuxSession)
+	if reconcile(sm.StateDir, session, map[string]bool{session.TmuxSession: exists}) {
+		if err := session.Save(sm.StateDir); err != nil {
+			return fmt.Errorf("failed to save session metadata: %w", err)
+		}
+		os.Remove(ExitFilePath(sm.StateDir, session.ID))
+		removeStaged(sm.StateDir, session.ID)
+		recordOutcome(sm.StateDir, *session)
+		if err := sm.updateRun(session.RunID); err != nil {
+			return err
 		}
 	}
 
-	// Delete metadata
-	if err := Delete(sm.StateDir, id); err != nil {
-		return fmt.Errorf("failed to delete session metadata: %w", err)
+	if err := session.Transition(StatusKilled); err != nil {
+		return fmt.Errorf("session is %s, nothing to kill", session.Status)
+	}
+	session.FinishedAt = time.Now()
+	if !session.StartedAt.IsZero() {
+		session.Duration = session.FinishedAt.Sub(session.StartedAt)
 	}
 
-	return nil
+	// Save first so the session is not reported as lost once it is gone
+	if err := session.Save(sm.StateDir); err != nil {
+		return fmt.Errorf("failed to save session metadata: %w", err)
+	}
+
+	// Kill the session in its backend
+	if exists {
+		if err := b.Kill(session.TmuxSession); err != nil {
+			return fmt.Errorf("failed to kill %s session: %w", b.Name(), err)
+		}
+	}
+
+	// Killing the last active session ends its run
+	return sm.updateRun(session.RunID)
+}
+
+// updateRun refreshes the record of a run after one of its sessions ended
+func (sm *SessionManager) updateRun(runID string) error {
+	sessions, err := LoadAll(sm.StateDir)
+	if err != nil {
+		return err
+	}
+	return updateRuns(sm.StateDir, sessions, map[string]bool{runID: true})
 }
 
-// KillAllSessions kills all sessions, optionally filtered by tool
+// KillAllSessions kills all queued and running sessions, optionally filtered by tool
 func (sm *SessionManager) KillAllSessions(toolFilter string) (int, error) {
+	sessions, err := sm.ActiveSessions(toolFilter)
+	if err != nil {
+		return 0, err
+	}
+
+	killed := 0
+	for _, session := range sessions {
+		if err := sm.KillSession(session.ID); err != nil {
+			fmt.Printf("Warning: failed to kill session %s: %v\n", session.ID, err)
+			continue
+		}
+		killed++
+	}
+
+	return killed, nil
+}
+
+// ActiveSessions lists queued and running sessions, optionally filtered by tool
+func (sm *SessionManager) ActiveSessions(toolFilter string) ([]Session, error) {
 	sessions, err := sm.ListSessions(toolFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	var active []Session
+	for _, s := range sessions {
+		if s.Status.IsActive() {
+			active = append(active, s)
+		}
+	}
+
+	return active, nil
+}
+
+// ListRuns returns every run with counts refreshed from its sessions
+func (sm *SessionManager) ListRuns() ([]Run, error) {
+	sessions, _, err := RefreshAll(sm.StateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	runs, err := LoadRuns(sm.StateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range runs {
+		if !runs[i].Refresh(sessions) {
+			continue
+		}
+		if err := runs[i].Save(sm.StateDir); err != nil {
+			return nil, fmt.Errorf("failed to save run %s: %w", runs[i].ID, err)
+		}
+	}
+
+	return runs, nil
+}
+
+// GetRun gets a run by its full ID or a unique ID prefix, together with its
+// sessions
+func (sm *SessionManager) GetRun(id string) (*Run, []Session, error) {
+	fullID, err := ResolveRunID(sm.StateDir, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	run, err := LoadRun(sm.StateDir, fullID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sessions, _, err := RefreshAll(sm.StateDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var runSessions []Session
+	for _, s := range sessions {
+		if s.RunID == run.ID {
+			runSessions = append(runSessions, s)
+		}
+	}
+
+	if run.Refresh(runSessions) {
+		if err := run.Save(sm.StateDir); err != nil {
+			return nil, nil, fmt.Errorf("failed to save run %s: %w", run.ID, err)
+		}
+	}
+
+	return run, runSessions, nil
+}
+
+// KillRun kills the queued and running sessions of a run
+func (sm *SessionManager) KillRun(id string) (int, error) {
+	_, sessions, err := sm.GetRun(id)
 	if err != nil {
 		return 0, err
 	}
 
 	killed := 0
 	for _, session := range sessions {
+		if !session.Status.IsActive() {
+			continue
+		}
 		if err := sm.KillSession(session.ID); err != nil {
 			fmt.Printf("Warning: failed to kill session %s: %v\n", session.ID, err)
 			continue
@@ -102,21 +224,60 @@ func (sm *SessionManager) KillAllSessions(toolFilter string) (int, error) {
 	return killed, nil
 }
 
-// GetSession gets a specific session by ID
+// GetSession gets a specific session by its full ID or a unique ID prefix
 func (sm *SessionManager) GetSession(id string) (*Session, error) {
-	return Load(sm.StateDir, id)
+	fullID, err := sm.ResolveID(id)
+	if err != nil {
+		return nil, err
+	}
+	return Load(sm.StateDir, fullID)
+}
+
+// ResolveID expands a unique ID prefix to the full session ID
+func (sm *SessionManager) ResolveID(prefix string) (string, error) {
+	if prefix == "" {
+		return "", fmt.Errorf("empty session ID")
+	}
+
+	sessions, err := LoadAll(sm.StateDir)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	for _, s := range sessions {
+		if s.ID == prefix {
+			return s.ID, nil
+		}
+		if strings.HasPrefix(s.ID, prefix) {
+			matches = append(matches, s.ID)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no session matches %q", prefix)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("session ID %q is ambiguous, matches: %s", prefix, strings.Join(matches, ", "))
+	}
 }
 
-// AttachToSession attaches to a tmux session
+// AttachToSession hands the terminal to a session in its backend
 func (sm *SessionManager) AttachToSession(id string) error {
-	session, err := Load(sm.StateDir, id)
+	session, err := sm.GetSession(id)
 	if err != nil {
 		return fmt.Errorf("session not found: %w", err)
 	}
 
-	if !tmux.SessionExists(session.TmuxSession) {
-		return fmt.Errorf("tmux session no longer exists")
+	b, err := session.sessionBackend(sm.StateDir)
+	if err != nil {
+		return err
+	}
+	if !b.Exists(session.TmuxSession) {
+		return fmt.Errorf("%s session no longer exists", b.Name())
 	}
 
-	return tmux.AttachSession(session.TmuxSession)
+	return b.Attach(session.TmuxSession)
 }
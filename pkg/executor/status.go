@@ -0,0 +1,290 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status is the lifecycle state of a session
+type Status string
+
+// Session lifecycle states
+const (
+	StatusPending   Status = "pending"   // Generated, not handed to the scheduler yet
+	StatusQueued    Status = "queued"    // Waiting for a free scheduler slot
+	StatusRunning   Status = "running"   // Backend session is alive
+	StatusSucceeded Status = "succeeded" // Command exited with code 0
+	StatusFailed    Status = "failed"    // Command exited non-zero or could not be started
+	StatusKilled    Status = "killed"    // Stopped by the user
+	StatusTimedOut  Status = "timed-out" // Stopped after exceeding its timeout
+	StatusLost      Status = "lost"      // Backend session vanished without recording an exit
+)
+
+// timeoutExitCode is the exit code timeout(1) uses when the command times out
+const timeoutExitCode = 124
+
+// transitions lists the states each state may move to
+var transitions = map[Status][]Status{
+	StatusPending: {StatusQueued, StatusRunning, StatusKilled},
+	StatusQueued:  {StatusRunning, StatusFailed, StatusKilled},
+	StatusRunning: {StatusSucceeded, StatusFailed, StatusKilled, StatusTimedOut, StatusLost},
+}
+
+// IsTerminal reports whether no further transitions are expected
+func (s Status) IsTerminal() bool {
+	return len(transitions[s]) == 0
+}
+
+// IsActive reports whether the session is waiting or running
+func (s Status) IsActive() bool {
+	return s == StatusQueued || s == StatusRunning
+}
+
+// CanTransition reports whether a session may move from one state to another
+func CanTransition(from, to Status) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition moves the session to a new state, rejecting invalid moves
+func (s *Session) Transition(to Status) error {
+	from := s.Status
+	if from == "" {
+		from = StatusPending
+	}
+	if !CanTransition(from, to) {
+		return fmt.Errorf("session %s: invalid status transition %s -> %s", s.ID, from, to)
+	}
+	s.Status = to
+	return nil
+}
+
+// IsFailure reports whether the session ended without succeeding on its
+// own: it failed, timed out or was lost
+func (s Status) IsFailure() bool {
+	return s == StatusFailed || s == StatusTimedOut || s == StatusLost
+}
+
+// Requeue puts a session that ended back in the queue, to run again with
+// the same command and output paths. It bypasses the transition rules,
+// which treat ended sessions as final.
+func (s *Session) Requeue() error {
+	switch {
+	case s.Status == StatusRunning:
+		return fmt.Errorf("session %s is still running", s.ID)
+	case s.Status == StatusQueued:
+		return nil
+	}
+
+	s.Status = StatusQueued
+	s.QueuedAt = time.Now()
+	s.StartedAt = time.Time{}
+	s.FinishedAt = time.Time{}
+	s.Duration = 0
+	s.ExitCode = nil
+	s.Error = ""
+	s.Progress = nil
+	return nil
+}
+
+// retryAt reports whether a session that ended is retried automatically
+// under its retries policy, and when. The backoff doubles after every
+// attempt.
+func (s *Session) retryAt() (time.Time, bool) {
+	if s.Retries == 0 || !s.Status.IsFailure() || s.Attempts > s.Retries {
+		return time.Time{}, false
+	}
+
+	wait := s.Backoff
+	for i := 1; i < s.Attempts; i++ {
+		wait *= 2
+	}
+	return s.FinishedAt.Add(wait), true
+}
+
+// Elapsed returns how long the session ran, or has been running so far
+func (s *Session) Elapsed() time.Duration {
+	switch {
+	case s.StartedAt.IsZero():
+		return 0
+	case s.Status == StatusRunning:
+		return time.Since(s.StartedAt)
+	default:
+		return s.Duration
+	}
+}
+
+// ExitFilePath returns the file the command wrapper writes the exit status to
+func ExitFilePath(stateDir, id string) string {
+	return filepath.Join(stateDir, "jobs", id+".exit")
+}
+
+// readExitFile parses an exit file written by the command wrapper.
+// The file holds "<exit code> <finished unix time>".
+func readExitFile(path string) (int, time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return 0, time.Time{}, fmt.Errorf("invalid exit file %s", path)
+	}
+
+	code, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid exit code in %s: %w", path, err)
+	}
+
+	finished, err := parseUnixTime(fields[1])
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid finish time in %s: %w", path, err)
+	}
+
+	return code, finished, nil
+}
+
+// parseUnixTime parses seconds since the epoch with an optional fraction,
+// as printed by date +%s or date +%s.%N
+func parseUnixTime(s string) (time.Time, error) {
+	secs, frac, _ := strings.Cut(s, ".")
+	sec, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var nsec int64
+	if frac != "" {
+		if len(frac) > 9 {
+			frac = frac[:9]
+		}
+		if nsec, err = strconv.ParseInt(frac, 10, 64); err != nil || nsec < 0 {
+			return time.Time{}, fmt.Errorf("invalid fraction %q", frac)
+		}
+		for i := len(frac); i < 9; i++ {
+			nsec *= 10
+		}
+	}
+
+	return time.Unix(sec, nsec), nil
+}
+
+// reconcile updates a running session whose backend session is gone from the
+// exit file left by the wrapper. It reports whether the session changed.
+func reconcile(stateDir string, s *Session, active map[string]bool) bool {
+	if s.Status != StatusRunning || active[s.TmuxSession] {
+		return false
+	}
+
+	// Files staged for a session killed before it cleaned up after itself
+	removeStaged(stateDir, s.ID)
+
+	code, finished, err := readExitFile(ExitFilePath(stateDir, s.ID))
+	if err != nil {
+		s.Status = StatusLost
+		s.FinishedAt = time.Now()
+		return true
+	}
+
+	s.ExitCode = &code
+	s.FinishedAt = finished
+	s.Duration = finished.Sub(s.StartedAt)
+	if s.Duration < 0 {
+		// Without date +%N the finish time only has second precision
+		s.Duration = 0
+	}
+
+	switch {
+	case code == 0:
+		s.Status = StatusSucceeded
+	case code == timeoutExitCode && s.Timeout > 0:
+		s.Status = StatusTimedOut
+	default:
+		s.Status = StatusFailed
+	}
+
+	return true
+}
+
+// activeSessions returns the names of the live sessions in every backend
+// the sessions were started in
+func activeSessions(stateDir string, sessions []Session) map[string]bool {
+	active := make(map[string]bool)
+	listed := make(map[string]bool)
+	for _, s := range sessions {
+		b, err := s.sessionBackend(stateDir)
+		if err != nil || listed[b.Name()] {
+			continue
+		}
+		listed[b.Name()] = true
+
+		names, err := b.List()
+		if err != nil {
+			// If the backend lists nothing, treat its sessions as gone
+			continue
+		}
+		for _, name := range names {
+			active[name] = true
+		}
+	}
+	return active
+}
+
+// RefreshAll loads every session, records the outcome of sessions whose
+// backend session has ended and returns the up to date list together with
+// the set of live backend session names.
+func RefreshAll(stateDir string) ([]Session, map[string]bool, error) {
+	lock, err := lockState(stateDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer lock.Unlock()
+
+	return refreshAll(stateDir)
+}
+
+// refreshAll is RefreshAll for callers already holding the state lock. It
+// also rewrites sessions read from files of an older schema, and records
+// the events of sessions and runs that ended.
+func refreshAll(stateDir string) ([]Session, map[string]bool, error) {
+	sessions, err := LoadAll(stateDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	active := activeSessions(stateDir, sessions)
+
+	ended := make(map[string]bool)
+	for i := range sessions {
+		// Read the progress before reconciling, so sessions that just
+		// ended keep their final progress
+		progressed := sessions[i].Status == StatusRunning && sessions[i].updateProgress(stateDir, active[sessions[i].TmuxSession])
+		reconciled := reconcile(stateDir, &sessions[i], active)
+		if !reconciled && !progressed && sessions[i].Version == SchemaVersion {
+			continue
+		}
+		if err := sessions[i].Save(stateDir); err != nil {
+			return nil, nil, fmt.Errorf("failed to save session %s: %w", sessions[i].ID, err)
+		}
+		if reconciled {
+			os.Remove(ExitFilePath(stateDir, sessions[i].ID))
+			recordOutcome(stateDir, sessions[i])
+			ended[sessions[i].RunID] = true
+		}
+	}
+
+	if err := updateRuns(stateDir, sessions, ended); err != nil {
+		return nil, nil, err
+	}
+
+	return sessions, active, nil
+}
@@ -0,0 +1,112 @@
+package executor
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRetryAtDoublesBackoff(t *testing.T) {
+	finished := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := Session{Status: StatusFailed, Retries: 2, Backoff: time.Minute, FinishedAt: finished}
+
+	for attempts, want := range map[int]time.Duration{1: time.Minute, 2: 2 * time.Minute} {
+		s.Attempts = attempts
+		at, ok := s.retryAt()
+		if !ok || !at.Equal(finished.Add(want)) {
+			t.Errorf("attempt %d: retryAt() = %v, %v; want %v", attempts, at, ok, finished.Add(want))
+		}
+	}
+
+	s.Attempts = 3
+	if _, ok := s.retryAt(); ok {
+		t.Error("retried after the last attempt")
+	}
+
+	s.Attempts, s.Status = 1, StatusKilled
+	if _, ok := s.retryAt(); ok {
+		t.Error("retried a killed session")
+	}
+}
+
+func TestRequeue(t *testing.T) {
+	exit := 1
+	s := Session{ID: "abc", Status: StatusFailed, ExitCode: &exit, Error: "boom", FinishedAt: time.Now()}
+	if err := s.Requeue(); err != nil {
+		t.Fatal(err)
+	}
+	if s.Status != StatusQueued || s.ExitCode != nil || s.Error != "" || !s.FinishedAt.IsZero() {
+		t.Errorf("Requeue() left %+v", s)
+	}
+
+	s.Status = StatusRunning
+	if err := s.Requeue(); err == nil {
+		t.Error("requeued a running session")
+	}
+}
+
+func TestReadExitFile(t *testing.T) {
+	tests := []struct {
+		content string
+		code    int
+		want    time.Time
+	}{
+		{"0 1735787045.123456789\n", 0, time.Unix(1735787045, 123456789)},
+		{"3 1735787045.5\n", 3, time.Unix(1735787045, 500000000)},
+		{"0 1735787045.0001234567891\n", 0, time.Unix(1735787045, 123456)},
+		// Exit files of wrappers and date commands without %N
+		{"124 1735787045\n", 124, time.Unix(1735787045, 0)},
+	}
+	path := filepath.Join(t.TempDir(), "exit")
+	for _, tt := range tests {
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		code, finished, err := readExitFile(path)
+		if err != nil {
+			t.Errorf("readExitFile(%q): %v", tt.content, err)
+			continue
+		}
+		if code != tt.code || !finished.Equal(tt.want) {
+			t.Errorf("readExitFile(%q) = %d, %v, want %d, %v", tt.content, code, finished, tt.code, tt.want)
+		}
+	}
+
+	for _, content := range []string{"", "0\n", "x 1735787045\n", "0 1735787045.N\n", "0 1735787045.-5\n", "0 1 2\n"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := readExitFile(path); err == nil {
+			t.Errorf("readExitFile(%q) succeeded", content)
+		}
+	}
+}
+
+func TestWrapCommandRecordsSubsecondDuration(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not available")
+	}
+
+	e := &Executor{StateDir: t.TempDir()}
+	if err := os.MkdirAll(filepath.Dir(ExitFilePath(e.StateDir, "abc")), 0755); err != nil {
+		t.Fatal(err)
+	}
+	s := Session{ID: "abc", Status: StatusRunning, StartedAt: time.Now()}
+
+	script := e.wrapCommand(&s)
+	if err := exec.Command("bash", "-c", script, "trident-recon", "sh", "-c", "sleep 0.2; exit 3").Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reconcile(e.StateDir, &s, nil) {
+		t.Fatal("session was not reconciled")
+	}
+	if s.Status != StatusFailed || s.ExitCode == nil || *s.ExitCode != 3 {
+		t.Errorf("got status %s and exit code %v, want failed with 3", s.Status, s.ExitCode)
+	}
+	if s.Duration < 200*time.Millisecond || s.Duration > 5*time.Second {
+		t.Errorf("Duration = %v, want about 200ms", s.Duration)
+	}
+}
@@ -0,0 +1,139 @@
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// SchemaVersion is the version of the session and run files this build
+// writes. Files of older versions are migrated when they are read.
+const SchemaVersion = 1
+
+// errNewerSchema is returned for files written by a newer trident-recon
+var errNewerSchema = errors.New("written by a newer version of trident-recon")
+
+// lockState takes the state directory lock. Every change that reads a
+// session, decides on it and writes it back (refreshing, scheduling,
+// killing) holds the lock, so separate trident-recon processes do not
+// overwrite each other's updates. Reads need no lock as files are replaced
+// atomically.
+func lockState(stateDir string) (*utils.FileLock, error) {
+	lock, err := utils.LockFile(filepath.Join(stateDir, "state.lock"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock state directory: %w", err)
+	}
+	return lock, nil
+}
+
+// writeState atomically writes a state file with secret values redacted
+func writeState(path string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteFileAtomic(path, []byte(utils.Redact(string(data))), 0644)
+}
+
+// decodeSession parses a session file and migrates it from older schemas.
+// Version keeps the version the file was written with until it is saved
+// again.
+func decodeSession(data []byte) (Session, error) {
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return Session{}, err
+	}
+	if session.Version > SchemaVersion {
+		return Session{}, fmt.Errorf("session %s is schema version %d: %w", session.ID, session.Version, errNewerSchema)
+	}
+
+	migrateSession(&session)
+	return session, nil
+}
+
+// migrateSession upgrades a session read from a file of an older schema
+func migrateSession(s *Session) {
+	if s.Version < 1 {
+		// The first releases only knew "running" and "completed", set
+		// once tmux was gone without recording how the command ended
+		if s.Status == "completed" {
+			s.Status = StatusLost
+		}
+	}
+}
+
+// indexRacyWindow is how long after a file was written its cached copy is
+// still distrusted: file times have a coarse resolution, so a write in the
+// same tick as an earlier read could leave the time and size unchanged.
+const indexRacyWindow = time.Second
+
+// sessionIndex caches the parsed session files, so listing sessions reads
+// one file instead of every session file. Entries are checked against the
+// time and size of their file and re-read when it changed; the index is
+// only a cache and is rebuilt whenever it is missing or unreadable.
+type sessionIndex struct {
+	Version int                   `json:"version"`
+	Entries map[string]indexEntry `json:"entries"` // By session file name
+}
+
+type indexEntry struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	ReadAt  time.Time `json:"read_at"`
+	Session Session   `json:"session"`
+}
+
+// indexPath returns where the session index is stored
+func indexPath(stateDir string) string {
+	return filepath.Join(stateDir, "index.json")
+}
+
+// loadIndex reads the session index, or returns an empty one
+func loadIndex(stateDir string) *sessionIndex {
+	empty := &sessionIndex{Version: SchemaVersion, Entries: make(map[string]indexEntry)}
+
+	data, err := os.ReadFile(indexPath(stateDir))
+	if err != nil {
+		return empty
+	}
+
+	var index sessionIndex
+	if err := json.Unmarshal(data, &index); err != nil || index.Version != SchemaVersion || index.Entries == nil {
+		return empty
+	}
+	return &index
+}
+
+// lookup returns the cached session for a file if the file has not changed
+// since it was read
+func (idx *sessionIndex) lookup(name string, info os.FileInfo) (Session, bool) {
+	entry, ok := idx.Entries[name]
+	if !ok || entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime()) {
+		return Session{}, false
+	}
+	if entry.ReadAt.Sub(entry.ModTime) < indexRacyWindow {
+		return Session{}, false
+	}
+	return entry.Session, true
+}
+
+// quarantine moves an unreadable session file aside so it is reported once
+// and kept for inspection rather than skipped on every load
+func quarantine(path string, cause error) {
+	corrupt := path + ".corrupt"
+	if err := os.Rename(path, corrupt); err != nil {
+		utils.PrintWarning(fmt.Sprintf("Skipping unreadable session file %s: %v", path, cause))
+		return
+	}
+	utils.PrintWarning(fmt.Sprintf("Moved unreadable session file to %s: %v", corrupt, cause))
+}
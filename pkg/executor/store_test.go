@@ -0,0 +1,125 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeJob(t *testing.T, stateDir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(stateDir, "jobs", name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadAllMigratesAndQuarantines(t *testing.T) {
+	stateDir := t.TempDir()
+	writeJob(t, stateDir, "old.json", `{"id": "old", "tool": "ffuf", "status": "completed"}`)
+	writeJob(t, stateDir, "newer.json", `{"version": 99, "id": "newer"}`)
+	broken := writeJob(t, stateDir, "broken.json", `{"id": "bro`)
+
+	sessions, err := LoadAll(stateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != "old" || sessions[0].Status != StatusLost || sessions[0].Version != 0 {
+		t.Fatalf("LoadAll() = %+v", sessions)
+	}
+	if _, err := os.Stat(broken + ".corrupt"); err != nil {
+		t.Errorf("broken file not moved aside: %v", err)
+	}
+
+	// Refreshing rewrites the migrated session with the current schema
+	if _, _, err := RefreshAll(stateDir); err != nil {
+		t.Fatal(err)
+	}
+	session, err := Load(stateDir, "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Version != SchemaVersion || session.Status != StatusLost {
+		t.Errorf("after refresh: version %d, status %s", session.Version, session.Status)
+	}
+}
+
+func TestLoadAllUsesIndex(t *testing.T) {
+	stateDir := t.TempDir()
+	s := Session{ID: "abc", Tool: "ffuf", Status: StatusQueued}
+	if err := s.Save(stateDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Age the file past the racy window so its cached copy is trusted
+	path := filepath.Join(stateDir, "jobs", "abc.json")
+	old := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadAll(stateDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// An unchanged file is served from the index
+	index := loadIndex(stateDir)
+	entry := index.Entries["abc.json"]
+	entry.Session.Tool = "cached"
+	index.Entries["abc.json"] = entry
+	if err := writeState(indexPath(stateDir), index); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := LoadAll(stateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Tool != "cached" {
+		t.Fatalf("index not used: %+v", sessions)
+	}
+
+	// A changed file is read again
+	s.Status = StatusKilled
+	if err := s.Save(stateDir); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err = LoadAll(stateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Tool != "ffuf" || sessions[0].Status != StatusKilled {
+		t.Fatalf("changed file not reread: %+v", sessions)
+	}
+}
+
+func TestMarkParsed(t *testing.T) {
+	stateDir := t.TempDir()
+	s := Session{ID: "abc", Tool: "ffuf", OutputFile: "out.json", Status: StatusSucceeded, FinishedAt: time.Now()}
+	if err := s.Save(stateDir); err != nil {
+		t.Fatal(err)
+	}
+	if !s.NeedsParsing() {
+		t.Fatal("finished session does not need parsing")
+	}
+
+	if err := MarkParsed(stateDir, "abc"); err != nil {
+		t.Fatal(err)
+	}
+	session, err := Load(stateDir, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.NeedsParsing() {
+		t.Error("parsed session still needs parsing")
+	}
+
+	// Finishing again, e.g. after a retry, needs another parse
+	session.FinishedAt = time.Now().Add(time.Second)
+	if !session.NeedsParsing() {
+		t.Error("session finished after parsing does not need parsing")
+	}
+}
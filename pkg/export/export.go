@@ -0,0 +1,88 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/results"
+)
+
+// Supported export formats
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+	FormatSARIF = "sarif"
+)
+
+// Record is a finding as written by the JSON Lines exporter
+type Record struct {
+	results.Finding
+	Rule string `json:"rule"`
+}
+
+// Write exports findings in the given format
+func Write(w io.Writer, format string, findings []results.Finding, toolVersion string) error {
+	switch format {
+	case FormatJSONL:
+		return WriteJSONL(w, findings)
+	case FormatCSV:
+		return WriteCSV(w, findings)
+	case FormatSARIF:
+		return WriteSARIF(w, findings, toolVersion)
+	default:
+		return fmt.Errorf("unknown export format %q (use jsonl, csv or sarif)", format)
+	}
+}
+
+// WriteJSONL writes one JSON object per finding
+func WriteJSONL(w io.Writer, findings []results.Finding) error {
+	encoder := json.NewEncoder(w)
+	for _, f := range findings {
+		if err := encoder.Encode(Record{Finding: f, Rule: results.Classify(f).ID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var csvHeader = []string{
+	"target", "url", "path", "status", "length", "words", "lines",
+	"content_type", "redirect_to", "rule", "tool", "command_name", "session_id", "found_at",
+}
+
+// WriteCSV writes findings as CSV with a header row
+func WriteCSV(w io.Writer, findings []results.Finding) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, f := range findings {
+		row := []string{
+			f.Target,
+			f.URL,
+			f.Path,
+			strconv.Itoa(f.Status),
+			strconv.FormatInt(f.Length, 10),
+			strconv.Itoa(f.Words),
+			strconv.Itoa(f.Lines),
+			f.ContentType,
+			f.RedirectTo,
+			results.Classify(f).ID,
+			f.Tool,
+			f.CommandName,
+			f.SessionID,
+			f.FoundAt.Format(time.RFC3339),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
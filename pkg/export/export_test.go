@@ -0,0 +1,173 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/results"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var foundAt = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// testFindings covers a confirmed and an unconfirmed finding of the same
+// error rule, a warning rule, the default rule and a URL found twice
+var testFindings = []results.Finding{
+	{Target: "https://example.com", URL: "https://example.com/.git/HEAD", Path: "/.git/HEAD", Status: 200, Length: 23, Words: 2, Lines: 1, ContentType: "text/plain", Tool: "ffuf", CommandName: "quick", SessionID: "aaa111", FoundAt: foundAt},
+	{Target: "https://example.com", URL: "https://example.com/.env", Path: "/.env", Status: 403, Length: 199, Tool: "ffuf", CommandName: "quick", SessionID: "aaa111", FoundAt: foundAt},
+	{Target: "https://example.com", URL: "https://example.com/backup.zip", Path: "/backup.zip", Status: 301, Length: 0, RedirectTo: "https://example.com/backup.zip/", Tool: "gobuster", CommandName: "dir", SessionID: "bbb222", FoundAt: foundAt},
+	{Target: "https://example.com", URL: "https://example.com/site.bak", Path: "/site.bak", Status: 200, Length: 4096, Tool: "gobuster", CommandName: "dir", SessionID: "bbb222", FoundAt: foundAt},
+	{Target: "https://example.com", URL: "https://example.com/login, \"new\"", Path: "/login, \"new\"", Status: 200, Length: 512, Words: 40, Lines: 12, ContentType: "text/html; charset=utf-8", Tool: "ffuf", CommandName: "quick", SessionID: "aaa111", FoundAt: foundAt},
+	{Target: "https://example.com", URL: "https://example.com/.git/HEAD", Path: "/.git/HEAD", Status: 200, Length: 23, Tool: "feroxbuster", CommandName: "deep", SessionID: "ccc333", FoundAt: foundAt},
+}
+
+func TestJSONLRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSONL(&buf, testFindings); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []results.Finding
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %d: %v", len(got)+1, err)
+		}
+		if want := results.Classify(record.Finding).ID; record.Rule != want {
+			t.Errorf("%s has rule %q, want %q", record.URL, record.Rule, want)
+		}
+		got = append(got, record.Finding)
+	}
+	if !reflect.DeepEqual(got, testFindings) {
+		t.Errorf("JSONL round trip = %+v, want %+v", got, testFindings)
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, testFindings); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rows[0], csvHeader) {
+		t.Errorf("header = %v", rows[0])
+	}
+	if len(rows) != len(testFindings)+1 {
+		t.Fatalf("got %d rows, want %d", len(rows), len(testFindings)+1)
+	}
+
+	for i, row := range rows[1:] {
+		status, _ := strconv.Atoi(row[3])
+		length, _ := strconv.ParseInt(row[4], 10, 64)
+		words, _ := strconv.Atoi(row[5])
+		lines, _ := strconv.Atoi(row[6])
+		found, _ := time.Parse(time.RFC3339, row[13])
+		got := results.Finding{
+			Target: row[0], URL: row[1], Path: row[2], Status: status, Length: length,
+			Words: words, Lines: lines, ContentType: row[7], RedirectTo: row[8],
+			Tool: row[10], CommandName: row[11], SessionID: row[12], FoundAt: found,
+		}
+		if !reflect.DeepEqual(got, testFindings[i]) {
+			t.Errorf("row %d = %+v, want %+v", i+1, got, testFindings[i])
+		}
+		if want := results.Classify(testFindings[i]).ID; row[9] != want {
+			t.Errorf("row %d has rule %q, want %q", i+1, row[9], want)
+		}
+	}
+}
+
+func TestSARIFGolden(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, testFindings, "1.2.3"); err != nil {
+		t.Fatal(err)
+	}
+
+	golden := filepath.Join("testdata", "findings.sarif")
+	if *update {
+		if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("SARIF output differs from %s (run with -update to see the change):\n%s", golden, buf.String())
+	}
+
+	// Only findings served with 2xx keep the level of their rule
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	levels := make(map[string]string)
+	for _, result := range log.Runs[0].Results {
+		levels[result.Locations[0].PhysicalLocation.ArtifactLocation.URI] = result.Level
+	}
+	for url, want := range map[string]string{
+		"https://example.com/.git/HEAD":      "error",
+		"https://example.com/.env":           "note",
+		"https://example.com/backup.zip":     "note",
+		"https://example.com/site.bak":       "warning",
+		"https://example.com/login, \"new\"": "note",
+	} {
+		if levels[url] != want {
+			t.Errorf("%s has level %q, want %q", url, levels[url], want)
+		}
+	}
+	if len(log.Runs[0].Results) != 5 {
+		t.Errorf("got %d results, want 5 (one per URL and rule)", len(log.Runs[0].Results))
+	}
+}
+
+func TestSARIFConfirmedByLaterFinding(t *testing.T) {
+	findings := []results.Finding{
+		{Target: "https://example.com", URL: "https://example.com/.git/HEAD", Path: "/.git/HEAD", Status: 403, Length: 199, Tool: "gobuster", SessionID: "bbb222", FoundAt: foundAt},
+		{Target: "https://example.com", URL: "https://example.com/.git/HEAD", Path: "/.git/HEAD", Status: 200, Length: 23, Tool: "ffuf", SessionID: "aaa111", FoundAt: foundAt},
+		// A later unconfirmed finding does not downgrade it again
+		{Target: "https://example.com", URL: "https://example.com/.git/HEAD", Path: "/.git/HEAD", Status: 401, Length: 12, Tool: "dirsearch", SessionID: "ccc333", FoundAt: foundAt},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, findings, "1.2.3"); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if len(log.Runs[0].Results) != 1 {
+		t.Fatalf("got %d results, want 1", len(log.Runs[0].Results))
+	}
+
+	result := log.Runs[0].Results[0]
+	if result.Level != "error" {
+		t.Errorf("level = %q, want error", result.Level)
+	}
+	if strings.Contains(result.Message.Text, "not confirmed") || !strings.Contains(result.Message.Text, "HTTP 200") {
+		t.Errorf("message = %q, want the confirmed finding's", result.Message.Text)
+	}
+	if result.Properties.Status != 200 || result.Properties.Length != 23 {
+		t.Errorf("properties = %d, %d bytes, want 200, 23 bytes", result.Properties.Status, result.Properties.Length)
+	}
+	if len(result.Properties.Sessions) != 3 {
+		t.Errorf("got %d sessions, want all 3", len(result.Properties.Sessions))
+	}
+}
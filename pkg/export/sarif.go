@@ -0,0 +1,188 @@
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/bc0d3/trident-recon/pkg/results"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolInfoURI  = "https://github.com/bc0d3/trident-recon"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          sarifProperties   `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifProperties struct {
+	Target   string         `json:"target"`
+	Status   int            `json:"status"`
+	Length   int64          `json:"length"`
+	Sessions []sarifSession `json:"sessions"`
+}
+
+type sarifSession struct {
+	ID          string `json:"id"`
+	Tool        string `json:"tool"`
+	CommandName string `json:"command_name"`
+}
+
+// WriteSARIF writes findings as a SARIF 2.1.0 log. Findings of the same URL
+// and rule become a single result listing every session that found it.
+// Results keep the level of their rule only when one of their findings
+// answered 2xx.
+func WriteSARIF(w io.Writer, findings []results.Finding, toolVersion string) error {
+	allRules := append(append([]results.Rule{}, results.Rules...), results.DefaultRule)
+	ruleIndex := make(map[string]int)
+	driver := sarifDriver{
+		Name:           "trident-recon",
+		Version:        toolVersion,
+		InformationURI: toolInfoURI,
+	}
+	for i, rule := range allRules {
+		ruleIndex[rule.ID] = i
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   rule.ID,
+			Name:                 rule.Name,
+			ShortDescription:     sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{Level: rule.Level},
+		})
+	}
+
+	var order []string
+	byKey := make(map[string]*sarifResult)
+	confirmed := make(map[string]bool)
+	for _, f := range findings {
+		rule := results.Classify(f)
+		key := f.URL + "\x00" + rule.ID
+
+		result, ok := byKey[key]
+		if !ok {
+			result = &sarifResult{
+				RuleID:    rule.ID,
+				RuleIndex: ruleIndex[rule.ID],
+				Locations: []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: f.URL},
+					},
+				}},
+				PartialFingerprints: map[string]string{
+					"targetUrlRule/v1": fingerprint(f.URL, rule.ID),
+				},
+				Properties: sarifProperties{Target: f.Target},
+			}
+			byKey[key] = result
+			order = append(order, key)
+		}
+
+		// The first finding describes the result until one that served the
+		// content confirms it, whichever tool found it first
+		if !ok || !confirmed[key] && results.Confirmed(f) {
+			describe(result, rule, f)
+			confirmed[key] = results.Confirmed(f)
+		}
+
+		result.Properties.Sessions = append(result.Properties.Sessions, sarifSession{
+			ID:          f.SessionID,
+			Tool:        f.Tool,
+			CommandName: f.CommandName,
+		})
+	}
+
+	run := sarifRun{
+		Tool:    sarifTool{Driver: driver},
+		Results: make([]sarifResult, 0, len(order)),
+	}
+	for _, key := range order {
+		run.Results = append(run.Results, *byKey[key])
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
+
+// fingerprint identifies a result across exports
+func fingerprint(url, ruleID string) string {
+	sum := sha256.Sum256([]byte(url + "|" + ruleID))
+	return hex.EncodeToString(sum[:])
+}
+
+// describe sets the level, message, status and length of a result from a
+// finding. Findings not served with 2xx get the note level of Classify and
+// a message saying so.
+func describe(result *sarifResult, rule results.Rule, f results.Finding) {
+	message := fmt.Sprintf("%s: %s (HTTP %d, %d bytes)", rule.Name, f.URL, f.Status, f.Length)
+	if !results.Confirmed(f) {
+		message = fmt.Sprintf("%s, not confirmed: %s answered HTTP %d (%d bytes) without serving its content", rule.Name, f.URL, f.Status, f.Length)
+	}
+	result.Level = rule.Level
+	result.Message = sarifMessage{Text: message}
+	result.Properties.Status = f.Status
+	result.Properties.Length = f.Length
+}
@@ -0,0 +1,421 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// Var is a value available to command templates
+type Var struct {
+	Value    string
+	List     []string // Set for list values such as HEADERS
+	Optional bool     // May resolve to an empty string
+	Raw      bool     // Shell syntax inserted as is, e.g. -H "Name: value"
+	Env      string   // Rendered as a reference to this environment variable
+	Err      error    // Set when the placeholder must not be rendered, with why
+}
+
+// Vars maps placeholder names (without braces) to their values
+type Vars map[string]Var
+
+// text returns the value as it is rendered without a join
+func (v Var) text() string {
+	if v.List != nil {
+		return strings.Join(v.List, " ")
+	}
+	return v.Value
+}
+
+// UserVarPrefix starts the placeholders of user-defined variables, e.g.
+// {VAR-username}
+const UserVarPrefix = "VAR-"
+
+// lookup returns the variable for a placeholder. User variables that were
+// never set resolve to empty so they work with {if} and default.
+func (vars Vars) lookup(name string) (Var, bool) {
+	if v, ok := vars[name]; ok {
+		return v, true
+	}
+	if strings.HasPrefix(name, UserVarPrefix) {
+		return Var{}, true
+	}
+	return Var{}, false
+}
+
+// Template is a parsed command template.
+//
+// Syntax:
+//
+//	{URL}                        placeholder, must be known and non-empty
+//	{WORDLIST | basename}        helpers: lower, quote, raw, basename, join "sep"
+//	{PROXY | default "none"}     fallback for an empty value
+//	{if PROXY}-x {PROXY}{end}    conditional, also {if !NAME} and {else}
+//
+// Braces that do not look like a placeholder (awk programs, ${VAR}, {})
+// are left untouched.
+//
+// Values are quoted for the place they appear in the shell command: as a
+// quoted word when unquoted, escaped inside "..." and '...'. The quote and
+// raw helpers turn this off for a placeholder.
+type Template struct {
+	nodes []node
+}
+
+// quoting is how a placeholder value has to be escaped where it appears
+type quoting int
+
+const (
+	quoteNone     quoting = iota // argv element, no shell involved
+	quoteUnquoted                // bare shell word
+	quoteSingle                  // between single quotes
+	quoteDouble                  // between double quotes
+)
+
+// scanQuotes returns the quoting in effect after the shell reads s
+func scanQuotes(s string, q quoting) quoting {
+	escaped := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if q == quoteSingle {
+			if c == '\'' {
+				q = quoteUnquoted
+			}
+			continue
+		}
+
+		if escaped {
+			escaped = false
+			continue
+		}
+		switch c {
+		case '\\':
+			escaped = true
+		case '\'':
+			if q == quoteUnquoted {
+				q = quoteSingle
+			}
+		case '"':
+			if q == quoteUnquoted {
+				q = quoteDouble
+			} else {
+				q = quoteUnquoted
+			}
+		}
+	}
+	return q
+}
+
+// varName matches placeholder names such as URL, OUTPUT_DIR, HEADER-User-Agent
+const varName = `[A-Z][A-Z0-9_]*(?:-[A-Za-z0-9_.-]+)?`
+
+var tagPattern = regexp.MustCompile(`\{(?:if\s+(!?)(` + varName + `)|(else)|(end)|(` + varName + `)((?:\s*\|[^{}|]+)*))\}`)
+
+type node interface {
+	render(vars Vars, b *strings.Builder) error
+}
+
+type textNode string
+
+type varNode struct {
+	name     string
+	pipeline []call
+	quoting  quoting
+}
+
+type ifNode struct {
+	name    string
+	negate  bool
+	then    []node
+	els     []node
+	inElse  bool
+	quoting quoting // In effect at the {if}, restored at {else}
+}
+
+type call struct {
+	fn   string
+	args []string
+}
+
+// helpers maps helper names to the number of arguments they take
+var helpers = map[string]int{
+	"default":  1,
+	"join":     1,
+	"lower":    0,
+	"quote":    0,
+	"raw":      0,
+	"basename": 0,
+}
+
+// ParseTemplate parses a command template run by the shell
+func ParseTemplate(text string) (*Template, error) {
+	return parseTemplate(text, quoteUnquoted)
+}
+
+// ParseArgTemplate parses a template for a single argv element. No shell
+// is involved, so values are inserted as they are and shell syntax
+// placeholders such as {HEADERS-ALL} are refused.
+func ParseArgTemplate(text string) (*Template, error) {
+	return parseTemplate(text, quoteNone)
+}
+
+func parseTemplate(text string, q quoting) (*Template, error) {
+	root := &ifNode{}
+	stack := []*ifNode{root}
+
+	appendNode := func(n node) {
+		top := stack[len(stack)-1]
+		if top.inElse {
+			top.els = append(top.els, n)
+		} else {
+			top.then = append(top.then, n)
+		}
+	}
+	appendText := func(t string) {
+		appendNode(textNode(t))
+		if q != quoteNone {
+			q = scanQuotes(t, q)
+		}
+	}
+
+	pos := 0
+	for _, m := range tagPattern.FindAllStringSubmatchIndex(text, -1) {
+		// A "$" before the brace is shell parameter expansion, not a placeholder
+		if m[0] > 0 && text[m[0]-1] == '$' {
+			continue
+		}
+
+		if m[0] > pos {
+			appendText(text[pos:m[0]])
+		}
+		pos = m[1]
+
+		group := func(i int) string {
+			if m[2*i] < 0 {
+				return ""
+			}
+			return text[m[2*i]:m[2*i+1]]
+		}
+
+		switch {
+		case group(2) != "":
+			n := &ifNode{name: group(2), negate: group(1) == "!", quoting: q}
+			appendNode(n)
+			stack = append(stack, n)
+		case group(3) != "":
+			top := stack[len(stack)-1]
+			if top == root || top.inElse {
+				return nil, fmt.Errorf("unexpected {else}")
+			}
+			top.inElse = true
+			q = top.quoting
+		case group(4) != "":
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("unexpected {end}")
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			pipeline, err := parsePipeline(group(6))
+			if err != nil {
+				return nil, fmt.Errorf("{%s}: %w", group(5), err)
+			}
+			appendNode(&varNode{name: group(5), pipeline: pipeline, quoting: q})
+		}
+	}
+
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("{if %s} is missing its {end}", stack[len(stack)-1].name)
+	}
+	if pos < len(text) {
+		appendText(text[pos:])
+	}
+
+	return &Template{nodes: root.then}, nil
+}
+
+// parsePipeline parses "| helper arg | helper" into calls
+func parsePipeline(s string) ([]call, error) {
+	var calls []call
+	for _, segment := range strings.Split(s, "|")[1:] {
+		fields, err := splitArgs(strings.TrimSpace(segment))
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("empty helper")
+		}
+
+		argc, ok := helpers[fields[0]]
+		if !ok {
+			return nil, fmt.Errorf("unknown helper %q", fields[0])
+		}
+		if len(fields)-1 != argc {
+			return nil, fmt.Errorf("helper %s takes %d argument(s)", fields[0], argc)
+		}
+
+		calls = append(calls, call{fn: fields[0], args: fields[1:]})
+	}
+	return calls, nil
+}
+
+// splitArgs splits helper arguments on spaces, keeping "double quoted" strings
+func splitArgs(s string) ([]string, error) {
+	var fields []string
+	for s != "" {
+		if s[0] == '"' {
+			end := strings.Index(s[1:], `"`)
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated string in %q", s)
+			}
+			fields = append(fields, s[1:end+1])
+			s = strings.TrimSpace(s[end+2:])
+			continue
+		}
+
+		end := strings.IndexAny(s, " \t")
+		if end == -1 {
+			end = len(s)
+		}
+		fields = append(fields, s[:end])
+		s = strings.TrimSpace(s[end:])
+	}
+	return fields, nil
+}
+
+// Execute renders the template. It fails on unknown placeholders and on
+// placeholders that resolve to an empty value without a default.
+func (t *Template) Execute(vars Vars) (string, error) {
+	var b strings.Builder
+	if err := renderNodes(t.nodes, vars, &b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func renderNodes(nodes []node, vars Vars, b *strings.Builder) error {
+	for _, n := range nodes {
+		if err := n.render(vars, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n textNode) render(vars Vars, b *strings.Builder) error {
+	b.WriteString(string(n))
+	return nil
+}
+
+func (n *ifNode) render(vars Vars, b *strings.Builder) error {
+	v, ok := vars.lookup(n.name)
+	if !ok {
+		return fmt.Errorf("unknown placeholder {%s} in {if}", n.name)
+	}
+
+	if (v.text() != "" || v.Env != "") != n.negate {
+		return renderNodes(n.then, vars, b)
+	}
+	return renderNodes(n.els, vars, b)
+}
+
+func (n *varNode) render(vars Vars, b *strings.Builder) error {
+	v, ok := vars.lookup(n.name)
+	if !ok {
+		return fmt.Errorf("unknown placeholder {%s}", n.name)
+	}
+
+	if v.Err != nil {
+		return fmt.Errorf("{%s}: %w", n.name, v.Err)
+	}
+	if v.Env != "" {
+		return n.renderEnv(v.Env, b)
+	}
+
+	value := v.text()
+	hasDefault := false
+	quoted := false
+	for _, c := range n.pipeline {
+		switch c.fn {
+		case "default":
+			hasDefault = true
+			if value == "" {
+				value = c.args[0]
+			}
+		case "join":
+			if v.List != nil {
+				value = strings.Join(v.List, c.args[0])
+			}
+		case "lower":
+			value = strings.ToLower(value)
+		case "quote":
+			value = utils.ShellQuote(value)
+			quoted = true
+		case "raw":
+			quoted = true
+		case "basename":
+			if value != "" {
+				value = filepath.Base(value)
+			}
+		}
+	}
+
+	if value == "" && !v.Optional && !hasDefault {
+		if name, ok := strings.CutPrefix(n.name, UserVarPrefix); ok {
+			return fmt.Errorf("variable %s is not set (set it under vars:, in the profile, on the target line or with --var)", name)
+		}
+		return fmt.Errorf("placeholder {%s} is empty", n.name)
+	}
+
+	if v.Raw {
+		if n.quoting == quoteNone {
+			return fmt.Errorf("{%s} is shell syntax and cannot be used in args", n.name)
+		}
+		b.WriteString(value)
+		return nil
+	}
+
+	if !quoted {
+		value = n.quoting.escape(value)
+	}
+	b.WriteString(value)
+	return nil
+}
+
+// renderEnv writes a reference to an environment variable that the shell
+// expands when the command runs
+func (n *varNode) renderEnv(env string, b *strings.Builder) error {
+	if len(n.pipeline) > 0 {
+		return fmt.Errorf("{%s} cannot be used with helpers", n.name)
+	}
+
+	ref := "${" + env + "}"
+	switch n.quoting {
+	case quoteNone:
+		return fmt.Errorf("{%s} needs the shell and cannot be used in args", n.name)
+	case quoteUnquoted:
+		ref = `"` + ref + `"`
+	case quoteSingle:
+		ref = `'"` + ref + `"'`
+	}
+	b.WriteString(ref)
+	return nil
+}
+
+// escape makes value safe to insert at a place with this quoting
+func (q quoting) escape(value string) string {
+	switch q {
+	case quoteUnquoted:
+		if value == "" {
+			return ""
+		}
+		return utils.ShellQuote(value)
+	case quoteSingle:
+		return utils.ShellEscapeSingle(value)
+	case quoteDouble:
+		return utils.ShellEscapeDouble(value)
+	}
+	return value
+}
@@ -0,0 +1,257 @@
+package generator
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/secrets"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// hostileTargets are target names that inject commands when substituted
+// into a shell command unquoted
+var hostileTargets = []string{
+	"https://example.com",
+	"https://example.com/;touch /tmp/trident-pwned",
+	"https://$(id).example.com",
+	"https://example.com/`id`",
+	"example.com$(id)",
+	"https://example.com/it's",
+	`https://example.com/"quoted"`,
+	`https://example.com/back\slash`,
+	"https://example.com/two words",
+	"https://example.com/a|b&c>d",
+	"https://example.com/${HOME}",
+	"https://example.com/*",
+}
+
+// runArgs runs command with bash and returns the arguments it printed
+func runArgs(t *testing.T, command string) []string {
+	t.Helper()
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+
+	out, err := exec.Command(bash, "-c", command).Output()
+	if err != nil {
+		t.Fatalf("bash -c %q: %v", command, err)
+	}
+	args := strings.Split(string(out), "\x00")
+	return args[:len(args)-1]
+}
+
+func TestTemplateQuotesByContext(t *testing.T) {
+	templates := map[string]func(url string) []string{
+		`printf '%s\0' {URL}`:              func(url string) []string { return []string{url} },
+		`printf '%s\0' {URL}/FUZZ`:         func(url string) []string { return []string{url + "/FUZZ"} },
+		`printf '%s\0' "url={URL}"`:        func(url string) []string { return []string{"url=" + url} },
+		`printf '%s\0' 'Host: {URL}'`:      func(url string) []string { return []string{"Host: " + url} },
+		`printf '%s\0' {if URL}{URL}{end}`: func(url string) []string { return []string{url} },
+		`printf '%s\0' "{if URL}{URL}{else}none{end}" {URL}`: func(url string) []string {
+			return []string{url, url}
+		},
+	}
+
+	for text, want := range templates {
+		tmpl, err := ParseTemplate(text)
+		if err != nil {
+			t.Fatalf("ParseTemplate(%q): %v", text, err)
+		}
+
+		for _, url := range hostileTargets {
+			command, err := tmpl.Execute(Vars{"URL": {Value: url}})
+			if err != nil {
+				t.Fatalf("%q with %q: %v", text, url, err)
+			}
+			if got := runArgs(t, command); !reflect.DeepEqual(got, want(url)) {
+				t.Errorf("%q with %q ran as %q, want %q", text, url, got, want(url))
+			}
+		}
+	}
+}
+
+func TestTemplateExplicitQuoting(t *testing.T) {
+	vars := Vars{"URL": {Value: "a b"}, "FLAGS": {Value: "-x -y"}}
+	for text, want := range map[string]string{
+		"cmd {URL | quote}":   "cmd 'a b'",
+		"cmd {FLAGS | raw}":   "cmd -x -y",
+		"cmd {FLAGS}":         "cmd '-x -y'",
+		`cmd "{URL | raw}"`:   `cmd "a b"`,
+		"cmd {MISSING | raw}": "",
+	} {
+		tmpl, err := ParseTemplate(text)
+		if err != nil {
+			t.Fatalf("ParseTemplate(%q): %v", text, err)
+		}
+		got, err := tmpl.Execute(vars)
+		if want == "" {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %q", text, got)
+			}
+			continue
+		}
+		if err != nil || got != want {
+			t.Errorf("%q = %q, %v; want %q", text, got, err, want)
+		}
+	}
+}
+
+func TestSecretReferenceByContext(t *testing.T) {
+	vars := Vars{"SECRET-tok": {Env: "TRIDENT_SECRET_TOK"}}
+	for text, want := range map[string]string{
+		"curl -H {SECRET-tok}":                  `curl -H "${TRIDENT_SECRET_TOK}"`,
+		`curl -H "Authorization: {SECRET-tok}"`: `curl -H "Authorization: ${TRIDENT_SECRET_TOK}"`,
+		`curl -H 'Authorization: {SECRET-tok}'`: `curl -H 'Authorization: '"${TRIDENT_SECRET_TOK}"''`,
+	} {
+		tmpl, err := ParseTemplate(text)
+		if err != nil {
+			t.Fatalf("ParseTemplate(%q): %v", text, err)
+		}
+		got, err := tmpl.Execute(vars)
+		if err != nil || got != want {
+			t.Errorf("%q = %q, %v; want %q", text, got, err, want)
+		}
+	}
+}
+
+func testConfig(command string, args []string) *config.Config {
+	return &config.Config{
+		Global: config.GlobalConfig{IDLength: 12},
+		Headers: config.HeadersConfig{
+			Custom: []string{`X-Note: say "hi" for $5`},
+		},
+		Tools: map[string]config.ToolConfig{
+			"echo": {
+				Enabled:    true,
+				TmuxPrefix: "echo_",
+				Commands:   []config.CommandTemplate{{Name: "args", Command: command, Args: args}},
+			},
+		},
+	}
+}
+
+func TestGeneratedCommandRoundTrip(t *testing.T) {
+	cfg := testConfig(`printf '%s\0' {URL} {DOMAIN} {HEADERS-ALL} -o {OUTPUT_DIR}/out.txt`, nil)
+
+	for _, target := range hostileTargets {
+		gen := New(cfg, target, "/tmp/out dir")
+		sessions, err := gen.Generate(nil, nil)
+		if err != nil {
+			t.Fatalf("Generate(%q): %v", target, err)
+		}
+		session := sessions[0]
+
+		want := []string{utils.NormalizeURL(target), domainOf(t, target), "-H", `X-Note: say "hi" for $5`, "-o", "/tmp/out dir/out.txt"}
+		if got := runArgs(t, session.Command); !reflect.DeepEqual(got, want) {
+			t.Errorf("target %q ran as %q, want %q", target, got, want)
+		}
+		if session.OutputFile != "/tmp/out dir/out.txt" {
+			t.Errorf("target %q: output file %q", target, session.OutputFile)
+		}
+
+		// The copy-paste line splits back into what the executor runs
+		line := utils.ShellSplit(tmuxCommandLine(session))
+		wantLine := []string{"tmux", "new-session", "-d", "-s", session.TmuxSession, "bash", "-c", session.Command}
+		if !reflect.DeepEqual(line, wantLine) {
+			t.Errorf("target %q: tmux line splits into %q, want %q", target, line, wantLine)
+		}
+	}
+}
+
+func TestArgsTemplateSkipsShell(t *testing.T) {
+	cfg := testConfig("", []string{"ffuf", "-u", "{URL}/FUZZ", "-o", "{OUTPUT_DIR}/ffuf.json"})
+
+	for _, target := range hostileTargets {
+		sessions, err := New(cfg, target, "/tmp/out").Generate(nil, nil)
+		if err != nil {
+			t.Fatalf("Generate(%q): %v", target, err)
+		}
+		session := sessions[0]
+
+		want := []string{"ffuf", "-u", utils.NormalizeURL(target) + "/FUZZ", "-o", "/tmp/out/ffuf.json"}
+		if !reflect.DeepEqual(session.Argv(), want) {
+			t.Errorf("target %q: argv %q, want %q", target, session.Argv(), want)
+		}
+		if got := utils.ShellSplit(session.Command); !reflect.DeepEqual(got, want) {
+			t.Errorf("target %q: displayed command splits into %q", target, got)
+		}
+		if session.OutputFile != "/tmp/out/ffuf.json" {
+			t.Errorf("target %q: output file %q", target, session.OutputFile)
+		}
+	}
+}
+
+func TestArgsTemplateRefusesShellSyntax(t *testing.T) {
+	cfg := testConfig("", []string{"ffuf", "{HEADERS-ALL}"})
+	if _, err := New(cfg, "https://example.com", "/tmp/out").Generate(nil, nil); err == nil {
+		t.Error("expected {HEADERS-ALL} to be refused in args")
+	}
+}
+
+func domainOf(t *testing.T, target string) string {
+	t.Helper()
+	_, domain, err := utils.ParseURL(target)
+	if err != nil {
+		t.Fatalf("ParseURL(%q): %v", target, err)
+	}
+	return utils.SanitizeDomain(domain)
+}
+
+func TestHeaderSecretsOnlyInHeadersFile(t *testing.T) {
+	cfg := testConfig("", nil)
+	cfg.Headers = config.HeadersConfig{
+		Default: map[string]string{"Accept": "*/*"},
+		Custom:  []string{"Cookie: sid={SECRET-session}"},
+	}
+	cfg.Secrets = map[string]secrets.Ref{"session": {Env: "TEST_SESSION"}}
+
+	generate := func(command string) (executor.Session, error) {
+		cfg.Tools["echo"].Commands[0].Command = command
+		sessions, err := New(cfg, "https://example.com", "/tmp/out").Generate(nil, nil)
+		if err != nil {
+			return executor.Session{}, err
+		}
+		return sessions[0], nil
+	}
+
+	// -H flags would put the secret in the tool's arguments
+	for _, command := range []string{"curl {HEADERS-ALL} {URL}", "curl {HEADERS-CUSTOM} {URL}", "curl {HEADER-Cookie} {URL}"} {
+		if _, err := generate(command); err == nil || !strings.Contains(err.Error(), "{HEADERS-FILE}") {
+			t.Errorf("%q: got error %v, want a refusal pointing to {HEADERS-FILE}", command, err)
+		}
+	}
+
+	session, err := generate("curl {HEADERS-DEFAULT} {URL}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.HeaderLines != nil || session.Secrets != nil {
+		t.Errorf("a command without the secret header got header lines %q and secrets %v", session.HeaderLines, session.Secrets)
+	}
+
+	session, err = generate("curl -H @{HEADERS-FILE} {URL}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Command != `curl -H @"${TRIDENT_HEADERS_FILE}" https://example.com` {
+		t.Errorf("command = %q", session.Command)
+	}
+	wantLines := []string{"Accept: */*", "Cookie: sid=${TRIDENT_SECRET_SESSION}"}
+	if !reflect.DeepEqual(session.HeaderLines, wantLines) {
+		t.Errorf("header lines = %q, want %q", session.HeaderLines, wantLines)
+	}
+	if _, ok := session.Secrets["TRIDENT_SECRET_SESSION"]; !ok || len(session.Secrets) != 1 {
+		t.Errorf("secrets = %v, want the session secret", session.Secrets)
+	}
+
+	cfg.Headers.Custom = []string{"Cookie: {SECRET-missing}"}
+	if _, err := generate("curl -H @{HEADERS-FILE} {URL}"); err == nil || !strings.Contains(err.Error(), "undefined secret missing") {
+		t.Errorf("undefined secret: got error %v", err)
+	}
+}
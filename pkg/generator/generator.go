@@ -3,9 +3,12 @@ package generator
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/bc0d3/trident-recon/pkg/config"
 	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/secrets"
 	"github.com/bc0d3/trident-recon/pkg/utils"
 )
 
@@ -15,6 +18,8 @@ type Generator struct {
 	Target         string
 	OutputDir      string
 	DomainListFile string
+	UserVars       map[string]UserVar // Available as {VAR-name}
+	Budgets        executor.Budgets   // Rate budgets {RATE} and {THREADS} are split from
 }
 
 // New creates a new generator
@@ -31,6 +36,16 @@ func (g *Generator) SetDomainListFile(path string) {
 	g.DomainListFile = path
 }
 
+// SetUserVars sets the variables available as {VAR-name}
+func (g *Generator) SetUserVars(vars map[string]UserVar) {
+	g.UserVars = vars
+}
+
+// SetBudgets sets the rate budgets the target's sessions share
+func (g *Generator) SetBudgets(budgets executor.Budgets) {
+	g.Budgets = budgets
+}
+
 // Generate generates all commands for enabled tools
 func (g *Generator) Generate(toolsFilter, skipTools []string) ([]executor.Session, error) {
 	protocol, domain, err := utils.ParseURL(g.Target)
@@ -38,6 +53,7 @@ func (g *Generator) Generate(toolsFilter, skipTools []string) ([]executor.Sessio
 		return nil, fmt.Errorf("invalid URL: %w", err)
 	}
 
+	key := utils.TargetKey(domain)
 	domain = utils.SanitizeDomain(domain)
 	normalizedURL := utils.NormalizeURL(g.Target)
 
@@ -59,51 +75,106 @@ func (g *Generator) Generate(toolsFilter, skipTools []string) ([]executor.Sessio
 
 		// Generate commands for this tool
 		for _, cmdTemplate := range toolConfig.Commands {
-			session := g.generateSession(toolName, toolConfig, cmdTemplate, normalizedURL, protocol, domain)
+			// Domain list commands only make sense for multi-target scans
+			if cmdTemplate.UseDomainList && g.DomainListFile == "" {
+				continue
+			}
+
+			session, err := g.generateSession(toolName, toolConfig, cmdTemplate, normalizedURL, protocol, domain, key)
+			if err != nil {
+				return nil, fmt.Errorf("tool %s, command %s: %w", toolName, cmdTemplate.Name, err)
+			}
 			sessions = append(sessions, session)
 		}
 	}
 
+	// Split the budgets as if every session ran at once; the scheduler
+	// assigns the actual shares when sessions start
+	executor.PlanBudgets(sessions, g.Budgets)
+
 	return sessions, nil
 }
 
-func (g *Generator) generateSession(toolName string, toolConfig config.ToolConfig, cmdTemplate config.CommandTemplate, url, protocol, domain string) executor.Session {
-	// Generate unique ID
-	id := utils.GenerateID(toolName, cmdTemplate.Name, domain)
+func (g *Generator) generateSession(toolName string, toolConfig config.ToolConfig, cmdTemplate config.CommandTemplate, url, protocol, domain, key string) (executor.Session, error) {
+	// Generate unique ID (the key includes a non-default port)
+	id := utils.GenerateID(toolName, cmdTemplate.Name, key)
 
 	// Get wordlist path if specified
 	wordlist := ""
 	if cmdTemplate.Wordlist != "" {
-		if path, ok := g.Config.Wordlists[cmdTemplate.Wordlist]; ok {
-			wordlist = os.ExpandEnv(path)
+		path, ok := g.Config.Wordlists[cmdTemplate.Wordlist]
+		if !ok {
+			return executor.Session{}, fmt.Errorf("wordlist %q is not defined in wordlists", cmdTemplate.Wordlist)
 		}
+		wordlist = os.ExpandEnv(path)
 	}
 
-	// Build dynamic headers map from config
+	// Build dynamic headers map from config. Headers holding a secret can
+	// only be used through the headers file.
+	if err := checkHeaderSecrets(BuildHeaderLines(g.Config.Headers), g.Config.Secrets); err != nil {
+		return executor.Session{}, err
+	}
 	headersMap := BuildHeadersMap(g.Config.Headers)
 
+	// Budgeted rates and threads are filled in when the session starts
+	rate, threads := positiveInt(g.Config.Global.Rate), positiveInt(g.Config.Global.Threads)
+	rateBudget, threadsBudget := g.Budgets.Covers(url)
+	if rateBudget {
+		rate = executor.RateToken
+	}
+	if threadsBudget {
+		threads = executor.ThreadsToken
+	}
+
 	// Create replacements
 	replacements := Replacements{
-		URL:        url,
-		Domain:     domain,
-		Protocol:   protocol,
-		Wordlist:   wordlist,
-		OutputDir:  g.OutputDir,
-		ID:         id,
-		DomainList: g.DomainListFile,
-		Headers:    headersMap,
+		URL:         url,
+		Domain:      domain,
+		Protocol:    protocol,
+		Wordlist:    wordlist,
+		OutputDir:   g.OutputDir,
+		ID:          id,
+		DomainList:  g.DomainListFile,
+		Rate:        rate,
+		Threads:     threads,
+		Headers:     headersMap,
+		HeaderLines: withoutSecrets(BuildHeaderLines(g.Config.Headers)),
+		UserVars:    g.UserVars,
+		Secrets:     g.Config.Secrets,
 	}
 
-	// Replace template variables
-	command := ReplaceTemplateVars(cmdTemplate.Command, replacements)
+	// Render the command template. An argv template skips the shell; its
+	// command is kept only for display.
+	var command string
+	var args []string
+	var err error
+	if len(cmdTemplate.Args) > 0 {
+		args, err = ReplaceArgVars(cmdTemplate.Args, replacements)
+		if err != nil {
+			return executor.Session{}, err
+		}
+		command = utils.ShellJoin(args...)
+	} else {
+		command, err = ReplaceTemplateVars(cmdTemplate.Command, replacements)
+		if err != nil {
+			return executor.Session{}, err
+		}
+	}
 
 	// Generate tmux session name
 	tmuxSession := fmt.Sprintf("%s%s", toolConfig.TmuxPrefix, id)
 
 	// Determine output file from command if possible
-	outputFile := ""
-	if strings := findOutputFlag(command); strings != "" {
-		outputFile = strings
+	parts := args
+	if parts == nil {
+		parts = utils.ShellSplit(command)
+	}
+	outputFile := findOutputFlag(parts)
+
+	// The headers file is only written for commands that read it
+	var headerLines []string
+	if strings.Contains(command, "${"+secrets.HeadersFileEnv+"}") {
+		headerLines = headersFileLines(BuildHeaderLines(g.Config.Headers))
 	}
 
 	return executor.Session{
@@ -113,18 +184,32 @@ func (g *Generator) generateSession(toolName string, toolConfig config.ToolConfi
 		Target:      url,
 		TmuxSession: tmuxSession,
 		Command:     command,
+		Args:        args,
 		OutputDir:   g.OutputDir,
 		OutputFile:  outputFile,
 		Wordlist:    wordlist,
-		Status:      "pending",
+		Timeout:     cmdTemplate.Timeout,
+		Retries:     cmdTemplate.Retries,
+		Backoff:     cmdTemplate.Backoff,
+		Status:      executor.StatusPending,
+		HeaderLines: headerLines,
+		Secrets:     usedSecrets(command+"\n"+strings.Join(headerLines, "\n"), g.Config.Secrets),
+	}, nil
+}
+
+// positiveInt formats n, or returns "" for unset values so templates can
+// fall back with the default helper
+func positiveInt(n int) string {
+	if n <= 0 {
+		return ""
 	}
+	return strconv.Itoa(n)
 }
 
-// findOutputFlag tries to extract output file path from command
-func findOutputFlag(command string) string {
+// findOutputFlag tries to extract output file path from command arguments
+func findOutputFlag(parts []string) string {
 	// Simple extraction of output file paths
 	// Look for common patterns like -o file, --output file, > file
-	parts := splitCommand(command)
 	for i, part := range parts {
 		if (part == "-o" || part == "--output" || part == "-oJ") && i+1 < len(parts) {
 			return parts[i+1]
@@ -133,32 +218,6 @@ func findOutputFlag(command string) string {
 	return ""
 }
 
-// splitCommand splits a command string into parts (simple implementation)
-func splitCommand(cmd string) []string {
-	var parts []string
-	var current string
-	inQuote := false
-
-	for _, c := range cmd {
-		if c == '"' || c == '\'' {
-			inQuote = !inQuote
-		} else if c == ' ' && !inQuote {
-			if current != "" {
-				parts = append(parts, current)
-				current = ""
-			}
-		} else {
-			current += string(c)
-		}
-	}
-
-	if current != "" {
-		parts = append(parts, current)
-	}
-
-	return parts
-}
-
 // contains checks if a slice contains a string
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
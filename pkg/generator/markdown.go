@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/utils"
 )
 
 // MarkdownGenerator generates markdown documentation
@@ -13,6 +14,7 @@ type MarkdownGenerator struct {
 	Target    string
 	OutputDir string
 	Sessions  []executor.Session
+	Vars      map[string]UserVar // User variables used to render the commands
 }
 
 // Generate generates the markdown content
@@ -29,6 +31,12 @@ func (mg *MarkdownGenerator) Generate() string {
 	// Quick Reference Table
 	mg.generateQuickReference(&md)
 
+	// User variables
+	mg.generateVariables(&md)
+
+	// Secrets the commands read from the environment
+	mg.generateSecrets(&md)
+
 	// Group sessions by tool
 	toolSessions := mg.groupByTool()
 
@@ -59,6 +67,68 @@ func (mg *MarkdownGenerator) generateQuickReference(md *strings.Builder) {
 	md.WriteString("\n---\n\n")
 }
 
+func (mg *MarkdownGenerator) generateVariables(md *strings.Builder) {
+	if len(mg.Vars) == 0 {
+		return
+	}
+
+	md.WriteString("## 🧩 Variables\n\n")
+	md.WriteString(fmt.Sprintf("Precedence, lowest first: %s\n\n", VarPrecedence))
+	md.WriteString("| Variable | Value | Source |\n")
+	md.WriteString("|----------|-------|--------|\n")
+	for _, name := range userVarNames(mg.Vars) {
+		v := mg.Vars[name]
+		// A variable may hold a secret value; keep the table intact too
+		value := strings.ReplaceAll(codeSpan(utils.Redact(v.Value)), "|", `\|`)
+		md.WriteString(fmt.Sprintf("| `{%s%s}` | %s | %s |\n", UserVarPrefix, name, value, v.Source))
+	}
+	md.WriteString("\n---\n\n")
+}
+
+// codeSpan renders s as inline code. The fence is one backtick longer than
+// the longest run of backticks in s, and padded with spaces when s starts
+// or ends with one.
+func codeSpan(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r != '`' {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+
+	fence := strings.Repeat("`", longest+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + s + fence
+}
+
+func (mg *MarkdownGenerator) generateSecrets(md *strings.Builder) {
+	used := make(map[string]string)
+	for _, s := range mg.Sessions {
+		for name, ref := range s.Secrets {
+			used[name] = ref.String()
+		}
+	}
+	if len(used) == 0 {
+		return
+	}
+
+	md.WriteString("## 🔐 Secrets\n\n")
+	md.WriteString("Some commands read secrets from environment variables; their values are not written to any file.\n")
+	md.WriteString("`trident-recon run` passes them to each session without putting them on the command line.\n")
+	md.WriteString("To run a command by hand, export the variables in your shell first.\n\n")
+	md.WriteString("| Variable | Source |\n")
+	md.WriteString("|----------|--------|\n")
+	for _, name := range sortedKeys(used) {
+		md.WriteString(fmt.Sprintf("| `%s` | %s |\n", name, used[name]))
+	}
+	md.WriteString("\n---\n\n")
+}
+
 func (mg *MarkdownGenerator) groupByTool() map[string][]executor.Session {
 	groups := make(map[string][]executor.Session)
 	for _, s := range mg.Sessions {
@@ -81,12 +151,12 @@ func (mg *MarkdownGenerator) generateToolSection(md *strings.Builder, tool strin
 
 		md.WriteString("```bash\n")
 		md.WriteString("# Start session\n")
-		md.WriteString(fmt.Sprintf("tmux new-session -d -s \"%s\" bash -c \"%s\"\n\n", s.TmuxSession, escapeCommand(s.Command)))
+		md.WriteString(tmuxCommandLine(s) + "\n\n")
 		md.WriteString("# Attach to session\n")
-		md.WriteString(fmt.Sprintf("tmux attach -t \"%s\"\n\n", s.TmuxSession))
+		md.WriteString(utils.ShellJoin("tmux", "attach", "-t", s.TmuxSession) + "\n\n")
 		if s.OutputFile != "" {
 			md.WriteString("# View output\n")
-			md.WriteString(fmt.Sprintf("cat %s\n", s.OutputFile))
+			md.WriteString(utils.ShellJoin("cat", s.OutputFile) + "\n")
 		}
 		md.WriteString("```\n\n")
 		md.WriteString("---\n\n")
@@ -165,9 +235,14 @@ func (mg *MarkdownGenerator) generateOutputStructure(md *strings.Builder) {
 
 func (mg *MarkdownGenerator) generateResultsAnalysis(md *strings.Builder) {
 	md.WriteString("## 📊 Results Analysis\n\n")
+	md.WriteString("### Parse outputs into findings\n\n")
+	md.WriteString("```bash\n")
+	md.WriteString("# Parse ffuf, gobuster, dirsearch and feroxbuster outputs of finished sessions\n")
+	md.WriteString("trident-recon ingest\n")
+	md.WriteString("```\n\n")
 	md.WriteString("### View all JSON results with jq\n\n")
 	md.WriteString("```bash\n")
-	md.WriteString(fmt.Sprintf("cd %s\n\n", mg.OutputDir))
+	md.WriteString(utils.ShellJoin("cd", mg.OutputDir) + "\n\n")
 	md.WriteString("# View ffuf results\n")
 	md.WriteString("for file in ffuf-*.json; do\n")
 	md.WriteString("    echo \"=== $file ===\"\n")
@@ -192,11 +267,6 @@ func (mg *MarkdownGenerator) generateResultsAnalysis(md *strings.Builder) {
 	md.WriteString("5. Kill sessions when done with `trident-recon kill-all`\n\n")
 }
 
-func escapeCommand(cmd string) string {
-	// Escape double quotes for bash -c
-	return strings.ReplaceAll(cmd, `"`, `\"`)
-}
-
 func extractFileName(path string) string {
 	parts := strings.Split(path, "/")
 	if len(parts) > 0 {
@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+func TestResolveUserVarsPrecedence(t *testing.T) {
+	config := VarLayer{Source: VarSourceConfig, Vars: map[string]string{"a": "config", "b": "config", "c": "config", "d": "config"}}
+	profile := VarLayer{Source: VarSourceProfile, Vars: map[string]string{"b": "profile", "c": "profile", "d": "profile"}}
+	target := VarLayer{Source: VarSourceTarget, Vars: map[string]string{"c": "target", "d": "target"}}
+	cli := VarLayer{Source: VarSourceCLI, Vars: map[string]string{"d": "cli", "e": "cli"}}
+
+	tests := []struct {
+		name   string
+		layers []VarLayer
+		want   map[string]UserVar
+	}{
+		{
+			name:   "config only",
+			layers: []VarLayer{config},
+			want: map[string]UserVar{
+				"a": {"config", VarSourceConfig}, "b": {"config", VarSourceConfig},
+				"c": {"config", VarSourceConfig}, "d": {"config", VarSourceConfig},
+			},
+		},
+		{
+			name:   "every layer",
+			layers: []VarLayer{config, profile, target, cli},
+			want: map[string]UserVar{
+				"a": {"config", VarSourceConfig},
+				"b": {"profile", VarSourceProfile},
+				"c": {"target", VarSourceTarget},
+				"d": {"cli", VarSourceCLI},
+				"e": {"cli", VarSourceCLI},
+			},
+		},
+		{
+			name:   "no profile",
+			layers: []VarLayer{config, target, cli},
+			want: map[string]UserVar{
+				"a": {"config", VarSourceConfig},
+				"b": {"config", VarSourceConfig},
+				"c": {"target", VarSourceTarget},
+				"d": {"cli", VarSourceCLI},
+				"e": {"cli", VarSourceCLI},
+			},
+		},
+		{
+			name:   "empty value still overrides",
+			layers: []VarLayer{config, {Source: VarSourceCLI, Vars: map[string]string{"a": ""}}},
+			want: map[string]UserVar{
+				"a": {"", VarSourceCLI}, "b": {"config", VarSourceConfig},
+				"c": {"config", VarSourceConfig}, "d": {"config", VarSourceConfig},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveUserVars(tt.layers...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveUserVars() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	bad := VarLayer{Source: VarSourceTarget, Vars: map[string]string{"bad name": "x"}}
+	if _, err := ResolveUserVars(config, bad); err == nil || !strings.HasPrefix(err.Error(), VarSourceTarget) {
+		t.Errorf("invalid name: got error %v, want one naming the %s layer", err, VarSourceTarget)
+	}
+}
+
+func TestCodeSpan(t *testing.T) {
+	for s, want := range map[string]string{
+		"plain":        "`plain`",
+		"a`b":          "``a`b``",
+		"a``b`c":       "```a``b`c```",
+		"`start":       "`` `start ``",
+		"end`":         "`` end` ``",
+		"$(id) && `x`": "`` $(id) && `x` ``",
+	} {
+		if got := codeSpan(s); got != want {
+			t.Errorf("codeSpan(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestMarkdownVariables(t *testing.T) {
+	utils.AddRedaction("tok-3f9a1c")
+
+	mg := MarkdownGenerator{
+		Target: "https://example.com",
+		Vars: map[string]UserVar{
+			"auth":  {Value: "Bearer tok-3f9a1c", Source: VarSourceCLI},
+			"path":  {Value: "/a|b", Source: VarSourceTarget},
+			"shell": {Value: "`id`", Source: VarSourceProfile},
+		},
+	}
+
+	var md strings.Builder
+	mg.generateVariables(&md)
+	out := md.String()
+
+	if strings.Contains(out, "tok-3f9a1c") {
+		t.Errorf("secret value written to the variables table:\n%s", out)
+	}
+	for _, want := range []string{
+		"| `{VAR-auth}` | `Bearer " + utils.Redacted + "` | --var |",
+		"| `{VAR-path}` | `/a\\|b` | target line |",
+		"| `{VAR-shell}` | `` `id` `` | profile |",
+		"Precedence, lowest first: " + VarPrecedence,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("variables table is missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bc0d3/trident-recon/pkg/secrets"
+)
+
+// SecretPrefix starts the placeholders of secrets, e.g. {SECRET-session_cookie}
+const SecretPrefix = "SECRET-"
+
+// secretTag matches {SECRET-name} inside header values
+var secretTag = regexp.MustCompile(`\{SECRET-([A-Za-z0-9_.-]+)\}`)
+
+// HeadersFileVar is the placeholder of the headers file, see
+// secrets.HeadersFileEnv
+const HeadersFileVar = "HEADERS-FILE"
+
+// checkHeaderSecrets fails when a header uses a secret that is not defined
+func checkHeaderSecrets(lines []string, defined map[string]secrets.Ref) error {
+	for _, line := range lines {
+		for _, tag := range secretTag.FindAllStringSubmatch(line, -1) {
+			if _, ok := defined[tag[1]]; !ok {
+				return fmt.Errorf("header uses undefined secret %s", tag[1])
+			}
+		}
+	}
+	return nil
+}
+
+// headerSecretError returns why header flags must not be rendered, or nil.
+// A -H flag puts the header in the tool's arguments, where any local user
+// can read it with ps, so headers holding a secret are only delivered
+// through the headers file.
+func headerSecretError(flags string) error {
+	tag := secretTag.FindStringSubmatch(flags)
+	if tag == nil {
+		return nil
+	}
+	return fmt.Errorf("a header holds secret %s, which -H flags would put in the tool's arguments where ps shows it; "+
+		"pass it with {%s} to a tool that reads headers from a file (e.g. dirsearch --headers-file, curl -H @file)", tag[1], HeadersFileVar)
+}
+
+// headersFileLines returns the header lines for the headers file, with
+// secrets as references the executor fills in when the session starts
+func headersFileLines(lines []string) []string {
+	refs := make([]string, len(lines))
+	for i, line := range lines {
+		refs[i] = secretTag.ReplaceAllStringFunc(line, func(tag string) string {
+			return secrets.Placeholder(secretTag.FindStringSubmatch(tag)[1])
+		})
+	}
+	return refs
+}
+
+// withoutSecrets drops header lines that use a secret. {HEADERS} values are
+// quoted as plain data, where a secret reference would not be expanded.
+func withoutSecrets(lines []string) []string {
+	var kept []string
+	for _, line := range lines {
+		if !secretTag.MatchString(line) {
+			kept = append(kept, line)
+		}
+	}
+	return kept
+}
+
+// usedSecrets returns the secrets a rendered command or its headers file
+// refers to, keyed by the environment variable they are delivered in
+func usedSecrets(command string, defined map[string]secrets.Ref) map[string]secrets.Ref {
+	var used map[string]secrets.Ref
+	for name, ref := range defined {
+		if !strings.Contains(command, secrets.Placeholder(name)) {
+			continue
+		}
+		if used == nil {
+			used = make(map[string]secrets.Ref)
+		}
+		used[secrets.EnvName(name)] = ref
+	}
+	return used
+}
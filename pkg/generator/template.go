@@ -2,67 +2,123 @@ package generator
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/secrets"
+	"github.com/bc0d3/trident-recon/pkg/utils"
 )
 
 // Replacements holds template variable replacements
 type Replacements struct {
-	URL        string
-	Domain     string
-	Protocol   string
-	Wordlist   string
-	OutputDir  string
-	ID         string
-	DomainList string
-	Headers    map[string]string // Dynamic header replacements
+	URL         string
+	Domain      string
+	Protocol    string
+	Wordlist    string
+	OutputDir   string
+	ID          string
+	DomainList  string
+	Rate        string
+	Threads     string
+	Headers     map[string]string // Dynamic header variables, see BuildHeadersMap
+	HeaderLines []string          // Every header as "Name: value"
+	UserVars    map[string]UserVar
+	Secrets     map[string]secrets.Ref // Rendered as references to their environment variables
 }
 
-// ReplaceTemplateVars replaces template variables in a command string
-func ReplaceTemplateVars(template string, rep Replacements) string {
-	result := template
-
-	// Replace basic variables
-	result = strings.ReplaceAll(result, "{URL}", rep.URL)
-	result = strings.ReplaceAll(result, "{DOMAIN}", rep.Domain)
-	result = strings.ReplaceAll(result, "{PROTOCOL}", rep.Protocol)
-	result = strings.ReplaceAll(result, "{WORDLIST}", rep.Wordlist)
-	result = strings.ReplaceAll(result, "{OUTPUT_DIR}", rep.OutputDir)
-	result = strings.ReplaceAll(result, "{ID}", rep.ID)
-	result = strings.ReplaceAll(result, "{DOMAIN_LIST}", rep.DomainList)
-
-	// Replace all header variables dynamically
-	// This supports {HEADER-User-Agent}, {HEADER-X-Bug-Bounty}, {HEADERS-ALL}, etc.
-	for placeholder, value := range rep.Headers {
-		result = strings.ReplaceAll(result, placeholder, value)
+// Vars returns the template variables for the replacements. Header groups
+// may be empty, every other variable must have a value when it is used.
+func (rep Replacements) Vars() Vars {
+	vars := Vars{
+		"URL":         {Value: rep.URL},
+		"DOMAIN":      {Value: rep.Domain},
+		"PROTOCOL":    {Value: rep.Protocol},
+		"WORDLIST":    {Value: rep.Wordlist},
+		"OUTPUT_DIR":  {Value: rep.OutputDir},
+		"ID":          {Value: rep.ID},
+		"DOMAIN_LIST": {Value: rep.DomainList},
+		"RATE":        {Value: rep.Rate},
+		"THREADS":     {Value: rep.Threads},
+		"HEADERS":     {List: rep.HeaderLines, Optional: true},
 	}
 
-	return result
+	// Dynamic header variables: HEADER-User-Agent, HEADERS-ALL, etc. They
+	// are ready-made -H flags, so they are not quoted again. Those holding
+	// a secret are refused.
+	for name, value := range rep.Headers {
+		vars[name] = Var{Value: value, Optional: strings.HasPrefix(name, "HEADERS-"), Raw: true, Err: headerSecretError(value)}
+	}
+
+	// HEADERS-FILE → "${TRIDENT_HEADERS_FILE}", every header with secrets
+	// filled in, in a file written when the session starts
+	vars[HeadersFileVar] = Var{Env: secrets.HeadersFileEnv}
+
+	// User variables: VAR-username, VAR-cookie, etc.
+	for name, v := range rep.UserVars {
+		vars[UserVarPrefix+name] = Var{Value: v.Value}
+	}
+
+	// Secrets: SECRET-session_cookie → "${TRIDENT_SECRET_SESSION_COOKIE}"
+	for name := range rep.Secrets {
+		vars[SecretPrefix+name] = Var{Env: secrets.EnvName(name)}
+	}
+
+	return vars
+}
+
+// ReplaceTemplateVars renders a command template. It fails when the template
+// is malformed or uses an unknown or empty placeholder.
+func ReplaceTemplateVars(template string, rep Replacements) (string, error) {
+	tmpl, err := ParseTemplate(template)
+	if err != nil {
+		return "", err
+	}
+	return tmpl.Execute(rep.Vars())
+}
+
+// ReplaceArgVars renders an argv template one element at a time. Values are
+// inserted as they are since no shell is involved.
+func ReplaceArgVars(args []string, rep Replacements) ([]string, error) {
+	vars := rep.Vars()
+	rendered := make([]string, len(args))
+	for i, arg := range args {
+		tmpl, err := ParseArgTemplate(arg)
+		if err != nil {
+			return nil, fmt.Errorf("args[%d]: %w", i, err)
+		}
+		if rendered[i], err = tmpl.Execute(vars); err != nil {
+			return nil, fmt.Errorf("args[%d]: %w", i, err)
+		}
+	}
+	return rendered, nil
 }
 
 // BuildHeadersMap creates a map of ALL possible header replacement variables
 // This function dynamically creates placeholders for ANY header in the config
 //
 // Examples:
-//   {HEADER-User-Agent} → -H "User-Agent: Mozilla/5.0..."
-//   {HEADER-Accept} → -H "Accept: */*"
-//   {HEADER-X-Bug-Bounty} → -H "X-Bug-Bounty: hackeroneUser"
-//   {HEADERS-ALL} → all headers combined
-//   {HEADERS-DEFAULT} → only default headers
-//   {HEADERS-CUSTOM} → only custom headers
+//
+//	HEADER-User-Agent → -H "User-Agent: Mozilla/5.0..."
+//	HEADER-Accept → -H "Accept: */*"
+//	HEADER-X-Bug-Bounty → -H "X-Bug-Bounty: hackeroneUser"
+//	HEADERS-ALL → all headers combined
+//	HEADERS-DEFAULT → only default headers
+//	HEADERS-CUSTOM → only custom headers
+//
+// Header values are escaped for the double quotes around them.
 func BuildHeadersMap(headers config.HeadersConfig) map[string]string {
 	result := make(map[string]string)
 
 	var defaultParts []string
 	var customParts []string
 
-	// Process ALL default headers dynamically
-	for key, value := range headers.Default {
-		formatted := fmt.Sprintf(`-H "%s: %s"`, key, value)
+	// Process ALL default headers dynamically, in a stable order
+	for _, key := range sortedKeys(headers.Default) {
+		formatted := headerFlag(fmt.Sprintf("%s: %s", key, headers.Default[key]))
 
-		// Create placeholder: {HEADER-User-Agent}, {HEADER-Accept}, etc.
-		placeholder := fmt.Sprintf("{HEADER-%s}", key)
+		// Create placeholder: HEADER-User-Agent, HEADER-Accept, etc.
+		placeholder := fmt.Sprintf("HEADER-%s", key)
 		result[placeholder] = formatted
 
 		defaultParts = append(defaultParts, formatted)
@@ -75,23 +131,47 @@ func BuildHeadersMap(headers config.HeadersConfig) map[string]string {
 		parts := strings.SplitN(header, ":", 2)
 		headerKey := strings.TrimSpace(parts[0])
 
-		formatted := fmt.Sprintf(`-H "%s"`, header)
+		formatted := headerFlag(header)
 
-		// Create placeholder: {HEADER-X-Bug-Bounty}, {HEADER-X-Custom}, etc.
-		placeholder := fmt.Sprintf("{HEADER-%s}", headerKey)
+		// Create placeholder: HEADER-X-Bug-Bounty, HEADER-X-Custom, etc.
+		placeholder := fmt.Sprintf("HEADER-%s", headerKey)
 		result[placeholder] = formatted
 
 		customParts = append(customParts, formatted)
 	}
 
 	// Build grouped placeholders
-	result["{HEADERS-DEFAULT}"] = strings.Join(defaultParts, " ")
-	result["{HEADERS-CUSTOM}"] = strings.Join(customParts, " ")
+	result["HEADERS-DEFAULT"] = strings.Join(defaultParts, " ")
+	result["HEADERS-CUSTOM"] = strings.Join(customParts, " ")
 
 	// Combine all headers (default + custom)
 	allParts := append([]string{}, defaultParts...)
 	allParts = append(allParts, customParts...)
-	result["{HEADERS-ALL}"] = strings.Join(allParts, " ")
+	result["HEADERS-ALL"] = strings.Join(allParts, " ")
 
 	return result
 }
+
+// headerFlag formats a header as a -H flag for the shell
+func headerFlag(header string) string {
+	return `-H "` + utils.ShellEscapeDouble(header) + `"`
+}
+
+// BuildHeaderLines returns every configured header as "Name: value"
+func BuildHeaderLines(headers config.HeadersConfig) []string {
+	var lines []string
+	for _, key := range sortedKeys(headers.Default) {
+		lines = append(lines, fmt.Sprintf("%s: %s", key, headers.Default[key]))
+	}
+	lines = append(lines, headers.Custom...)
+	return lines
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
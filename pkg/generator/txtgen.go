@@ -1,10 +1,11 @@
 package generator
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/tmux"
+	"github.com/bc0d3/trident-recon/pkg/utils"
 )
 
 // PlainTextGenerator generates plain text commands (copy-paste ready)
@@ -18,19 +19,15 @@ func (tg *PlainTextGenerator) Generate() string {
 
 	for _, s := range tg.Sessions {
 		// Just write the raw tmux command that can be copy-pasted
-		txt.WriteString(fmt.Sprintf("tmux new-session -d -s \"%s\" bash -c \"%s\"\n",
-			s.TmuxSession,
-			escapeForBash(s.Command)))
+		txt.WriteString(tmuxCommandLine(s))
+		txt.WriteString("\n")
 	}
 
 	return txt.String()
 }
 
-// escapeForBash escapes special characters for bash -c execution
-func escapeForBash(cmd string) string {
-	// Escape double quotes and backslashes for bash -c
-	cmd = strings.ReplaceAll(cmd, `\`, `\\`)
-	cmd = strings.ReplaceAll(cmd, `"`, `\"`)
-	cmd = strings.ReplaceAll(cmd, `$`, `\$`)
-	return cmd
+// tmuxCommandLine returns the shell command that starts a session in tmux,
+// quoted the same way the executor passes it
+func tmuxCommandLine(s executor.Session) string {
+	return utils.ShellJoin(append([]string{"tmux"}, tmux.NewSessionArgs(s.TmuxSession, s.Argv())...)...)
 }
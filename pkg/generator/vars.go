@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Sources of user variables, lowest precedence first
+const (
+	VarSourceConfig  = "config"
+	VarSourceProfile = "profile"
+	VarSourceTarget  = "target line"
+	VarSourceCLI     = "--var"
+)
+
+// VarPrecedence describes how user variables override each other
+const VarPrecedence = "config `vars:` < profile `vars:` < target line `vars:` < `--var`"
+
+// userVarName matches the name part of {VAR-name}
+var userVarName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// UserVar is a user-defined variable and the layer that set it
+type UserVar struct {
+	Value  string
+	Source string
+}
+
+// VarLayer is one set of user variables
+type VarLayer struct {
+	Source string
+	Vars   map[string]string
+}
+
+// ValidateVarName checks that name can be used as {VAR-name}
+func ValidateVarName(name string) error {
+	if !userVarName.MatchString(name) {
+		return fmt.Errorf("invalid variable name %q (use letters, digits, '_', '.' and '-')", name)
+	}
+	return nil
+}
+
+// ResolveUserVars merges layers into the variables available as {VAR-name}.
+// Layers are given lowest precedence first, so later layers win.
+func ResolveUserVars(layers ...VarLayer) (map[string]UserVar, error) {
+	resolved := make(map[string]UserVar)
+	for _, layer := range layers {
+		for name, value := range layer.Vars {
+			if err := ValidateVarName(name); err != nil {
+				return nil, fmt.Errorf("%s: %w", layer.Source, err)
+			}
+			resolved[name] = UserVar{Value: value, Source: layer.Source}
+		}
+	}
+	return resolved, nil
+}
+
+// userVarNames returns the names of vars, sorted
+func userVarNames(vars map[string]UserVar) []string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,169 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/scope"
+	"github.com/bc0d3/trident-recon/pkg/secrets"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// levels ranks finding levels for min_level
+var levels = map[string]int{"note": 0, "warning": 1, "error": 2}
+
+// defaultMinLevel keeps notes out of finding notifications unless a
+// channel asks for them
+const defaultMinLevel = "warning"
+
+// channel is a configured notification channel
+type channel struct {
+	name    string
+	config  config.ChannelConfig
+	scope   *scope.Scope
+	title   *template.Template
+	body    *template.Template
+	secrets map[string]secrets.Ref
+}
+
+func newChannel(name string, cfg config.ChannelConfig, refs map[string]secrets.Ref) (*channel, error) {
+	targets, err := scope.New(cfg.Targets, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := &channel{name: name, config: cfg, scope: targets, secrets: refs}
+	if cfg.Title != "" {
+		if ch.title, err = template.New("title").Parse(cfg.Title); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Body != "" {
+		if ch.body, err = template.New("body").Parse(cfg.Body); err != nil {
+			return nil, err
+		}
+	}
+	return ch, nil
+}
+
+// notify sends an event if it passes the channel's filters and reports
+// whether it did
+func (ch *channel) notify(event Event) (bool, error) {
+	event, ok := ch.filter(event)
+	if !ok {
+		return false, nil
+	}
+	return true, ch.send(describe(event))
+}
+
+// filter applies the channel's filters. Finding events keep only the
+// findings that pass; run events are not filtered by tool.
+func (ch *channel) filter(event Event) (Event, bool) {
+	if len(ch.config.Events) > 0 && !containsString(ch.config.Events, event.Type) {
+		return event, false
+	}
+
+	if event.Type == EventNewFindings {
+		minLevel := ch.config.MinLevel
+		if minLevel == "" {
+			minLevel = defaultMinLevel
+		}
+
+		var kept []Finding
+		for _, f := range event.Findings {
+			if levels[f.Level] >= levels[minLevel] && ch.matchTool(f.Tool) && ch.matchTarget(f.Target) {
+				kept = append(kept, f)
+			}
+		}
+		event.Findings = kept
+		return event, len(kept) > 0
+	}
+
+	if event.Session != nil {
+		return event, ch.matchTool(event.Session.Tool) && ch.matchTarget(event.Session.Target)
+	}
+
+	if event.Run != nil {
+		for _, target := range event.Run.Targets {
+			if ch.matchTarget(target) {
+				return event, true
+			}
+		}
+		return event, len(event.Run.Targets) == 0
+	}
+
+	return event, true
+}
+
+func (ch *channel) matchTool(tool string) bool {
+	return len(ch.config.Tools) == 0 || containsString(ch.config.Tools, tool)
+}
+
+func (ch *channel) matchTarget(target string) bool {
+	if ch.scope.IsEmpty() {
+		return true
+	}
+	ok, _ := ch.scope.Check(target)
+	return ok
+}
+
+// send renders an event and delivers it
+func (ch *channel) send(event Event) error {
+	title, err := render(ch.title, event, event.Title)
+	if err != nil {
+		return fmt.Errorf("title template: %w", err)
+	}
+	body, err := render(ch.body, event, event.Text)
+	if err != nil {
+		return fmt.Errorf("body template: %w", err)
+	}
+	msg := message{
+		event: event,
+		title: utils.Redact(strings.TrimSpace(title)),
+		body:  utils.Redact(strings.TrimSpace(body)),
+	}
+
+	switch ch.config.Type {
+	case config.ChannelEmail:
+		return ch.sendEmail(msg)
+	default:
+		return ch.sendWebhook(msg)
+	}
+}
+
+// message is a rendered notification
+type message struct {
+	event Event
+	title string
+	body  string
+}
+
+// render executes a template, or returns the default text when there is
+// none
+func render(tmpl *template.Template, event Event, fallback string) (string, error) {
+	if tmpl == nil {
+		return fallback, nil
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// secret reads a secret named in the channel config
+func (ch *channel) secret(name string) (string, error) {
+	ref, ok := ch.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("unknown secret %q", name)
+	}
+	value, err := ref.Resolve()
+	if err != nil {
+		return "", fmt.Errorf("secret %s (%s): %w", name, ref, err)
+	}
+	utils.AddRedaction(value)
+	return value, nil
+}
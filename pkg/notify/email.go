@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSMTPPort is the mail submission port, upgraded with STARTTLS
+const defaultSMTPPort = 587
+
+// sendEmail sends a message through the channel's SMTP server. The
+// connection is upgraded with STARTTLS when the server offers it; password
+// authentication is only used over TLS or to localhost.
+func (ch *channel) sendEmail(msg message) error {
+	cfg := ch.config.SMTP
+
+	port := cfg.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		password := ""
+		if cfg.PasswordSecret != "" {
+			var err error
+			if password, err = ch.secret(cfg.PasswordSecret); err != nil {
+				return err
+			}
+		}
+		auth = smtp.PlainAuth("", cfg.Username, password, cfg.Host)
+	}
+
+	// Keep line breaks out of headers
+	subject := strings.Join(strings.Fields(msg.title), " ")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+
+	return smtp.SendMail(addr, auth, cfg.From, cfg.To, []byte(b.String()))
+}
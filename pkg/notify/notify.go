@@ -0,0 +1,295 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/results"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// EventNewFindings is sent when parsing a session found URLs that were not
+// known for its target
+const EventNewFindings = "finding.new"
+
+// maxAttempts is how many commands try to deliver an event before it is
+// dropped
+const maxAttempts = 3
+
+// maxFindings caps the findings listed in the default message body
+const maxFindings = 20
+
+// Event is what a notification is about. Title and Text hold the default
+// message; templates can use them or any other field, e.g.
+// {{.Session.Tool}} or {{range .Findings}}{{.Level}} {{.URL}}{{end}}.
+type Event struct {
+	Type     string
+	Time     time.Time
+	Title    string
+	Text     string
+	Session  *executor.Session
+	Run      *executor.Run
+	Findings []Finding
+}
+
+// Finding is a new finding with the rule that classified it
+type Finding struct {
+	results.Finding
+	Rule  string `json:"rule"`  // ID of the matching rule
+	Level string `json:"level"` // note, warning or error
+}
+
+// Notifier sends events to the configured channels
+type Notifier struct {
+	channels []*channel
+}
+
+// New creates a notifier for the channels in the config
+func New(cfg *config.Config) (*Notifier, error) {
+	names := make([]string, 0, len(cfg.Notifications.Channels))
+	for name := range cfg.Notifications.Channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	n := &Notifier{}
+	for _, name := range names {
+		ch, err := newChannel(name, cfg.Notifications.Channels[name], cfg.Secrets)
+		if err != nil {
+			return nil, fmt.Errorf("notifications.channels.%s: %w", name, err)
+		}
+		n.channels = append(n.channels, ch)
+	}
+	return n, nil
+}
+
+// Enabled reports whether any channel is configured
+func (n *Notifier) Enabled() bool {
+	return len(n.channels) > 0
+}
+
+// Flush delivers the session and run events recorded in the state
+// directory and returns how many notifications were sent. An event a
+// channel failed to take is kept and retried by later commands, only for
+// the channels that failed.
+func (n *Notifier) Flush(stateDir string) (int, error) {
+	lock, err := executor.LockEvents(stateDir)
+	if err != nil {
+		return 0, err
+	}
+	defer lock.Unlock()
+
+	records, err := executor.LoadEvents(stateDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load events: %w", err)
+	}
+
+	sent := 0
+	for _, record := range records {
+		event := fromRecord(record)
+
+		failed := false
+		for _, ch := range n.channels {
+			if containsString(record.Delivered, ch.name) {
+				continue
+			}
+			ok, err := ch.notify(event)
+			if err != nil {
+				utils.PrintWarning(fmt.Sprintf("Failed to send %s notification to %s: %v", event.Type, ch.name, err))
+				failed = true
+				continue
+			}
+			record.Delivered = append(record.Delivered, ch.name)
+			if ok {
+				sent++
+			}
+		}
+
+		record.Attempts++
+		if failed && record.Attempts < maxAttempts {
+			if err := record.Save(stateDir); err != nil {
+				return sent, fmt.Errorf("failed to save event %s: %w", record.ID, err)
+			}
+			continue
+		}
+		if err := executor.DeleteEvent(stateDir, record.ID); err != nil {
+			return sent, fmt.Errorf("failed to delete event %s: %w", record.ID, err)
+		}
+	}
+
+	return sent, nil
+}
+
+// NotifyFindings sends the new findings of a session and returns how many
+// notifications were sent. Failures are reported and not retried.
+func (n *Notifier) NotifyFindings(session executor.Session, findings []results.Finding) int {
+	if len(findings) == 0 {
+		return 0
+	}
+
+	event := Event{
+		Type:    EventNewFindings,
+		Time:    time.Now(),
+		Session: &session,
+	}
+	for _, f := range findings {
+		rule := results.Classify(f)
+		event.Findings = append(event.Findings, Finding{Finding: f, Rule: rule.ID, Level: rule.Level})
+	}
+
+	sent := 0
+	for _, ch := range n.channels {
+		ok, err := ch.notify(event)
+		if err != nil {
+			utils.PrintWarning(fmt.Sprintf("Failed to send %s notification to %s: %v", event.Type, ch.name, err))
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent
+}
+
+// Test sends a test message to a channel, ignoring its filters
+func (n *Notifier) Test(name string) error {
+	for _, ch := range n.channels {
+		if ch.name != name {
+			continue
+		}
+		event := Event{
+			Type:  "test",
+			Time:  time.Now(),
+			Title: "trident-recon test notification",
+			Text:  fmt.Sprintf("Notifications for channel %s are working.", name),
+		}
+		return ch.send(event)
+	}
+	return fmt.Errorf("no channel named %q", name)
+}
+
+// Channels returns the names of the configured channels
+func (n *Notifier) Channels() []string {
+	names := make([]string, 0, len(n.channels))
+	for _, ch := range n.channels {
+		names = append(names, ch.name)
+	}
+	return names
+}
+
+// fromRecord turns a recorded session or run event into a notification
+func fromRecord(record executor.Event) Event {
+	return Event{
+		Type:    record.Type,
+		Time:    record.Time,
+		Session: record.Session,
+		Run:     record.Run,
+	}
+}
+
+// describe fills in the default title and text of an event
+func describe(event Event) Event {
+	switch {
+	case event.Type == EventNewFindings:
+		event.Title, event.Text = describeFindings(event)
+	case event.Session != nil:
+		event.Title, event.Text = describeSession(*event.Session)
+	case event.Run != nil:
+		event.Title, event.Text = describeRun(*event.Run)
+	}
+	return event
+}
+
+func describeSession(s executor.Session) (string, string) {
+	title := fmt.Sprintf("%s - %s %s on %s", s.Tool, s.CommandName, s.Status, s.Target)
+
+	status := string(s.Status)
+	if s.ExitCode != nil {
+		status += fmt.Sprintf(" (exit %d)", *s.ExitCode)
+	}
+	if s.Duration > 0 {
+		status += " after " + s.Duration.Round(time.Second).String()
+	}
+
+	lines := []string{
+		"Session: " + s.ID,
+		"Target:  " + s.Target,
+		"Status:  " + status,
+	}
+	if s.RunID != "" {
+		lines = append(lines, "Run:     "+s.RunID)
+	}
+	if s.Attempts > 1 {
+		lines = append(lines, fmt.Sprintf("Attempt: %d", s.Attempts))
+	}
+	if s.OutputFile != "" {
+		lines = append(lines, "Output:  "+s.OutputFile)
+	}
+	if s.Error != "" {
+		lines = append(lines, "Error:   "+s.Error)
+	}
+	return title, strings.Join(lines, "\n")
+}
+
+func describeRun(r executor.Run) (string, string) {
+	title := fmt.Sprintf("Run %s finished", r.ID)
+
+	var counts []string
+	for _, status := range []executor.Status{
+		executor.StatusSucceeded, executor.StatusFailed, executor.StatusTimedOut,
+		executor.StatusKilled, executor.StatusLost,
+	} {
+		if n := r.Counts[status]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, status))
+		}
+	}
+
+	targets := strings.Join(r.Targets, ", ")
+	if len(r.Targets) > 3 {
+		targets = fmt.Sprintf("%s and %d more", strings.Join(r.Targets[:3], ", "), len(r.Targets)-3)
+	}
+
+	lines := []string{
+		fmt.Sprintf("Sessions: %d (%s)", r.Sessions, strings.Join(counts, ", ")),
+		"Targets:  " + targets,
+		"Duration: " + r.FinishedAt.Sub(r.StartedAt).Round(time.Second).String(),
+	}
+	if r.Profile != "" {
+		lines = append(lines, "Profile:  "+r.Profile)
+	}
+	return title, strings.Join(lines, "\n")
+}
+
+func describeFindings(event Event) (string, string) {
+	target := ""
+	if event.Session != nil {
+		target = event.Session.Target
+	}
+	title := fmt.Sprintf("%d new finding(s) on %s", len(event.Findings), target)
+
+	var lines []string
+	for i, f := range event.Findings {
+		if i == maxFindings {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(event.Findings)-maxFindings))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("[%s] %s %d %s", f.Level, f.Rule, f.Status, f.URL))
+	}
+	if event.Session != nil {
+		lines = append(lines, fmt.Sprintf("Found by %s - %s (session %s)", event.Session.Tool, event.Session.CommandName, event.Session.ID))
+	}
+	return title, strings.Join(lines, "\n")
+}
+
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
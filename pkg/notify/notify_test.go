@@ -0,0 +1,173 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/results"
+)
+
+// recorder is a webhook endpoint that keeps the payloads it receives
+type recorder struct {
+	server   *httptest.Server
+	payloads []map[string]interface{}
+	status   int
+}
+
+func newRecorder(t *testing.T) *recorder {
+	r := &recorder{status: http.StatusOK}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		r.payloads = append(r.payloads, payload)
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func newNotifier(t *testing.T, channels map[string]config.ChannelConfig) *Notifier {
+	t.Helper()
+	n, err := New(&config.Config{Notifications: config.NotificationsConfig{Channels: channels}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func saveEvent(t *testing.T, stateDir string, event executor.Event) {
+	t.Helper()
+	if err := event.Save(stateDir); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFlushAppliesFilters(t *testing.T) {
+	slack := newRecorder(t)
+	hook := newRecorder(t)
+	n := newNotifier(t, map[string]config.ChannelConfig{
+		"slack": {Type: config.ChannelSlack, URL: slack.server.URL, Events: []string{"session.failed"}},
+		"hook":  {Type: config.ChannelWebhook, URL: hook.server.URL, Tools: []string{"gobuster"}},
+	})
+
+	stateDir := t.TempDir()
+	code := 1
+	saveEvent(t, stateDir, executor.Event{ID: "1", Type: "session.failed", Session: &executor.Session{
+		ID: "abc", Tool: "ffuf", CommandName: "big", Target: "https://example.com",
+		Status: executor.StatusFailed, ExitCode: &code,
+	}})
+
+	sent, err := n.Flush(stateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 || len(slack.payloads) != 1 || len(hook.payloads) != 0 {
+		t.Fatalf("sent %d, slack got %d, hook got %d", sent, len(slack.payloads), len(hook.payloads))
+	}
+	if text := slack.payloads[0]["text"]; text != "*ffuf - big failed on https://example.com*\nSession: abc\nTarget:  https://example.com\nStatus:  failed (exit 1)" {
+		t.Errorf("slack text = %q", text)
+	}
+	if executor.HasEvents(stateDir) {
+		t.Error("delivered event was kept")
+	}
+}
+
+func TestFlushRetriesFailedChannels(t *testing.T) {
+	hook := newRecorder(t)
+	hook.status = http.StatusBadGateway
+	n := newNotifier(t, map[string]config.ChannelConfig{
+		"hook": {Type: config.ChannelWebhook, URL: hook.server.URL},
+	})
+
+	stateDir := t.TempDir()
+	saveEvent(t, stateDir, executor.Event{ID: "1", Type: executor.EventRunFinished, Run: &executor.Run{
+		ID: "20250101-120000-ab12", StartedAt: time.Now(), FinishedAt: time.Now(),
+	}})
+
+	for i := 1; i <= maxAttempts; i++ {
+		if _, err := n.Flush(stateDir); err != nil {
+			t.Fatal(err)
+		}
+		if kept := executor.HasEvents(stateDir); kept != (i < maxAttempts) {
+			t.Errorf("after attempt %d: event kept = %v", i, kept)
+		}
+	}
+	if len(hook.payloads) != maxAttempts || hook.payloads[0]["event"] != executor.EventRunFinished {
+		t.Errorf("hook got %v", hook.payloads)
+	}
+}
+
+func TestNotifyFindingsMinLevelAndTemplate(t *testing.T) {
+	hook := newRecorder(t)
+	n := newNotifier(t, map[string]config.ChannelConfig{
+		"hook": {Type: config.ChannelDiscord, URL: hook.server.URL, Title: "{{len .Findings}} new", Body: "{{range .Findings}}{{.Rule}} {{.URL}}{{end}}"},
+	})
+
+	session := executor.Session{ID: "abc", Tool: "ffuf", Target: "https://example.com"}
+	findings := []results.Finding{
+		{URL: "https://example.com/login", Path: "/login", Status: 200, Tool: "ffuf", Target: "https://example.com"},
+		{URL: "https://example.com/.git/HEAD", Path: "/.git/HEAD", Status: 200, Tool: "ffuf", Target: "https://example.com"},
+		{URL: "https://example.com/.env", Path: "/.env", Status: 403, Tool: "ffuf", Target: "https://example.com"},
+	}
+
+	if sent := n.NotifyFindings(session, findings); sent != 1 {
+		t.Fatalf("sent %d notifications", sent)
+	}
+	if content := hook.payloads[0]["content"]; content != "**1 new**\nexposed-vcs https://example.com/.git/HEAD" {
+		t.Errorf("discord content = %q", content)
+	}
+}
+
+func TestDiscordTruncatesOnRuneBoundary(t *testing.T) {
+	r := newRecorder(t)
+	ch := &channel{name: "discord", config: config.ChannelConfig{Type: config.ChannelDiscord, URL: r.server.URL}}
+
+	// Each of these is three bytes, so a byte limit would split one
+	body := strings.Repeat("発見", 1500)
+	if err := ch.sendWebhook(message{title: "Findings", body: body}); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.payloads) != 1 {
+		t.Fatalf("got %d payloads, want 1", len(r.payloads))
+	}
+
+	content := r.payloads[0]["content"].(string)
+	if !utf8.ValidString(content) || strings.ContainsRune(content, utf8.RuneError) {
+		t.Errorf("content is not valid UTF-8: %q", content[len(content)-12:])
+	}
+	if n := utf8.RuneCountInString(content); n != discordLimit {
+		t.Errorf("content has %d characters, want %d", n, discordLimit)
+	}
+	if !strings.HasPrefix(content, "**Findings**\n発見") || !strings.HasSuffix(content, "...") {
+		t.Errorf("content = %q...%q", content[:20], content[len(content)-12:])
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		limit int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"much too long", 10, "much to..."},
+		{"héllo wörld", 8, "héllo..."},
+		{"🔱🔱🔱🔱🔱", 4, "🔱..."},
+		{"🔱🔱🔱🔱", 4, "🔱🔱🔱🔱"},
+	}
+	for _, tt := range tests {
+		if got := truncate(tt.s, tt.limit); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bc0d3/trident-recon/pkg/config"
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// discordLimit is the longest message Discord accepts, in characters
+const discordLimit = 2000
+
+// httpClient sends webhook requests
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// webhookPayload is the body of generic webhooks
+type webhookPayload struct {
+	Event    string            `json:"event"`
+	Time     time.Time         `json:"time"`
+	Title    string            `json:"title"`
+	Text     string            `json:"text"`
+	Session  *executor.Session `json:"session,omitempty"`
+	Run      *executor.Run     `json:"run,omitempty"`
+	Findings []Finding         `json:"findings,omitempty"`
+}
+
+// sendWebhook posts a message in the JSON shape of the channel type
+func (ch *channel) sendWebhook(msg message) error {
+	url := ch.config.URL
+	if ch.config.URLSecret != "" {
+		var err error
+		if url, err = ch.secret(ch.config.URLSecret); err != nil {
+			return err
+		}
+	}
+
+	var payload interface{}
+	switch ch.config.Type {
+	case config.ChannelSlack:
+		payload = map[string]string{"text": fmt.Sprintf("*%s*\n%s", msg.title, msg.body)}
+	case config.ChannelDiscord:
+		content := truncate(fmt.Sprintf("**%s**\n%s", msg.title, msg.body), discordLimit)
+		payload = map[string]string{"content": content}
+	default:
+		payload = webhookPayload{
+			Event:    msg.event.Type,
+			Time:     msg.event.Time,
+			Title:    msg.title,
+			Text:     msg.body,
+			Session:  msg.event.Session,
+			Run:      msg.event.Run,
+			Findings: msg.event.Findings,
+		}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	data = []byte(utils.Redact(string(data)))
+
+	resp, err := httpClient.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		// The error quotes the URL, which may be secret
+		return fmt.Errorf("%s", utils.Redact(err.Error()))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// truncate shortens s to at most limit characters, ending it with "..."
+// when it is cut. It cuts between runes, so multi-byte characters stay
+// valid UTF-8.
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+
+	runes := 0
+	for i := range s {
+		if runes == limit-3 {
+			return s[:i] + "..."
+		}
+		runes++
+	}
+	return s
+}
@@ -0,0 +1,156 @@
+package report
+
+// styleTemplate holds the CSS and JavaScript shared by every page, so each
+// report is a single self-contained file
+const styleTemplate = `{{define "head"}}<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; margin: 2em; color: #222; background: #fafafa; }
+h1 { margin-bottom: 0.2em; }
+h2 { margin-top: 2em; border-bottom: 1px solid #ddd; padding-bottom: 0.3em; }
+.meta { color: #666; }
+.cards { display: flex; flex-wrap: wrap; gap: 1em; margin: 1em 0; }
+.card { background: #fff; border: 1px solid #ddd; border-radius: 6px; padding: 0.8em 1.2em; min-width: 7em; }
+.card b { display: block; font-size: 1.6em; }
+table { border-collapse: collapse; width: 100%; background: #fff; font-size: 0.9em; }
+th, td { border: 1px solid #ddd; padding: 0.4em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; cursor: pointer; user-select: none; white-space: nowrap; }
+th.asc::after { content: " ▲"; }
+th.desc::after { content: " ▼"; }
+tr:hover td { background: #f7f9ff; }
+td.url { word-break: break-all; }
+code { font-size: 0.95em; }
+input.filter { margin: 0.5em 0; padding: 0.4em; width: 24em; }
+.status { font-weight: bold; }
+.s-succeeded, .c2 { color: #1a7f37; }
+.s-running, .s-queued, .c3 { color: #0969da; }
+.s-failed, .s-lost, .s-timed-out, .c5 { color: #cf222e; }
+.s-killed, .c4 { color: #9a6700; }
+</style>
+<script>
+function sortTable(th) {
+  var table = th.closest("table"), body = table.tBodies[0];
+  var index = Array.prototype.indexOf.call(th.parentNode.children, th);
+  var numeric = th.dataset.type === "num";
+  var asc = !th.classList.contains("asc");
+  Array.prototype.forEach.call(th.parentNode.children, function (h) { h.classList.remove("asc", "desc"); });
+  th.classList.add(asc ? "asc" : "desc");
+  var rows = Array.prototype.slice.call(body.rows);
+  rows.sort(function (a, b) {
+    var x = a.cells[index].dataset.sort || a.cells[index].textContent;
+    var y = b.cells[index].dataset.sort || b.cells[index].textContent;
+    var cmp = numeric ? (parseFloat(x) || 0) - (parseFloat(y) || 0) : x.localeCompare(y);
+    return asc ? cmp : -cmp;
+  });
+  rows.forEach(function (r) { body.appendChild(r); });
+}
+function filterTable(input) {
+  var needle = input.value.toLowerCase();
+  var body = document.getElementById(input.dataset.table).tBodies[0];
+  Array.prototype.forEach.call(body.rows, function (r) {
+    r.style.display = r.textContent.toLowerCase().indexOf(needle) === -1 ? "none" : "";
+  });
+}
+document.addEventListener("DOMContentLoaded", function () {
+  document.querySelectorAll("th").forEach(function (th) {
+    th.addEventListener("click", function () { sortTable(th); });
+  });
+  document.querySelectorAll("input.filter").forEach(function (input) {
+    input.addEventListener("input", function () { filterTable(input); });
+  });
+});
+</script>{{end}}`
+
+const targetHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<title>Trident Recon - {{.Target}}</title>
+{{template "head"}}
+</head>
+<body>
+<h1>🔱 {{.Target}}</h1>
+<p class="meta">Generated {{timestamp .Generated}} · Output directory <code>{{.OutputDir}}</code></p>
+
+<h2>Run</h2>
+<div class="cards">
+  <div class="card"><b>{{len .Sessions}}</b>sessions</div>
+  {{range $status, $count := .StatusCounts}}<div class="card"><b class="s-{{$status}}">{{$count}}</b>{{$status}}</div>
+  {{end}}<div class="card"><b>{{len .Results}}</b>unique URLs</div>
+</div>
+<p class="meta">First session started {{timestamp .FirstStart}} · Last session finished {{timestamp .LastFinish}}</p>
+{{if .Runs}}<table id="runs">
+<thead><tr>
+  <th>Run ID</th><th>Profile</th><th>Config hash</th><th>Started</th><th>Finished</th>
+</tr></thead>
+<tbody>
+{{range .Runs}}<tr>
+  <td><code>{{.ID}}</code></td><td>{{or .Profile "-"}}</td><td><code>{{or .ConfigHash "-"}}</code></td>
+  <td>{{timestamp .StartedAt}}</td><td>{{timestamp .FinishedAt}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+{{end}}
+<h2>Sessions</h2>
+<input class="filter" data-table="sessions" placeholder="Filter sessions...">
+<table id="sessions">
+<thead><tr>
+  <th>ID</th><th>Tool</th><th>Command</th><th>Status</th><th data-type="num">Exit</th>
+  <th>Started</th><th data-type="num">Duration</th><th>Output</th>
+</tr></thead>
+<tbody>
+{{range .Sessions}}<tr>
+  <td><code>{{.ID}}</code></td><td>{{.Tool}}</td><td>{{.CommandName}}</td>
+  <td class="status s-{{.Status}}">{{.Status}}</td><td>{{exitCode .ExitCode}}</td>
+  <td>{{timestamp .StartedAt}}</td><td data-sort="{{seconds .}}">{{duration .}}</td>
+  <td class="url"><code>{{.OutputFile}}</code></td>
+</tr>
+{{end}}</tbody>
+</table>
+
+<h2>Findings</h2>
+{{if .Results}}<input class="filter" data-table="findings" placeholder="Filter findings...">
+<table id="findings">
+<thead><tr>
+  <th data-type="num">Status</th><th data-type="num">Length</th><th data-type="num">Words</th>
+  <th>Type</th><th>URL</th><th>Redirect</th><th>Found by</th><th>Sessions</th>
+</tr></thead>
+<tbody>
+{{range .Results}}<tr>
+  <td class="status {{statusClass .Status}}">{{.Status}}</td><td>{{.Length}}</td><td>{{.Words}}</td>
+  <td>{{.ContentType}}</td><td class="url"><a href="{{.URL}}">{{.URL}}</a></td>
+  <td class="url">{{.RedirectTo}}</td><td>{{join .Tools ", "}}</td><td><code>{{join .Sessions " "}}</code></td>
+</tr>
+{{end}}</tbody>
+</table>
+{{else}}<p class="meta">No findings yet. Run <code>trident-recon ingest</code> once sessions have finished.</p>
+{{end}}
+</body>
+</html>
+`
+
+const indexHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<title>Trident Recon - Report Index</title>
+{{template "head"}}
+</head>
+<body>
+<h1>🔱 Trident Recon</h1>
+<p class="meta">Generated {{timestamp .Generated}} · {{len .Entries}} target(s)</p>
+
+<input class="filter" data-table="targets" placeholder="Filter targets...">
+<table id="targets">
+<thead><tr>
+  <th>Target</th><th data-type="num">Sessions</th><th data-type="num">Running</th>
+  <th data-type="num">Succeeded</th><th data-type="num">Failed</th><th data-type="num">Unique URLs</th>
+</tr></thead>
+<tbody>
+{{range .Entries}}<tr>
+  <td><a href="{{.Link}}">{{.Target}}</a></td><td>{{.Sessions}}</td><td class="s-running">{{.Running}}</td>
+  <td class="s-succeeded">{{.Finished}}</td><td class="s-failed">{{.Failed}}</td><td>{{.Findings}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+</body>
+</html>
+`
@@ -0,0 +1,199 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/results"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// TargetReport holds everything shown in the report of one target
+type TargetReport struct {
+	Target     string
+	OutputDir  string
+	Generated  time.Time
+	FirstStart time.Time
+	LastFinish time.Time
+	Runs       []executor.Run // Runs that started the sessions, oldest first
+	Sessions   []executor.Session
+	Results    []results.Result
+}
+
+// StatusCounts returns how many sessions are in each status
+func (r *TargetReport) StatusCounts() map[executor.Status]int {
+	counts := make(map[executor.Status]int)
+	for _, s := range r.Sessions {
+		counts[s.Status]++
+	}
+	return counts
+}
+
+// IndexEntry is one line of the batch index page
+type IndexEntry struct {
+	Target   string
+	Link     string
+	Sessions int
+	Running  int
+	Finished int
+	Failed   int
+	Findings int
+}
+
+// NewTargetReport builds the report of a target from its sessions and
+// findings. runs may hold every run; only those that started one of the
+// sessions are kept.
+func NewTargetReport(target, outputDir string, runs []executor.Run, sessions []executor.Session, findings []results.Finding) *TargetReport {
+	report := &TargetReport{
+		Target:    target,
+		OutputDir: outputDir,
+		Generated: time.Now(),
+		Runs:      sessionRuns(runs, sessions),
+		Sessions:  sessions,
+		Results:   results.Dedupe(findings),
+	}
+
+	sort.SliceStable(report.Sessions, func(i, j int) bool {
+		if report.Sessions[i].Tool != report.Sessions[j].Tool {
+			return report.Sessions[i].Tool < report.Sessions[j].Tool
+		}
+		return report.Sessions[i].CommandName < report.Sessions[j].CommandName
+	})
+
+	for _, s := range sessions {
+		if !s.StartedAt.IsZero() && (report.FirstStart.IsZero() || s.StartedAt.Before(report.FirstStart)) {
+			report.FirstStart = s.StartedAt
+		}
+		if s.FinishedAt.After(report.LastFinish) {
+			report.LastFinish = s.FinishedAt
+		}
+	}
+
+	return report
+}
+
+// sessionRuns returns the runs that started sessions, oldest first. Runs
+// whose record was deleted are listed by ID only.
+func sessionRuns(runs []executor.Run, sessions []executor.Session) []executor.Run {
+	byID := make(map[string]executor.Run)
+	for _, run := range runs {
+		byID[run.ID] = run
+	}
+
+	var used []executor.Run
+	seen := make(map[string]bool)
+	for _, s := range sessions {
+		if s.RunID == "" || seen[s.RunID] {
+			continue
+		}
+		seen[s.RunID] = true
+
+		run, ok := byID[s.RunID]
+		if !ok {
+			run = executor.Run{ID: s.RunID}
+		}
+		used = append(used, run)
+	}
+
+	// Run IDs start with the start time
+	sort.Slice(used, func(i, j int) bool { return used[i].ID < used[j].ID })
+	return used
+}
+
+// Entry summarizes the report for the batch index. link is the path of the
+// target report relative to the index page.
+func (r *TargetReport) Entry(link string) IndexEntry {
+	entry := IndexEntry{
+		Target:   r.Target,
+		Link:     link,
+		Sessions: len(r.Sessions),
+		Findings: len(r.Results),
+	}
+
+	for _, s := range r.Sessions {
+		switch {
+		case s.Status.IsActive():
+			entry.Running++
+		case s.Status == executor.StatusSucceeded:
+			entry.Finished++
+		case s.Status.IsTerminal():
+			entry.Failed++
+		}
+	}
+
+	return entry
+}
+
+// WriteTarget renders the report of a target to path
+func WriteTarget(path string, report *TargetReport) error {
+	var html strings.Builder
+	if err := targetTemplate.Execute(&html, report); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return utils.WriteFile(path, html.String())
+}
+
+// WriteIndex renders the batch index page linking every target report
+func WriteIndex(path string, entries []IndexEntry) error {
+	data := struct {
+		Generated time.Time
+		Entries   []IndexEntry
+	}{
+		Generated: time.Now(),
+		Entries:   entries,
+	}
+
+	var html strings.Builder
+	if err := indexTemplate.Execute(&html, data); err != nil {
+		return fmt.Errorf("failed to render index: %w", err)
+	}
+	return utils.WriteFile(path, html.String())
+}
+
+// RelativeLink returns the link from the index page to a target report
+func RelativeLink(indexPath, reportPath string) string {
+	rel, err := filepath.Rel(filepath.Dir(indexPath), reportPath)
+	if err != nil {
+		return reportPath
+	}
+	return filepath.ToSlash(rel)
+}
+
+var funcs = template.FuncMap{
+	"timestamp": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format("2006-01-02 15:04:05")
+	},
+	"duration": func(s executor.Session) string {
+		d := s.Elapsed()
+		if d <= 0 {
+			return "-"
+		}
+		return d.Round(time.Second).String()
+	},
+	"seconds": func(s executor.Session) int64 {
+		return int64(s.Elapsed().Seconds())
+	},
+	"exitCode": func(code *int) string {
+		if code == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%d", *code)
+	},
+	"statusClass": func(status int) string {
+		return fmt.Sprintf("c%d", status/100)
+	},
+	"join": strings.Join,
+}
+
+var (
+	targetTemplate = template.Must(template.New("target").Funcs(funcs).Parse(styleTemplate + targetHTML))
+	indexTemplate  = template.Must(template.New("index").Funcs(funcs).Parse(styleTemplate + indexHTML))
+)
@@ -0,0 +1,164 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/results"
+)
+
+var start = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func testSessions() []executor.Session {
+	return []executor.Session{
+		{ID: "s1", RunID: "20250101-120000-aaaa", Tool: "gobuster", CommandName: "dir", Status: executor.StatusSucceeded,
+			StartedAt: start.Add(time.Minute), FinishedAt: start.Add(5 * time.Minute)},
+		{ID: "s2", RunID: "20250101-120000-aaaa", Tool: "ffuf", CommandName: "quick", Status: executor.StatusFailed,
+			StartedAt: start, FinishedAt: start.Add(2 * time.Minute)},
+		{ID: "s3", RunID: "20241231-080000-bbbb", Tool: "ffuf", CommandName: "deep", Status: executor.StatusRunning,
+			StartedAt: start.Add(3 * time.Minute)},
+		{ID: "s4", RunID: "20250102-090000-cccc", Tool: "dirsearch", CommandName: "default", Status: executor.StatusQueued},
+		{ID: "s5", Tool: "ffuf", CommandName: "api", Status: executor.StatusPending},
+	}
+}
+
+func testRuns() []executor.Run {
+	return []executor.Run{
+		{ID: "20250101-120000-aaaa", Profile: "stealth", ConfigHash: "0123456789ab", StartedAt: start, FinishedAt: start.Add(5 * time.Minute)},
+		{ID: "20241231-080000-bbbb", ConfigHash: "ba9876543210", StartedAt: start.Add(-28 * time.Hour)},
+		{ID: "20240101-000000-dddd", Profile: "other-target", ConfigHash: "ffffffffffff"},
+	}
+}
+
+func TestNewTargetReport(t *testing.T) {
+	findings := []results.Finding{
+		{URL: "https://example.com/b", Status: 200, Tool: "ffuf", SessionID: "s2"},
+		{URL: "https://example.com/a", Status: 403, Tool: "gobuster", SessionID: "s1"},
+		{URL: "https://example.com/b", Status: 200, Tool: "gobuster", SessionID: "s1"},
+	}
+	report := NewTargetReport("https://example.com", "/out/example.com", testRuns(), testSessions(), findings)
+
+	var order []string
+	for _, s := range report.Sessions {
+		order = append(order, s.ID)
+	}
+	if want := []string{"s4", "s5", "s3", "s2", "s1"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("sessions ordered %q, want %q (by tool, then command)", order, want)
+	}
+
+	if !report.FirstStart.Equal(start) || !report.LastFinish.Equal(start.Add(5*time.Minute)) {
+		t.Errorf("first start %v, last finish %v", report.FirstStart, report.LastFinish)
+	}
+
+	if len(report.Results) != 2 || report.Results[0].URL != "https://example.com/a" {
+		t.Errorf("results = %+v, want one per URL", report.Results)
+	}
+
+	// Only the runs of these sessions, oldest first, with deleted ones by ID
+	want := []executor.Run{testRuns()[1], testRuns()[0], {ID: "20250102-090000-cccc"}}
+	if !reflect.DeepEqual(report.Runs, want) {
+		t.Errorf("runs = %+v\nwant %+v", report.Runs, want)
+	}
+
+	counts := map[executor.Status]int{
+		executor.StatusSucceeded: 1, executor.StatusFailed: 1, executor.StatusRunning: 1,
+		executor.StatusQueued: 1, executor.StatusPending: 1,
+	}
+	if got := report.StatusCounts(); !reflect.DeepEqual(got, counts) {
+		t.Errorf("StatusCounts() = %v, want %v", got, counts)
+	}
+}
+
+func TestNewTargetReportWithoutRuns(t *testing.T) {
+	sessions := []executor.Session{{ID: "s1", Tool: "ffuf", Status: executor.StatusPending}}
+	report := NewTargetReport("https://example.com", "/out", testRuns(), sessions, nil)
+	if report.Runs != nil {
+		t.Errorf("runs = %+v, want none for sessions not started by a run", report.Runs)
+	}
+	if !report.FirstStart.IsZero() || !report.LastFinish.IsZero() {
+		t.Errorf("first start %v, last finish %v, want zero", report.FirstStart, report.LastFinish)
+	}
+}
+
+func TestEntry(t *testing.T) {
+	findings := []results.Finding{{URL: "https://example.com/a", SessionID: "s1"}}
+	report := NewTargetReport("https://example.com", "/out", nil, testSessions(), findings)
+
+	want := IndexEntry{
+		Target:   "https://example.com",
+		Link:     "example.com/report.html",
+		Sessions: 5,
+		Running:  2, // Running and queued
+		Finished: 1,
+		Failed:   1,
+		Findings: 1,
+	}
+	if got := report.Entry("example.com/report.html"); got != want {
+		t.Errorf("Entry() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteTarget(t *testing.T) {
+	sessions := testSessions()
+	sessions[0].OutputFile = "/out/<script>.txt"
+	report := NewTargetReport("https://example.com", "/out/example.com", testRuns(), sessions, nil)
+
+	path := filepath.Join(t.TempDir(), "example.com", "report.html")
+	if err := WriteTarget(path, report); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(data)
+
+	for _, want := range []string{
+		"<title>Trident Recon - https://example.com</title>",
+		"<code>20250101-120000-aaaa</code></td><td>stealth</td><td><code>0123456789ab</code>",
+		"<code>20241231-080000-bbbb</code></td><td>-</td><td><code>ba9876543210</code>",
+		"<code>20250102-090000-cccc</code></td><td>-</td><td><code>-</code>",
+		"<td>2025-01-01 12:00:00</td><td>2025-01-01 12:05:00</td>",
+		"/out/&lt;script&gt;.txt",
+		"No findings yet.",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+	if strings.Contains(html, "20240101-000000-dddd") {
+		t.Error("report lists a run that started none of its sessions")
+	}
+}
+
+func TestWriteIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.html")
+	entries := []IndexEntry{{Target: "https://example.com", Link: "example.com/report.html", Sessions: 3, Findings: 7}}
+	if err := WriteIndex(path, entries); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `<a href="example.com/report.html">https://example.com</a>`) {
+		t.Errorf("index does not link the target report:\n%s", data)
+	}
+}
+
+func TestRelativeLink(t *testing.T) {
+	for _, tt := range []struct{ index, report, want string }{
+		{"/out/index.html", "/out/example.com/report.html", "example.com/report.html"},
+		{"/out/index.html", "/out/index.html", "index.html"},
+		{"/out/batch/index.html", "/out/example.com/report.html", "../example.com/report.html"},
+	} {
+		if got := RelativeLink(tt.index, tt.report); got != tt.want {
+			t.Errorf("RelativeLink(%q, %q) = %q, want %q", tt.index, tt.report, got, tt.want)
+		}
+	}
+}
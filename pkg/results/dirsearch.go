@@ -0,0 +1,87 @@
+package results
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bc0d3/trident-recon/pkg/executor"
+)
+
+// "200   595B   http://example.com/admin/   -> REDIRECTS TO: http://example.com/login"
+var dirsearchLine = regexp.MustCompile(`^(\d{3})\s+(\d+(?:\.\d+)?)(B|KB|MB|GB)\s+(\S+)(?:\s+->\s+REDIRECTS TO:\s+(\S+))?`)
+
+func parseDirsearchText(r io.Reader, session executor.Session) ([]Finding, error) {
+	var findings []Finding
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(stripANSI(scanner.Text()))
+		m := dirsearchLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			URL:        joinURL(session.Target, m[4]),
+			Status:     atoi(m[1]),
+			Length:     parseSize(m[2], m[3]),
+			RedirectTo: m[5],
+		})
+	}
+
+	return findings, scanner.Err()
+}
+
+// dirsearchOutput is the subset of dirsearch's --format json output we use
+type dirsearchOutput struct {
+	Results []struct {
+		URL           string `json:"url"`
+		Status        int    `json:"status"`
+		ContentLength int64  `json:"content-length"`
+		ContentType   string `json:"content-type"`
+		Redirect      string `json:"redirect"`
+	} `json:"results"`
+}
+
+func parseDirsearchJSON(r io.Reader, session executor.Session) ([]Finding, error) {
+	var out dirsearchOutput
+	if err := json.NewDecoder(r).Decode(&out); err != nil {
+		return nil, err
+	}
+
+	findings := make([]Finding, 0, len(out.Results))
+	for _, res := range out.Results {
+		findings = append(findings, Finding{
+			URL:         joinURL(session.Target, res.URL),
+			Status:      res.Status,
+			Length:      res.ContentLength,
+			ContentType: res.ContentType,
+			RedirectTo:  res.Redirect,
+		})
+	}
+
+	return findings, nil
+}
+
+// parseSize converts dirsearch's human readable sizes (595B, 1.2KB) to bytes
+func parseSize(value, unit string) int64 {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+
+	switch unit {
+	case "KB":
+		n *= 1024
+	case "MB":
+		n *= 1024 * 1024
+	case "GB":
+		n *= 1024 * 1024 * 1024
+	}
+
+	return int64(n)
+}
@@ -0,0 +1,80 @@
+package results
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/bc0d3/trident-recon/pkg/executor"
+)
+
+// "200      GET        7l       12w      124c http://example.com/admin => http://example.com/admin/"
+var feroxbusterLine = regexp.MustCompile(`^(\d{3})\s+[A-Z]+\s+(\d+)l\s+(\d+)w\s+(\d+)c\s+(\S+)(?:\s+=>\s+(\S+))?`)
+
+func parseFeroxbusterText(r io.Reader, session executor.Session) ([]Finding, error) {
+	var findings []Finding
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(stripANSI(scanner.Text()))
+		m := feroxbusterLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			URL:        m[5],
+			Status:     atoi(m[1]),
+			Lines:      atoi(m[2]),
+			Words:      atoi(m[3]),
+			Length:     atoi64(m[4]),
+			RedirectTo: m[6],
+		})
+	}
+
+	return findings, scanner.Err()
+}
+
+// feroxbusterEntry is one line of feroxbuster's --json output
+type feroxbusterEntry struct {
+	Type          string            `json:"type"`
+	URL           string            `json:"url"`
+	Status        int               `json:"status"`
+	ContentLength int64             `json:"content_length"`
+	LineCount     int               `json:"line_count"`
+	WordCount     int               `json:"word_count"`
+	Headers       map[string]string `json:"headers"`
+}
+
+func parseFeroxbusterJSON(r io.Reader, session executor.Session) ([]Finding, error) {
+	var findings []Finding
+
+	decoder := json.NewDecoder(r)
+	for {
+		var entry feroxbusterEntry
+		err := decoder.Decode(&entry)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if entry.Type != "response" {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			URL:         entry.URL,
+			Status:      entry.Status,
+			Length:      entry.ContentLength,
+			Lines:       entry.LineCount,
+			Words:       entry.WordCount,
+			ContentType: entry.Headers["content-type"],
+			RedirectTo:  entry.Headers["location"],
+		})
+	}
+
+	return findings, nil
+}
@@ -0,0 +1,56 @@
+package results
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+
+	"github.com/bc0d3/trident-recon/pkg/executor"
+)
+
+// ffufOutput is the subset of ffuf's -of json output we use
+type ffufOutput struct {
+	Results []struct {
+		URL              string `json:"url"`
+		Host             string `json:"host"`
+		Status           int    `json:"status"`
+		Length           int64  `json:"length"`
+		Words            int    `json:"words"`
+		Lines            int    `json:"lines"`
+		ContentType      string `json:"content-type"`
+		RedirectLocation string `json:"redirectlocation"`
+	} `json:"results"`
+}
+
+func parseFfufJSON(r io.Reader, session executor.Session) ([]Finding, error) {
+	var out ffufOutput
+	if err := json.NewDecoder(r).Decode(&out); err != nil {
+		return nil, err
+	}
+
+	findings := make([]Finding, 0, len(out.Results))
+	for _, res := range out.Results {
+		findings = append(findings, Finding{
+			URL:         ffufResultURL(res.URL, res.Host),
+			Status:      res.Status,
+			Length:      res.Length,
+			Words:       res.Words,
+			Lines:       res.Lines,
+			ContentType: res.ContentType,
+			RedirectTo:  res.RedirectLocation,
+		})
+	}
+
+	return findings, nil
+}
+
+// ffufResultURL puts the fuzzed Host header into the URL for vhost scans,
+// where every result shares the same request URL
+func ffufResultURL(rawURL, host string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || host == "" || u.Host == host {
+		return rawURL
+	}
+	u.Host = host
+	return u.String()
+}
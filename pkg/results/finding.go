@@ -0,0 +1,237 @@
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// Finding is a single discovered URL, normalized across tools
+type Finding struct {
+	Target      string    `json:"target"`
+	URL         string    `json:"url"`
+	Path        string    `json:"path"`
+	Status      int       `json:"status"`
+	Length      int64     `json:"length"`
+	Words       int       `json:"words,omitempty"`
+	Lines       int       `json:"lines,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	RedirectTo  string    `json:"redirect_to,omitempty"`
+	Tool        string    `json:"tool"`
+	CommandName string    `json:"command_name"`
+	SessionID   string    `json:"session_id"`
+	FoundAt     time.Time `json:"found_at"`
+}
+
+// Store keeps findings on disk, one file per target host
+type Store struct {
+	Dir string
+}
+
+// NewStore creates a findings store inside the state directory
+func NewStore(stateDir string) *Store {
+	return &Store{
+		Dir: filepath.Join(stateDir, "findings"),
+	}
+}
+
+// TargetKey returns the name findings of a target are stored under
+func TargetKey(target string) string {
+	_, domain, err := utils.ParseURL(target)
+	if err != nil || domain == "" {
+		return target
+	}
+	return utils.TargetKey(domain)
+}
+
+func (st *Store) path(target string) string {
+	return filepath.Join(st.Dir, TargetKey(target)+".json")
+}
+
+// Load returns all findings stored for a target
+func (st *Store) Load(target string) ([]Finding, error) {
+	return loadFile(st.path(target))
+}
+
+// loadFile reads a findings file; a missing file holds no findings
+func loadFile(path string) ([]Finding, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []Finding{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	if err := json.Unmarshal(data, &findings); err != nil {
+		return nil, err
+	}
+
+	return findings, nil
+}
+
+// LoadAll returns the findings of every target
+func (st *Store) LoadAll() ([]Finding, error) {
+	entries, err := os.ReadDir(st.Dir)
+	if os.IsNotExist(err) {
+		return []Finding{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var all []Finding
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		findings, err := loadFile(filepath.Join(st.Dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, findings...)
+	}
+
+	return all, nil
+}
+
+// Save replaces the stored findings of a target
+func (st *Store) Save(target string, findings []Finding) error {
+	return st.saveFile(st.path(target), findings)
+}
+
+// saveFile writes a findings file, sorted by URL
+func (st *Store) saveFile(path string, findings []Finding) error {
+	if err := os.MkdirAll(st.Dir, 0755); err != nil {
+		return err
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].URL != findings[j].URL {
+			return findings[i].URL < findings[j].URL
+		}
+		return findings[i].SessionID < findings[j].SessionID
+	})
+
+	data, err := json.MarshalIndent(findings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteFileAtomic(path, []byte(utils.Redact(string(data))), 0644)
+}
+
+// lock takes the findings lock, held while findings are read, changed and
+// written back so concurrent ingests do not drop each other's findings
+func (st *Store) lock() (*utils.FileLock, error) {
+	lock, err := utils.LockFile(filepath.Join(st.Dir, ".lock"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock findings: %w", err)
+	}
+	return lock, nil
+}
+
+// ReplaceSession swaps the findings of one session for a fresh set, so that
+// parsing the same output twice does not duplicate anything. It returns the
+// findings whose URL had not been found on the target before.
+func (st *Store) ReplaceSession(target, sessionID string, findings []Finding) ([]Finding, error) {
+	lock, err := st.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	existing, err := st.Load(target)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(existing))
+	merged := make([]Finding, 0, len(existing)+len(findings))
+	for _, f := range existing {
+		known[f.URL] = true
+		if f.SessionID != sessionID {
+			merged = append(merged, f)
+		}
+	}
+	merged = append(merged, findings...)
+
+	var fresh []Finding
+	for _, f := range findings {
+		if !known[f.URL] {
+			known[f.URL] = true
+			fresh = append(fresh, f)
+		}
+	}
+
+	if err := st.Save(target, merged); err != nil {
+		return nil, err
+	}
+	return fresh, nil
+}
+
+// DeleteSessions removes the findings of the given sessions from every
+// target and returns how many were removed
+func (st *Store) DeleteSessions(sessionIDs []string) (int, error) {
+	lock, err := st.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer lock.Unlock()
+
+	entries, err := os.ReadDir(st.Dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(st.Dir, entry.Name())
+
+		existing, err := loadFile(path)
+		if err != nil {
+			return removed, err
+		}
+
+		kept := make([]Finding, 0, len(existing))
+		for _, f := range existing {
+			if !containsString(sessionIDs, f.SessionID) {
+				kept = append(kept, f)
+			}
+		}
+		if len(kept) == len(existing) {
+			continue
+		}
+
+		if err := st.saveFile(path, kept); err != nil {
+			return removed, err
+		}
+		removed += len(existing) - len(kept)
+	}
+
+	return removed, nil
+}
+
+// urlPath returns the path component of a URL, or "/" when it has none
+func urlPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
@@ -0,0 +1,75 @@
+package results
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bc0d3/trident-recon/pkg/executor"
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+var (
+	// "/admin (Status: 301) [Size: 169] [--> http://example.com/admin/]"
+	gobusterDirLine = regexp.MustCompile(`^(\S+)\s+\(Status:\s*(\d+)\)(?:\s+\[Size:\s*(\d+)\])?(?:\s+\[-->\s*(\S+)\])?`)
+	// "Found: admin.example.com Status: 200 [Size: 1234]"
+	gobusterVhostLine = regexp.MustCompile(`^Found:\s+(\S+)\s+Status:\s*(\d+)(?:\s+\[Size:\s*(\d+)\])?`)
+)
+
+func parseGobusterText(r io.Reader, session executor.Session) ([]Finding, error) {
+	var findings []Finding
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(stripANSI(scanner.Text()))
+
+		if m := gobusterDirLine.FindStringSubmatch(line); m != nil {
+			findings = append(findings, Finding{
+				URL:        joinURL(session.Target, m[1]),
+				Status:     atoi(m[2]),
+				Length:     atoi64(m[3]),
+				RedirectTo: m[4],
+			})
+			continue
+		}
+
+		if m := gobusterVhostLine.FindStringSubmatch(line); m != nil {
+			protocol, _, _ := utils.ParseURL(session.Target)
+			findings = append(findings, Finding{
+				URL:    protocol + "://" + m[1],
+				Status: atoi(m[2]),
+				Length: atoi64(m[3]),
+			})
+		}
+	}
+
+	return findings, scanner.Err()
+}
+
+// joinURL resolves a path printed by a tool against the target URL.
+// Full URLs are returned unchanged.
+func joinURL(base, path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return strings.TrimRight(utils.NormalizeURL(base), "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// stripANSI removes terminal color codes some tools write to their files
+func stripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func atoi64(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
@@ -0,0 +1,132 @@
+package results
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/executor"
+)
+
+// Output formats a parser can be registered for
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Parser reads a tool's output and returns the findings in it. Parsers only
+// fill in what the output contains; source fields are set by Ingest.
+type Parser func(r io.Reader, session executor.Session) ([]Finding, error)
+
+// registry maps "tool/format" to the parser for that output
+var registry = map[string]Parser{}
+
+// Register adds a parser for a tool and output format
+func Register(tool, format string, parser Parser) {
+	registry[tool+"/"+format] = parser
+}
+
+// Lookup returns the parser for a tool and output format
+func Lookup(tool, format string) (Parser, bool) {
+	parser, ok := registry[tool+"/"+format]
+	return parser, ok
+}
+
+func init() {
+	Register("ffuf", FormatJSON, parseFfufJSON)
+	Register("gobuster", FormatText, parseGobusterText)
+	Register("dirsearch", FormatText, parseDirsearchText)
+	Register("dirsearch", FormatJSON, parseDirsearchJSON)
+	Register("feroxbuster", FormatText, parseFeroxbusterText)
+	Register("feroxbuster", FormatJSON, parseFeroxbusterJSON)
+}
+
+// DetectFormat guesses the format of an output file from its first
+// non-blank character, since tools do not always honour the file extension
+func DetectFormat(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			return FormatText, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '{', '[':
+			return FormatJSON, nil
+		default:
+			return FormatText, nil
+		}
+	}
+}
+
+// Parse reads the output file of a session with the matching parser and
+// returns findings tagged with the session they came from
+func Parse(session executor.Session) ([]Finding, error) {
+	if session.OutputFile == "" {
+		return nil, fmt.Errorf("session %s has no output file", session.ID)
+	}
+
+	format, err := DetectFormat(session.OutputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	parser, ok := Lookup(session.Tool, format)
+	if !ok {
+		return nil, fmt.Errorf("no parser for %s %s output", session.Tool, format)
+	}
+
+	file, err := os.Open(session.OutputFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	findings, err := parser(file, session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", session.OutputFile, err)
+	}
+
+	now := time.Now()
+	for i := range findings {
+		findings[i].Target = session.Target
+		findings[i].Path = urlPath(findings[i].URL)
+		findings[i].Tool = session.Tool
+		findings[i].CommandName = session.CommandName
+		findings[i].SessionID = session.ID
+		findings[i].FoundAt = now
+	}
+
+	return findings, nil
+}
+
+// Ingest parses the output of a session and stores its findings under the
+// session's target. It returns the number of findings stored and those that
+// are new for the target.
+func Ingest(stateDir string, session executor.Session) (int, []Finding, error) {
+	findings, err := Parse(session)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	store := NewStore(stateDir)
+	fresh, err := store.ReplaceSession(session.Target, session.ID, findings)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to store findings: %w", err)
+	}
+
+	return len(findings), fresh, nil
+}
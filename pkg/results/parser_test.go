@@ -0,0 +1,119 @@
+package results
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bc0d3/trident-recon/pkg/executor"
+)
+
+// parseFixture parses a file from testdata as the output of a tool run
+// against https://example.com. The source fields Parse adds are checked
+// and then cleared, so results compare against what the output contains.
+func parseFixture(t *testing.T, tool, name string) ([]Finding, error) {
+	t.Helper()
+	session := executor.Session{
+		ID:          "abc123",
+		Tool:        tool,
+		CommandName: "dir",
+		Target:      "https://example.com",
+		OutputFile:  filepath.Join("testdata", name),
+	}
+
+	findings, err := Parse(session)
+	for i, f := range findings {
+		if f.Target != session.Target || f.Tool != tool || f.CommandName != "dir" || f.SessionID != "abc123" || f.FoundAt.IsZero() {
+			t.Errorf("%s: finding %d is not tagged with its session: %+v", name, i, f)
+		}
+		findings[i].Target, findings[i].Tool, findings[i].CommandName, findings[i].SessionID = "", "", "", ""
+		findings[i].FoundAt = time.Time{}
+	}
+	return findings, err
+}
+
+// checkFixture fails unless a fixture parses to exactly want
+func checkFixture(t *testing.T, tool, name string, want []Finding) {
+	t.Helper()
+	got, err := parseFixture(t, tool, name)
+	if err != nil {
+		t.Errorf("%s: %v", name, err)
+		return
+	}
+	if len(got) != len(want) || len(want) > 0 && !reflect.DeepEqual(got, want) {
+		t.Errorf("%s:\n got %+v\nwant %+v", name, got, want)
+	}
+}
+
+// checkFixtureError fails unless parsing a fixture fails with an error
+// containing msg
+func checkFixtureError(t *testing.T, tool, name, msg string) {
+	t.Helper()
+	if _, err := parseFixture(t, tool, name); err == nil || !strings.Contains(err.Error(), msg) {
+		t.Errorf("%s: got error %v, want one containing %q", name, err, msg)
+	}
+}
+
+func TestParseFfuf(t *testing.T) {
+	checkFixture(t, "ffuf", "ffuf.json", []Finding{
+		{URL: "https://example.com/admin", Path: "/admin", Status: 301, Length: 169, Words: 5, Lines: 8, ContentType: "text/html", RedirectTo: "https://example.com/admin/"},
+		{URL: "https://example.com/.git/HEAD", Path: "/.git/HEAD", Status: 200, Length: 23, Words: 2, Lines: 2, ContentType: "text/plain"},
+		// A vhost result carries the fuzzed Host header in its URL
+		{URL: "https://dev.example.com/", Path: "/", Status: 200, Length: 1543, Words: 120, Lines: 40, ContentType: "text/html; charset=utf-8"},
+	})
+	checkFixture(t, "ffuf", "ffuf_empty_results.json", nil)
+	checkFixtureError(t, "ffuf", "ffuf_truncated.json", "unexpected EOF")
+	// ffuf always writes JSON, so an empty file means it never finished
+	checkFixtureError(t, "ffuf", "empty", "no parser for ffuf text output")
+}
+
+func TestParseGobuster(t *testing.T) {
+	checkFixture(t, "gobuster", "gobuster_dir.txt", []Finding{
+		{URL: "https://example.com/admin", Path: "/admin", Status: 301, Length: 169, RedirectTo: "https://example.com/admin/"},
+		{URL: "https://example.com/.git/HEAD", Path: "/.git/HEAD", Status: 200, Length: 23},
+		{URL: "https://example.com/server-status", Path: "/server-status", Status: 403, Length: 277},
+		{URL: "https://example.com/robots.txt", Path: "/robots.txt", Status: 200, Length: 67},
+	})
+	checkFixture(t, "gobuster", "gobuster_vhost.txt", []Finding{
+		{URL: "https://dev.example.com", Path: "/", Status: 200, Length: 1543},
+		{URL: "https://staging.example.com", Path: "/", Status: 401, Length: 381},
+	})
+	checkFixture(t, "gobuster", "gobuster_garbage.txt", nil)
+	checkFixture(t, "gobuster", "empty", nil)
+}
+
+func TestParseDirsearch(t *testing.T) {
+	checkFixture(t, "dirsearch", "dirsearch.txt", []Finding{
+		{URL: "https://example.com/admin", Path: "/admin", Status: 301, Length: 169, RedirectTo: "https://example.com/admin/"},
+		{URL: "https://example.com/robots.txt", Path: "/robots.txt", Status: 200, Length: 2048},
+		{URL: "https://example.com/.htaccess", Path: "/.htaccess", Status: 403, Length: 199},
+		{URL: "https://example.com/backup.zip", Path: "/backup.zip", Status: 200, Length: 1572864},
+	})
+	checkFixture(t, "dirsearch", "dirsearch.json", []Finding{
+		{URL: "https://example.com/admin", Path: "/admin", Status: 301, Length: 169, ContentType: "text/html", RedirectTo: "https://example.com/admin/"},
+		{URL: "https://example.com/robots.txt", Path: "/robots.txt", Status: 200, Length: 67, ContentType: "text/plain"},
+	})
+	checkFixture(t, "dirsearch", "dirsearch_garbage.txt", nil)
+	checkFixture(t, "dirsearch", "empty", nil)
+}
+
+func TestParseFeroxbuster(t *testing.T) {
+	want := []Finding{
+		{URL: "https://example.com/admin", Path: "/admin", Status: 301, Length: 169, Words: 12, Lines: 7, RedirectTo: "https://example.com/admin/"},
+		{URL: "https://example.com/.git/HEAD", Path: "/.git/HEAD", Status: 200, Length: 23, Words: 2, Lines: 2},
+		{URL: "https://example.com/admin/login", Path: "/admin/login", Status: 200, Length: 1543, Words: 120, Lines: 40},
+	}
+	checkFixture(t, "feroxbuster", "feroxbuster.txt", want)
+
+	// The JSON output adds the content type, and skips the configuration
+	// and statistics entries
+	want[0].ContentType = "text/html"
+	want[1].ContentType = "text/plain"
+	checkFixture(t, "feroxbuster", "feroxbuster.json", want[:2])
+
+	checkFixtureError(t, "feroxbuster", "feroxbuster_truncated.json", "unexpected EOF")
+	checkFixture(t, "feroxbuster", "feroxbuster_garbage.txt", nil)
+	checkFixture(t, "feroxbuster", "empty", nil)
+}
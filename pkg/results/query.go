@@ -0,0 +1,228 @@
+package results
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Range is an inclusive numeric range, a bound of -1 means open-ended
+type Range struct {
+	Min int64
+	Max int64
+}
+
+// Contains reports whether n falls inside the range
+func (r Range) Contains(n int64) bool {
+	return (r.Min < 0 || n >= r.Min) && (r.Max < 0 || n <= r.Max)
+}
+
+// ParseStatusRanges parses status filters such as "200", "301-302" or "4xx"
+func ParseStatusRanges(values []string) ([]Range, error) {
+	var ranges []Range
+	for _, value := range values {
+		value = strings.TrimSpace(strings.ToLower(value))
+		if len(value) == 3 && strings.HasSuffix(value, "xx") {
+			class, err := strconv.ParseInt(value[:1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid status class %q", value)
+			}
+			ranges = append(ranges, Range{Min: class * 100, Max: class*100 + 99})
+			continue
+		}
+
+		r, err := parseRange(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status filter %q: %w", value, err)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// ParseSizeRanges parses size filters such as "1234", "100-500", "1000-" or "-500"
+func ParseSizeRanges(values []string) ([]Range, error) {
+	var ranges []Range
+	for _, value := range values {
+		r, err := parseRange(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid size filter %q: %w", value, err)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// parseRange parses "n", "min-max", "min-" or "-max". A range must have at
+// least one bound and its bounds must be in order.
+func parseRange(value string) (Range, error) {
+	if value == "" {
+		return Range{}, fmt.Errorf("empty range")
+	}
+
+	bounds := strings.SplitN(value, "-", 2)
+	if len(bounds) == 1 {
+		n, err := parseBound(value)
+		if err != nil {
+			return Range{}, err
+		}
+		return Range{Min: n, Max: n}, nil
+	}
+	if bounds[0] == "" && bounds[1] == "" {
+		return Range{}, fmt.Errorf("range has no bounds")
+	}
+
+	r := Range{Min: -1, Max: -1}
+	var err error
+	if bounds[0] != "" {
+		if r.Min, err = parseBound(bounds[0]); err != nil {
+			return Range{}, err
+		}
+	}
+	if bounds[1] != "" {
+		if r.Max, err = parseBound(bounds[1]); err != nil {
+			return Range{}, err
+		}
+	}
+	if r.Min >= 0 && r.Max >= 0 && r.Min > r.Max {
+		return Range{}, fmt.Errorf("range %d-%d is reversed", r.Min, r.Max)
+	}
+	return r, nil
+}
+
+// parseBound parses a range bound, which cannot be negative
+func parseBound(value string) (int64, error) {
+	n, err := strconv.ParseUint(value, 10, 63)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a non-negative number", value)
+	}
+	return int64(n), nil
+}
+
+// Filter selects findings. Empty fields match everything.
+type Filter struct {
+	Statuses []Range
+	Sizes    []Range
+	Path     *regexp.Regexp
+	Tools    []string
+	Commands []string
+	Sessions []string // Session IDs, e.g. the sessions of a run
+}
+
+// Match reports whether a finding passes the filter
+func (f Filter) Match(finding Finding) bool {
+	if len(f.Statuses) > 0 && !anyContains(f.Statuses, int64(finding.Status)) {
+		return false
+	}
+	if len(f.Sizes) > 0 && !anyContains(f.Sizes, finding.Length) {
+		return false
+	}
+	if f.Path != nil && !f.Path.MatchString(finding.Path) {
+		return false
+	}
+	if len(f.Tools) > 0 && !containsString(f.Tools, finding.Tool) {
+		return false
+	}
+	if len(f.Commands) > 0 && !containsString(f.Commands, finding.CommandName) {
+		return false
+	}
+	if f.Sessions != nil && !containsString(f.Sessions, finding.SessionID) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the findings that pass the filter
+func (f Filter) Apply(findings []Finding) []Finding {
+	var matched []Finding
+	for _, finding := range findings {
+		if f.Match(finding) {
+			matched = append(matched, finding)
+		}
+	}
+	return matched
+}
+
+// Result is a URL found by one or more sessions
+type Result struct {
+	URL         string   `json:"url"`
+	Target      string   `json:"target"`
+	Status      int      `json:"status"`
+	Length      int64    `json:"length"`
+	Words       int      `json:"words,omitempty"`
+	ContentType string   `json:"content_type,omitempty"`
+	RedirectTo  string   `json:"redirect_to,omitempty"`
+	Tools       []string `json:"tools"`
+	Sessions    []string `json:"sessions"`
+}
+
+// Dedupe merges findings of the same URL reported by several sessions.
+// The first finding of a URL provides the response details, later ones
+// only fill in details the earlier tools did not report.
+func Dedupe(findings []Finding) []Result {
+	var order []string
+	byURL := make(map[string]*Result)
+
+	for _, f := range findings {
+		r, ok := byURL[f.URL]
+		if !ok {
+			r = &Result{
+				URL:         f.URL,
+				Target:      f.Target,
+				Status:      f.Status,
+				Length:      f.Length,
+				Words:       f.Words,
+				ContentType: f.ContentType,
+				RedirectTo:  f.RedirectTo,
+			}
+			byURL[f.URL] = r
+			order = append(order, f.URL)
+		}
+		if r.Words == 0 {
+			r.Words = f.Words
+		}
+		if r.ContentType == "" {
+			r.ContentType = f.ContentType
+		}
+		if r.RedirectTo == "" {
+			r.RedirectTo = f.RedirectTo
+		}
+		if !containsString(r.Tools, f.Tool) {
+			r.Tools = append(r.Tools, f.Tool)
+		}
+		if !containsString(r.Sessions, f.SessionID) {
+			r.Sessions = append(r.Sessions, f.SessionID)
+		}
+	}
+
+	sort.Strings(order)
+	results := make([]Result, 0, len(order))
+	for _, u := range order {
+		r := byURL[u]
+		sort.Strings(r.Tools)
+		sort.Strings(r.Sessions)
+		results = append(results, *r)
+	}
+
+	return results
+}
+
+func anyContains(ranges []Range, n int64) bool {
+	for _, r := range ranges {
+		if r.Contains(n) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package results
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Range
+		wantErr bool
+	}{
+		{value: "200", want: Range{Min: 200, Max: 200}},
+		{value: "0", want: Range{Min: 0, Max: 0}},
+		{value: "301-302", want: Range{Min: 301, Max: 302}},
+		{value: "100-100", want: Range{Min: 100, Max: 100}},
+		{value: "1000-", want: Range{Min: 1000, Max: -1}},
+		{value: "-500", want: Range{Min: -1, Max: 500}},
+		{value: "", wantErr: true},
+		{value: "-", wantErr: true},
+		{value: "500-100", wantErr: true},
+		{value: "abc", wantErr: true},
+		{value: "1-x", wantErr: true},
+		{value: "5--3", wantErr: true},
+		{value: "+5", wantErr: true},
+		{value: "1-2-3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseRange(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseRange(%q) = %+v, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseRange(%q) = %+v, %v; want %+v", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseStatusRanges(t *testing.T) {
+	tests := []struct {
+		values  []string
+		want    []Range
+		wantErr bool
+	}{
+		{values: nil, want: nil},
+		{values: []string{"200"}, want: []Range{{200, 200}}},
+		{values: []string{"2xx", " 4XX "}, want: []Range{{200, 299}, {400, 499}}},
+		{values: []string{"301-302", "500-"}, want: []Range{{301, 302}, {500, -1}}},
+		{values: []string{"xxx"}, wantErr: true},
+		{values: []string{"-"}, wantErr: true},
+		{values: []string{"500-100"}, wantErr: true},
+		{values: []string{"200", "nope"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseStatusRanges(tt.values)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseStatusRanges(%q) = %v, want an error", tt.values, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseStatusRanges(%q) = %v, %v; want %v", tt.values, got, err, tt.want)
+		}
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	finding := Finding{
+		Path:        "/admin/login.php",
+		Status:      403,
+		Length:      1234,
+		Tool:        "ffuf",
+		CommandName: "quick",
+		SessionID:   "abc123",
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty filter", Filter{}, true},
+		{"status in range", Filter{Statuses: []Range{{400, 499}}}, true},
+		{"status in any range", Filter{Statuses: []Range{{200, 299}, {403, 403}}}, true},
+		{"status out of range", Filter{Statuses: []Range{{200, 299}}}, false},
+		{"size open-ended", Filter{Sizes: []Range{{1000, -1}}}, true},
+		{"size out of range", Filter{Sizes: []Range{{-1, 1000}}}, false},
+		{"path matches", Filter{Path: regexp.MustCompile(`^/admin/`)}, true},
+		{"path does not match", Filter{Path: regexp.MustCompile(`\.bak$`)}, false},
+		{"tool listed", Filter{Tools: []string{"gobuster", "ffuf"}}, true},
+		{"tool not listed", Filter{Tools: []string{"gobuster"}}, false},
+		{"command listed", Filter{Commands: []string{"quick"}}, true},
+		{"command not listed", Filter{Commands: []string{"deep"}}, false},
+		{"session listed", Filter{Sessions: []string{"abc123"}}, true},
+		{"no sessions", Filter{Sessions: []string{}}, false},
+		{"every field matches", Filter{
+			Statuses: []Range{{403, 403}},
+			Sizes:    []Range{{1234, 1234}},
+			Path:     regexp.MustCompile("login"),
+			Tools:    []string{"ffuf"},
+			Commands: []string{"quick"},
+			Sessions: []string{"abc123"},
+		}, true},
+		{"one field fails", Filter{Statuses: []Range{{403, 403}}, Tools: []string{"gobuster"}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.filter.Match(finding); got != tt.want {
+			t.Errorf("%s: Match() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	findings := []Finding{
+		{URL: "https://example.com/b", Target: "https://example.com", Status: 200, Length: 10, Tool: "gobuster", SessionID: "s2"},
+		{URL: "https://example.com/a", Target: "https://example.com", Status: 301, Length: 0, RedirectTo: "/a/", Tool: "ffuf", SessionID: "s1"},
+		{URL: "https://example.com/b", Status: 403, Length: 99, Words: 5, ContentType: "text/html", Tool: "ffuf", SessionID: "s1"},
+		{URL: "https://example.com/b", Words: 7, Tool: "ffuf", SessionID: "s3"},
+		{URL: "https://example.com/a", RedirectTo: "/other", Tool: "ffuf", SessionID: "s1"},
+	}
+
+	want := []Result{
+		{
+			URL:        "https://example.com/a",
+			Target:     "https://example.com",
+			Status:     301,
+			RedirectTo: "/a/",
+			Tools:      []string{"ffuf"},
+			Sessions:   []string{"s1"},
+		},
+		{
+			URL:         "https://example.com/b",
+			Target:      "https://example.com",
+			Status:      200,
+			Length:      10,
+			Words:       5,
+			ContentType: "text/html",
+			Tools:       []string{"ffuf", "gobuster"},
+			Sessions:    []string{"s1", "s2", "s3"},
+		},
+	}
+	if got := Dedupe(findings); !reflect.DeepEqual(got, want) {
+		t.Errorf("Dedupe() = %+v\nwant %+v", got, want)
+	}
+
+	if got := Dedupe(nil); len(got) != 0 {
+		t.Errorf("Dedupe(nil) = %+v, want no results", got)
+	}
+}
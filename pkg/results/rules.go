@@ -0,0 +1,93 @@
+package results
+
+import "regexp"
+
+// Rule classifies what kind of exposure a finding is
+type Rule struct {
+	ID          string
+	Name        string
+	Description string
+	Level       string // SARIF level: error, warning or note
+	pattern     *regexp.Regexp
+}
+
+// Rules are checked in order, the first matching rule wins
+var Rules = []Rule{
+	{
+		ID:          "exposed-vcs",
+		Name:        "Exposed version control data",
+		Description: "Version control metadata such as .git or .svn is reachable",
+		Level:       "error",
+		pattern:     regexp.MustCompile(`(?i)/\.(git|svn|hg|bzr)(/|$)`),
+	},
+	{
+		ID:          "exposed-secret-file",
+		Name:        "Exposed secrets file",
+		Description: "A file that commonly holds credentials (.env, keys, htpasswd) is reachable",
+		Level:       "error",
+		pattern:     regexp.MustCompile(`(?i)(/\.env(\.[a-z]+)?|\.pem|\.key|id_rsa|\.htpasswd|credentials(\.[a-z]+)?)$`),
+	},
+	{
+		ID:          "exposed-backup-file",
+		Name:        "Exposed backup file",
+		Description: "A backup, archive or database dump is reachable",
+		Level:       "warning",
+		pattern:     regexp.MustCompile(`(?i)(\.(bak|backup|old|orig|save|swp|tmp|sql|db|sqlite3?|zip|tar|tar\.gz|tgz|rar|7z)|~)$`),
+	},
+	{
+		ID:          "exposed-config-file",
+		Name:        "Exposed configuration file",
+		Description: "A configuration or log file is reachable",
+		Level:       "warning",
+		pattern:     regexp.MustCompile(`(?i)\.(config|conf|cfg|ini|yml|yaml|properties|log)$|/web\.config$`),
+	},
+	{
+		ID:          "api-documentation",
+		Name:        "API documentation",
+		Description: "API documentation or schema (Swagger, OpenAPI, GraphQL) is reachable",
+		Level:       "note",
+		pattern:     regexp.MustCompile(`(?i)(swagger|openapi|api-docs|graphql|graphiql)`),
+	},
+	{
+		ID:          "admin-interface",
+		Name:        "Administrative interface",
+		Description: "An administrative or management interface is reachable",
+		Level:       "note",
+		pattern:     regexp.MustCompile(`(?i)/(admin|administrator|manager|phpmyadmin|wp-admin|console|dashboard)(/|$)`),
+	},
+}
+
+// DefaultRule applies to findings no other rule matches
+var DefaultRule = Rule{
+	ID:          "discovered-endpoint",
+	Name:        "Discovered endpoint",
+	Description: "Content discovery found a reachable URL",
+	Level:       "note",
+}
+
+// Confirmed reports whether the server sent the content of a finding: a
+// 2xx status. A 401, 403 or redirect only shows the path exists.
+func Confirmed(f Finding) bool {
+	return f.Status >= 200 && f.Status < 300
+}
+
+// Classify returns the rule that describes a finding. Findings that are not
+// Confirmed are only notes, whatever the level of their rule.
+func Classify(f Finding) Rule {
+	path := f.Path
+	if path == "" {
+		path = urlPath(f.URL)
+	}
+
+	rule := DefaultRule
+	for _, r := range Rules {
+		if r.pattern.MatchString(path) {
+			rule = r
+			break
+		}
+	}
+	if !Confirmed(f) {
+		rule.Level = "note"
+	}
+	return rule
+}
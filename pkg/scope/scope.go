@@ -0,0 +1,297 @@
+package scope
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule matches targets by scheme, host, port and path prefix.
+//
+// Accepted forms:
+//
+//	example.com           exact host
+//	*.example.com         any subdomain of example.com (not example.com itself)
+//	*                     any host
+//	10.0.0.0/24           any IP in the range
+//	[2001:db8::1]:8443    host on a specific port
+//	example.com/api       host with a path prefix: /api and /api/..., not /apiv2
+//	https://example.com   host over https only
+//	*.example.com:443/v2  all of the above combined
+type Rule struct {
+	Raw        string
+	Scheme     string     // http or https, "" = either
+	Host       string     // Exact host, or the parent domain for wildcards
+	Wildcard   bool       // Host is "*.<Host>"
+	AnyHost    bool       // Host is "*"
+	Network    *net.IPNet // Set for CIDR rules
+	Port       int        // 0 = any port
+	PathPrefix string     // "" = any path
+}
+
+// Scope decides which targets may be scanned
+type Scope struct {
+	Include []Rule
+	Exclude []Rule
+}
+
+// scopeFile is the format of a --scope file, the same as the scope section
+// of config.yaml
+type scopeFile struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+}
+
+// New parses include and exclude rules into a scope
+func New(include, exclude []string) (*Scope, error) {
+	s := &Scope{}
+	for _, raw := range include {
+		rule, err := ParseRule(raw)
+		if err != nil {
+			return nil, fmt.Errorf("scope include: %w", err)
+		}
+		s.Include = append(s.Include, rule)
+	}
+	for _, raw := range exclude {
+		rule, err := ParseRule(raw)
+		if err != nil {
+			return nil, fmt.Errorf("scope exclude: %w", err)
+		}
+		s.Exclude = append(s.Exclude, rule)
+	}
+	return s, nil
+}
+
+// LoadFile reads a scope file with include and exclude lists
+func LoadFile(path string) (*Scope, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file scopeFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error parsing scope file: %w", err)
+	}
+
+	return New(file.Include, file.Exclude)
+}
+
+// IsEmpty reports whether the scope has no rules at all
+func (s *Scope) IsEmpty() bool {
+	return len(s.Include) == 0 && len(s.Exclude) == 0
+}
+
+// Check reports whether a target is in scope, and if not, why.
+// Exclude rules win over include rules. Without include rules every target
+// that is not excluded is in scope.
+func (s *Scope) Check(target string) (bool, string) {
+	scheme, host, port, path, err := splitTarget(target)
+	if err != nil {
+		return false, fmt.Sprintf("invalid target: %v", err)
+	}
+
+	for _, rule := range s.Exclude {
+		if rule.Match(scheme, host, port, path) {
+			return false, fmt.Sprintf("matches exclude rule %q", rule.Raw)
+		}
+	}
+
+	if len(s.Include) == 0 {
+		return true, ""
+	}
+
+	for _, rule := range s.Include {
+		if rule.Match(scheme, host, port, path) {
+			return true, ""
+		}
+	}
+
+	return false, "matches no include rule"
+}
+
+// ParseRule parses a single scope rule
+func ParseRule(raw string) (Rule, error) {
+	rule := Rule{Raw: raw}
+
+	rest := strings.TrimSpace(raw)
+	if idx := strings.Index(rest, "://"); idx != -1 {
+		rule.Scheme = strings.ToLower(rest[:idx])
+		if rule.Scheme != "http" && rule.Scheme != "https" {
+			return rule, fmt.Errorf("unsupported scheme in rule %q (use http or https)", raw)
+		}
+		rest = rest[idx+3:]
+	}
+	if rest == "" {
+		return rule, fmt.Errorf("empty rule")
+	}
+
+	// CIDR ranges contain a slash, so they have to be recognised before the
+	// path prefix is split off
+	var hostPort string
+	if cidr, network, err := parseCIDRPrefix(rest); err == nil {
+		rule.Network = network
+		hostPort, rule.PathPrefix = splitPath(rest[len(cidr):])
+	} else {
+		hostPort, rule.PathPrefix = splitPath(rest)
+	}
+
+	host, port, err := splitHostPort(hostPort)
+	if err != nil {
+		return rule, fmt.Errorf("rule %q: %w", raw, err)
+	}
+	rule.Host = strings.ToLower(host)
+	rule.Port = port
+
+	switch {
+	case rule.Network != nil:
+	case host == "*":
+		rule.AnyHost = true
+	case strings.HasPrefix(rule.Host, "*."):
+		rule.Wildcard = true
+		rule.Host = rule.Host[2:]
+	case strings.Contains(rule.Host, "*"):
+		return rule, fmt.Errorf("unsupported wildcard in rule %q (use *.domain)", raw)
+	case rule.Host == "":
+		return rule, fmt.Errorf("no host in rule %q", raw)
+	}
+
+	return rule, nil
+}
+
+// splitPath splits "host:port/path" into "host:port" and "/path"
+func splitPath(s string) (string, string) {
+	if idx := strings.Index(s, "/"); idx != -1 {
+		return s[:idx], s[idx:]
+	}
+	return s, ""
+}
+
+// splitHostPort splits an optional ":port" suffix off a host. IPv6 hosts
+// must be bracketed when a port is given.
+func splitHostPort(s string) (string, int, error) {
+	idx := strings.LastIndex(s, ":")
+	if idx == -1 || strings.Contains(s[idx:], "]") || strings.Count(s, ":") > 1 && !strings.HasPrefix(s, "[") {
+		return strings.Trim(s, "[]"), 0, nil
+	}
+
+	port, err := strconv.Atoi(s[idx+1:])
+	if err != nil || port < 1 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid port %q", s[idx+1:])
+	}
+
+	return strings.Trim(s[:idx], "[]"), port, nil
+}
+
+// parseCIDRPrefix parses a CIDR range at the start of s and returns the
+// CIDR text that was consumed
+func parseCIDRPrefix(s string) (string, *net.IPNet, error) {
+	slash := strings.Index(s, "/")
+	if slash == -1 {
+		return "", nil, fmt.Errorf("not a CIDR")
+	}
+
+	end := slash + 1
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+
+	cidr := s[:end]
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", nil, err
+	}
+	return cidr, network, nil
+}
+
+// Match reports whether the rule covers the given scheme, host, port and
+// path
+func (r Rule) Match(scheme, host string, port int, path string) bool {
+	if r.Scheme != "" && scheme != r.Scheme {
+		return false
+	}
+
+	switch {
+	case r.Network != nil:
+		ip := net.ParseIP(host)
+		if ip == nil || !r.Network.Contains(ip) {
+			return false
+		}
+	case r.AnyHost:
+	case r.Wildcard:
+		if !strings.HasSuffix(host, "."+r.Host) {
+			return false
+		}
+	default:
+		if host != r.Host && !sameIP(host, r.Host) {
+			return false
+		}
+	}
+
+	if r.Port != 0 && port != r.Port {
+		return false
+	}
+
+	if r.PathPrefix != "" && !pathWithin(path, r.PathPrefix) {
+		return false
+	}
+
+	return true
+}
+
+// sameIP reports whether a and b are the same IP address written
+// differently, e.g. 2001:db8::1 and 2001:DB8:0::1
+func sameIP(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	return ipA != nil && ipB != nil && ipA.Equal(ipB)
+}
+
+// pathWithin reports whether path is prefix or below it. Prefixes end at a
+// segment boundary, so /api covers /api/v1 but not /apiv2.
+func pathWithin(path, prefix string) bool {
+	if path == prefix {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/")
+}
+
+// splitTarget returns the scheme, lower-cased host, effective port and path
+// of a target. Targets without a scheme are http, as they are scanned.
+func splitTarget(target string) (string, string, int, string, error) {
+	raw := target
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", 0, "", err
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return "", "", 0, "", fmt.Errorf("no host in %q", target)
+	}
+
+	port := 80
+	if strings.EqualFold(u.Scheme, "https") {
+		port = 443
+	}
+	if p := u.Port(); p != "" {
+		if port, err = strconv.Atoi(p); err != nil {
+			return "", "", 0, "", err
+		}
+	}
+
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	return strings.ToLower(u.Scheme), host, port, path, nil
+}
@@ -0,0 +1,90 @@
+package scope
+
+import "testing"
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		target  string
+		want    bool
+	}{
+		{"no rules", nil, nil, "https://anything.test", true},
+
+		{"exact host", []string{"example.com"}, nil, "https://example.com/x", true},
+		{"exact host is case insensitive", []string{"Example.COM"}, nil, "https://EXAMPLE.com", true},
+		{"exact host is not a subdomain", []string{"example.com"}, nil, "https://www.example.com", false},
+		{"bare target", []string{"example.com"}, nil, "example.com", true},
+
+		{"wildcard covers subdomains", []string{"*.example.com"}, nil, "https://a.b.example.com", true},
+		{"wildcard does not cover the apex", []string{"*.example.com"}, nil, "https://example.com", false},
+		{"wildcard does not cover lookalikes", []string{"*.example.com"}, nil, "https://badexample.com", false},
+		{"wildcard and apex", []string{"*.example.com", "example.com"}, nil, "https://example.com", true},
+		{"any host", []string{"*"}, nil, "https://whatever.test", true},
+
+		{"port matches", []string{"example.com:8443"}, nil, "https://example.com:8443/", true},
+		{"port differs", []string{"example.com:8443"}, nil, "https://example.com/", false},
+		{"default https port", []string{"example.com:443"}, nil, "https://example.com", true},
+		{"default http port", []string{"example.com:80"}, nil, "example.com", true},
+
+		{"ipv4 range", []string{"10.0.0.0/24"}, nil, "http://10.0.0.7:8080", true},
+		{"outside ipv4 range", []string{"10.0.0.0/24"}, nil, "http://10.0.1.7", false},
+		{"hostname is not in an ip range", []string{"10.0.0.0/24"}, nil, "http://ten.example.com", false},
+		{"ipv6 range", []string{"2001:db8::/32"}, nil, "http://[2001:db8::5]", true},
+		{"outside ipv6 range", []string{"2001:db8::/32"}, nil, "http://[2001:db9::5]", false},
+		{"ip range with path", []string{"10.0.0.0/24/admin"}, nil, "http://10.0.0.7/admin/x", true},
+
+		{"bracketed ipv6", []string{"[2001:db8::1]"}, nil, "http://[2001:db8::1]:8080", true},
+		{"bracketed ipv6 with port", []string{"[2001:db8::1]:8080"}, nil, "http://[2001:db8::1]:8080", true},
+		{"bracketed ipv6 other port", []string{"[2001:db8::1]:8080"}, nil, "http://[2001:db8::1]", false},
+		{"ipv6 written differently", []string{"[2001:DB8:0::1]"}, nil, "http://[2001:db8::1]", true},
+		{"other ipv6", []string{"[2001:db8::1]"}, nil, "http://[2001:db8::2]", false},
+
+		{"path itself", []string{"example.com/api"}, nil, "https://example.com/api", true},
+		{"path below", []string{"example.com/api"}, nil, "https://example.com/api/v1", true},
+		{"path sibling", []string{"example.com/api"}, nil, "https://example.com/apiv2", false},
+		{"path with slash", []string{"example.com/api/"}, nil, "https://example.com/api/v1", true},
+		{"path with slash sibling", []string{"example.com/api/"}, nil, "https://example.com/apiv2", false},
+		{"path root", []string{"example.com/api"}, nil, "https://example.com", false},
+
+		{"https rule, https target", []string{"https://example.com"}, nil, "https://example.com", true},
+		{"https rule, http target", []string{"https://example.com"}, nil, "http://example.com", false},
+		{"http rule, bare target", []string{"http://example.com"}, nil, "example.com", true},
+		{"scheme is case insensitive", []string{"HTTPS://example.com"}, nil, "https://example.com", true},
+
+		{"exclude wins", []string{"*.example.com"}, []string{"admin.example.com"}, "https://admin.example.com", false},
+		{"exclude other host", []string{"*.example.com"}, []string{"admin.example.com"}, "https://www.example.com", true},
+		{"exclude without include", nil, []string{"10.0.0.0/24"}, "http://10.0.0.1", false},
+		{"exclude path", []string{"example.com"}, []string{"example.com/logout"}, "https://example.com/logout/now", false},
+		{"exclude path sibling", []string{"example.com"}, []string{"example.com/logout"}, "https://example.com/logouts", true},
+		{"exclude scheme", []string{"example.com"}, []string{"http://example.com"}, "http://example.com", false},
+		{"exclude scheme other", []string{"example.com"}, []string{"http://example.com"}, "https://example.com", true},
+	}
+
+	for _, tt := range tests {
+		s, err := New(tt.include, tt.exclude)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got, reason := s.Check(tt.target); got != tt.want {
+			t.Errorf("%s: Check(%q) = %v (%s), want %v", tt.name, tt.target, got, reason, tt.want)
+		}
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"ftp://example.com",
+		"ex*ample.com",
+		"example.com:0",
+		"example.com:http",
+		":8080",
+	} {
+		if _, err := ParseRule(raw); err == nil {
+			t.Errorf("ParseRule(%q) succeeded", raw)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bc0d3/trident-recon/pkg/utils"
+)
+
+// EnvPrefix starts the environment variables secrets are delivered in, e.g.
+// TRIDENT_SECRET_SESSION_COOKIE for the secret session_cookie
+const EnvPrefix = "TRIDENT_SECRET_"
+
+// namePattern matches secret names usable as {SECRET-name}
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Ref tells where a secret is read from. It never holds the value itself,
+// so it is safe to store in the config and in session state.
+type Ref struct {
+	Env  string `yaml:"env" json:"env,omitempty"`   // Environment variable holding the value
+	File string `yaml:"file" json:"file,omitempty"` // File holding the value, mode 0600 or stricter
+}
+
+// Validate checks that exactly one source is set
+func (r Ref) Validate() error {
+	switch {
+	case r.Env != "" && r.File != "":
+		return fmt.Errorf("set either env or file, not both")
+	case r.Env == "" && r.File == "":
+		return fmt.Errorf("env or file is required")
+	}
+	return nil
+}
+
+// String describes the source for humans, e.g. "env H1_COOKIE"
+func (r Ref) String() string {
+	if r.Env != "" {
+		return "env " + r.Env
+	}
+	return "file " + r.File
+}
+
+// Resolve reads the secret value. Files readable by group or others are
+// refused, and a trailing newline is dropped.
+func (r Ref) Resolve() (string, error) {
+	if err := r.Validate(); err != nil {
+		return "", err
+	}
+
+	if r.Env != "" {
+		value := os.Getenv(r.Env)
+		if value == "" {
+			return "", fmt.Errorf("environment variable %s is not set", r.Env)
+		}
+		return value, nil
+	}
+
+	path := utils.ExpandPath(r.File)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return "", fmt.Errorf("%s is accessible by group or others (run chmod 600 %s)", path, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return value, nil
+}
+
+// ValidateName checks that name can be used as {SECRET-name}
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q (use letters, digits, '_', '.' and '-')", name)
+	}
+	return nil
+}
+
+// EnvName returns the environment variable a secret is delivered in
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name))
+}
+
+// Placeholder is what {SECRET-name} renders to: a reference to the secret's
+// environment variable, expanded by the shell inside the session. Use it
+// unquoted or inside double quotes.
+func Placeholder(name string) string {
+	return "${" + EnvName(name) + "}"
+}
+
+// EnvFilePath returns where the secrets of a session are staged until the
+// session reads them
+func EnvFilePath(stateDir, id string) string {
+	return filepath.Join(stateDir, "secrets", id+".env")
+}
+
+// RedactFilePath returns where the secret values of a session are staged
+// for the filter that redacts its pane log
+func RedactFilePath(stateDir, id string) string {
+	return filepath.Join(stateDir, "secrets", id+".redact")
+}
+
+// HeadersFileEnv is the environment variable that holds the path of a
+// session's headers file, rendered for {HEADERS-FILE}
+const HeadersFileEnv = "TRIDENT_HEADERS_FILE"
+
+// HeadersFilePath returns where the headers of a session are written, with
+// secrets filled in, for tools that read headers from a file
+func HeadersFilePath(stateDir, id string) string {
+	return filepath.Join(stateDir, "secrets", id+".headers")
+}
+
+// WriteHeadersFile writes headers, one "Name: value" per line, to a file
+// only the current user can read
+func WriteHeadersFile(path string, lines []string) error {
+	var b strings.Builder
+	for _, line := range lines {
+		if strings.ContainsAny(line, "\r\n") {
+			name, _, _ := strings.Cut(line, ":")
+			return fmt.Errorf("header %s spans several lines", name)
+		}
+		b.WriteString(line + "\n")
+	}
+	return writePrivate(path, []byte(b.String()))
+}
+
+// WriteEnvFile stages environment variables for a session in a file only
+// the current user can read. The session sources and deletes it on start.
+func WriteEnvFile(path string, env map[string]string) error {
+	var b strings.Builder
+	for name, value := range env {
+		fmt.Fprintf(&b, "export %s=%s\n", name, utils.ShellQuote(value))
+	}
+	return writePrivate(path, []byte(b.String()))
+}
+
+// WriteValuesFile stages secret values for the log filter in a file only
+// the current user can read
+func WriteValuesFile(path string, values []string) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return writePrivate(path, data)
+}
+
+// ReadValuesFile reads and removes a file written by WriteValuesFile
+func ReadValuesFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(path)
+
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// writePrivate writes a file readable only by the current user
+func writePrivate(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// Start from a fresh file so a leftover with looser permissions is not reused
+	os.Remove(path)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
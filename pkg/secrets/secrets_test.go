@@ -0,0 +1,140 @@
+package secrets
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResolveEnv(t *testing.T) {
+	t.Setenv("TRIDENT_TEST_TOKEN", "s3cret")
+	if value, err := (Ref{Env: "TRIDENT_TEST_TOKEN"}).Resolve(); err != nil || value != "s3cret" {
+		t.Errorf("Resolve() = %q, %v", value, err)
+	}
+
+	t.Setenv("TRIDENT_TEST_TOKEN", "")
+	if _, err := (Ref{Env: "TRIDENT_TEST_TOKEN"}).Resolve(); err == nil {
+		t.Error("Resolve() accepted an unset variable")
+	}
+}
+
+func TestResolveFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string, mode os.FileMode) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	private := write("private", "token value\n", 0600)
+	if value, err := (Ref{File: private}).Resolve(); err != nil || value != "token value" {
+		t.Errorf("Resolve() = %q, %v, want the value without the newline", value, err)
+	}
+
+	for name, mode := range map[string]os.FileMode{"group": 0640, "world": 0604, "open": 0644} {
+		path := write(name, "token", mode)
+		if _, err := (Ref{File: path}).Resolve(); err == nil {
+			t.Errorf("Resolve() accepted a file with mode %o", mode)
+		}
+	}
+
+	if _, err := (Ref{File: write("empty", "\n", 0600)}).Resolve(); err == nil {
+		t.Error("Resolve() accepted an empty file")
+	}
+	if _, err := (Ref{File: filepath.Join(dir, "missing")}).Resolve(); err == nil {
+		t.Error("Resolve() accepted a missing file")
+	}
+}
+
+func TestRefValidate(t *testing.T) {
+	if err := (Ref{Env: "A", File: "b"}).Validate(); err == nil {
+		t.Error("Validate() accepted both env and file")
+	}
+	if err := (Ref{}).Validate(); err == nil {
+		t.Error("Validate() accepted neither env nor file")
+	}
+}
+
+func TestWriteEnvFileQuoting(t *testing.T) {
+	dir := t.TempDir()
+	pwned := filepath.Join(dir, "pwned")
+	env := map[string]string{
+		"TRIDENT_SECRET_A": "it's; touch " + pwned,
+		"TRIDENT_SECRET_B": "$(touch " + pwned + ")",
+		"TRIDENT_SECRET_C": "`touch " + pwned + "` ${HOME} \"quoted\" back\\slash",
+		"TRIDENT_SECRET_D": "new\nline\ttab '",
+	}
+	path := EnvFilePath(dir, "abc")
+	if err := WriteEnvFile(path, env); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("env file has mode %o, want 600", info.Mode().Perm())
+	}
+
+	for name, want := range env {
+		output, err := exec.Command("sh", "-c", `. "$1"; printf '%s' "$(eval "printf '%s' \"\${$2}\"")"`, "sh", path, name).Output()
+		if err != nil {
+			t.Fatalf("sourcing the env file: %v", err)
+		}
+		if string(output) != want {
+			t.Errorf("%s = %q, want %q", name, output, want)
+		}
+	}
+	if _, err := os.Stat(pwned); err == nil {
+		t.Error("sourcing the env file ran a command from a value")
+	}
+}
+
+func TestValuesFile(t *testing.T) {
+	path := RedactFilePath(t.TempDir(), "abc")
+	values := []string{"one", "two \"quoted\"\n"}
+	if err := WriteValuesFile(path, values); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadValuesFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, values) {
+		t.Errorf("ReadValuesFile() = %q, want %q", got, values)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("ReadValuesFile() left the file behind")
+	}
+}
+
+func TestWriteHeadersFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets", "abc.headers")
+	if err := WriteHeadersFile(path, []string{"Accept: */*", "Cookie: sid=a;b"}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %o, want 600", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(path); string(data) != "Accept: */*\nCookie: sid=a;b\n" {
+		t.Errorf("content = %q", data)
+	}
+
+	// A value with a line break would smuggle in another header
+	if err := WriteHeadersFile(path, []string{"Cookie: a\r\nX-Admin: 1"}); err == nil {
+		t.Error("WriteHeadersFile accepted a header spanning several lines")
+	}
+}
@@ -0,0 +1,54 @@
+package targets
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type httpxRecord struct {
+	URL      string `json:"url"`
+	FinalURL string `json:"final_url"`
+	Failed   bool   `json:"failed"`
+}
+
+// loadHttpxJSONL reads httpx -json output. The final URL after redirects is
+// used when httpx recorded one, so the scheme and host are the ones that
+// actually answered.
+func loadHttpxJSONL(r io.Reader) ([]Target, error) {
+	var targets []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var record httpxRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		if record.Failed {
+			continue
+		}
+
+		target := record.FinalURL
+		if target == "" {
+			target = record.URL
+		}
+		if target == "" {
+			continue
+		}
+		targets = append(targets, target)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return fromURLs(targets), nil
+}
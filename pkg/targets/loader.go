@@ -0,0 +1,186 @@
+package targets
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Input formats understood by Load
+const (
+	FormatAuto     = "auto"
+	FormatURLs     = "urls"
+	FormatHostPort = "hostport"
+	FormatCIDR     = "cidr"
+	FormatNmap     = "nmap"
+	FormatHttpx    = "httpx"
+)
+
+// Target is a target URL with the variables set on its line
+type Target struct {
+	URL  string
+	Vars map[string]string // From "vars: name=value ..." after the URL
+}
+
+// Loader reads a targets file and returns its targets
+type Loader func(r io.Reader) ([]Target, error)
+
+// loaders maps an input format to its loader
+var loaders = map[string]Loader{
+	FormatURLs:     lineLoader(parseURL),
+	FormatHostPort: lineLoader(parseHostPort),
+	FormatCIDR:     lineLoader(parseCIDR),
+	FormatNmap:     loadNmapXML,
+	FormatHttpx:    loadHttpxJSONL,
+}
+
+// Formats returns the names accepted by --input-format
+func Formats() []string {
+	formats := []string{FormatAuto}
+	for name := range loaders {
+		formats = append(formats, name)
+	}
+	sort.Strings(formats[1:])
+	return formats
+}
+
+// Load reads targets from a file in the given format. With FormatAuto the
+// format is detected from the content, and plain text lines may mix URLs,
+// host:port entries and CIDR ranges.
+func Load(path, format string) ([]Target, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "" || format == FormatAuto {
+		format = DetectFormat(data)
+	}
+
+	var loader Loader
+	if format == FormatAuto {
+		loader = lineLoader(ParseLine)
+	} else {
+		var ok bool
+		if loader, ok = loaders[format]; !ok {
+			return nil, fmt.Errorf("unknown input format %q (use %s)", format, strings.Join(Formats(), ", "))
+		}
+	}
+
+	targets, err := loader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s input: %w", format, err)
+	}
+
+	return dedupe(targets), nil
+}
+
+// DetectFormat recognises nmap XML and httpx JSON Lines by their first
+// non-blank character. Anything else is FormatAuto, meaning each line is
+// classified on its own.
+func DetectFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return FormatNmap
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return FormatHttpx
+	default:
+		return FormatAuto
+	}
+}
+
+// ParseLine turns a single target entry into target URLs, whatever its
+// form: a URL, a bare host, host:port or a CIDR range
+func ParseLine(line string) ([]string, error) {
+	switch {
+	case strings.Contains(line, "://"):
+		return parseURL(line)
+	case strings.Contains(line, "/") && isCIDR(line):
+		return parseCIDR(line)
+	case isHostPort(line):
+		return parseHostPort(line)
+	default:
+		return parseURL(line)
+	}
+}
+
+// lineLoader builds a loader that parses a file line by line, skipping blank
+// lines and comments. A line may end with "vars: name=value ..." to set
+// variables for the targets on it.
+func lineLoader(parse func(string) ([]string, error)) Loader {
+	return func(r io.Reader) ([]Target, error) {
+		var targets []Target
+		scanner := bufio.NewScanner(r)
+		lineNum := 0
+		for scanner.Scan() {
+			lineNum++
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			line, vars, err := splitVars(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+
+			parsed, err := parse(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			for _, url := range parsed {
+				targets = append(targets, Target{URL: url, Vars: vars})
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return targets, nil
+	}
+}
+
+// parseURL accepts a URL or bare host as is
+func parseURL(line string) ([]string, error) {
+	if strings.ContainsAny(line, " \t") {
+		return nil, fmt.Errorf("invalid target %q", line)
+	}
+	return []string{line}, nil
+}
+
+// URLs returns the URLs of targets
+func URLs(targets []Target) []string {
+	urls := make([]string, len(targets))
+	for i, target := range targets {
+		urls[i] = target.URL
+	}
+	return urls
+}
+
+// fromURLs wraps plain URLs as targets without variables
+func fromURLs(urls []string) []Target {
+	targets := make([]Target, len(urls))
+	for i, url := range urls {
+		targets[i] = Target{URL: url}
+	}
+	return targets
+}
+
+// dedupe removes repeated target URLs, keeping the first occurrence
+func dedupe(targets []Target) []Target {
+	seen := make(map[string]bool)
+	var unique []Target
+	for _, target := range targets {
+		if seen[target.URL] {
+			continue
+		}
+		seen[target.URL] = true
+		unique = append(unique, target)
+	}
+	return unique
+}
@@ -0,0 +1,246 @@
+package targets
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// load writes content to a targets file and loads it in the given format
+func load(t *testing.T, content, format string) ([]Target, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "targets")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return Load(path, format)
+}
+
+func TestParseCIDR(t *testing.T) {
+	tests := []struct {
+		cidr string
+		want []string
+	}{
+		{"10.0.0.0/30", []string{"http://10.0.0.1", "http://10.0.0.2"}},
+		{"10.0.0.4/31", []string{"http://10.0.0.4", "http://10.0.0.5"}},
+		{"10.0.0.7/32", []string{"http://10.0.0.7"}},
+		{"2001:db8::/127", []string{"http://[2001:db8::]", "http://[2001:db8::1]"}},
+	}
+	for _, tt := range tests {
+		got, err := parseCIDR(tt.cidr)
+		if err != nil {
+			t.Errorf("parseCIDR(%q): %v", tt.cidr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseCIDR(%q) = %v, want %v", tt.cidr, got, tt.want)
+		}
+	}
+
+	for _, cidr := range []string{"10.0.0.0/8", "10.0.0.0", "10.0.0.0/33"} {
+		if _, err := parseCIDR(cidr); err == nil {
+			t.Errorf("parseCIDR(%q) succeeded", cidr)
+		}
+	}
+}
+
+func TestParseHostPort(t *testing.T) {
+	tests := map[string]string{
+		"example.com:80":       "http://example.com",
+		"example.com:8080":     "http://example.com:8080",
+		"example.com:443":      "https://example.com",
+		"example.com:8443":     "https://example.com:8443",
+		"10.0.0.1:9443":        "https://10.0.0.1:9443",
+		"[2001:db8::1]:8080":   "http://[2001:db8::1]:8080",
+		"[2001:db8::1]:443":    "https://[2001:db8::1]",
+		"example.com:0":        "",
+		"example.com:70000":    "",
+		"example.com:http":     "",
+		"2001:db8::1":          "",
+		"example.com:8080:443": "",
+	}
+	for line, want := range tests {
+		got, err := parseHostPort(line)
+		if want == "" {
+			if err == nil {
+				t.Errorf("parseHostPort(%q) = %v, want an error", line, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseHostPort(%q): %v", line, err)
+			continue
+		}
+		if len(got) != 1 || got[0] != want {
+			t.Errorf("parseHostPort(%q) = %v, want %s", line, got, want)
+		}
+	}
+}
+
+func TestLoadAuto(t *testing.T) {
+	content := `# mixed targets
+https://a.example.com
+b.example.com
+c.example.com:8443
+10.0.0.0/30
+
+https://a.example.com
+`
+	got, err := load(t, content, FormatAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"https://a.example.com",
+		"b.example.com",
+		"https://c.example.com:8443",
+		"http://10.0.0.1",
+		"http://10.0.0.2",
+	}
+	if !reflect.DeepEqual(URLs(got), want) {
+		t.Errorf("Load = %v, want %v", URLs(got), want)
+	}
+
+	if _, err := load(t, "a.example.com\n10.0.0.0/8\n", FormatAuto); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Load of a too large range = %v, want an error on line 2", err)
+	}
+	if _, err := load(t, "a.example.com\n", "csv"); err == nil {
+		t.Error("Load accepted an unknown format")
+	}
+}
+
+const nmapXML = `<?xml version="1.0"?>
+<nmaprun>
+  <host>
+    <address addr="10.0.0.5" addrtype="ipv4"/>
+    <hostnames><hostname name="app.example.com" type="user"/><hostname name="x.internal" type="PTR"/></hostnames>
+    <ports>
+      <port protocol="tcp" portid="80"><state state="open"/><service name="http"/></port>
+      <port protocol="tcp" portid="8443"><state state="open"/><service name="http" tunnel="ssl"/></port>
+      <port protocol="tcp" portid="22"><state state="open"/><service name="ssh"/></port>
+      <port protocol="tcp" portid="8080"><state state="closed"/><service name="http-proxy"/></port>
+      <port protocol="udp" portid="80"><state state="open"/><service name="http"/></port>
+    </ports>
+  </host>
+  <host>
+    <address addr="2001:db8::5" addrtype="ipv6"/>
+    <address addr="00:11:22:33:44:55" addrtype="mac"/>
+    <ports>
+      <port protocol="tcp" portid="443"><state state="open"/><service name="https"/></port>
+      <port protocol="tcp" portid="8000"><state state="open"/><service name="http-alt"/></port>
+    </ports>
+  </host>
+  <host>
+    <address addr="00:11:22:33:44:66" addrtype="mac"/>
+    <ports><port protocol="tcp" portid="80"><state state="open"/><service name="http"/></port></ports>
+  </host>
+</nmaprun>
+`
+
+func TestLoadNmapXML(t *testing.T) {
+	got, err := load(t, nmapXML, FormatAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"http://app.example.com",
+		"https://app.example.com:8443",
+		"https://[2001:db8::5]",
+		"http://[2001:db8::5]:8000",
+	}
+	if !reflect.DeepEqual(URLs(got), want) {
+		t.Errorf("Load = %v, want %v", URLs(got), want)
+	}
+
+	if _, err := load(t, "<nmaprun><host>", FormatNmap); err == nil {
+		t.Error("Load accepted truncated XML")
+	}
+}
+
+func TestLoadHttpxJSONL(t *testing.T) {
+	content := `{"url":"http://a.example.com","final_url":"https://a.example.com/login","status_code":302}
+{"url":"https://b.example.com","status_code":200}
+
+{"url":"https://c.example.com","failed":true}
+{"input":"d.example.com"}
+{"url":"https://b.example.com","status_code":200}
+`
+	got, err := load(t, content, FormatAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"https://a.example.com/login", "https://b.example.com"}
+	if !reflect.DeepEqual(URLs(got), want) {
+		t.Errorf("Load = %v, want %v", URLs(got), want)
+	}
+
+	if _, err := load(t, "{\"url\":\"https://a.example.com\"}\n{not json\n", FormatHttpx); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Load of invalid JSON = %v, want an error on line 2", err)
+	}
+}
+
+func TestSplitVars(t *testing.T) {
+	tests := []struct {
+		line    string
+		target  string
+		vars    map[string]string
+		wantErr bool
+	}{
+		{line: "https://a.com", target: "https://a.com"},
+		{
+			line:   `https://a.com  vars: cookie="sid=abc; theme=dark" user=bob`,
+			target: "https://a.com",
+			vars:   map[string]string{"cookie": "sid=abc; theme=dark", "user": "bob"},
+		},
+		{
+			line:   "10.0.0.0/30\tvars: env=staging",
+			target: "10.0.0.0/30",
+			vars:   map[string]string{"env": "staging"},
+		},
+		{
+			line:   `a.com vars: empty= quoted="a \"b\""`,
+			target: "a.com",
+			vars:   map[string]string{"empty": "", "quoted": `a "b"`},
+		},
+		{line: "a.com vars:", wantErr: true},
+		{line: "a.com vars: novalue", wantErr: true},
+		{line: `a.com vars: x="unterminated`, wantErr: true},
+		{line: `a.com vars: x="a"b`, wantErr: true},
+	}
+	for _, tt := range tests {
+		target, vars, err := splitVars(tt.line)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("splitVars(%q) succeeded", tt.line)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("splitVars(%q): %v", tt.line, err)
+			continue
+		}
+		if target != tt.target || !reflect.DeepEqual(vars, tt.vars) {
+			t.Errorf("splitVars(%q) = %q, %v, want %q, %v", tt.line, target, vars, tt.target, tt.vars)
+		}
+	}
+}
+
+func TestLoadVars(t *testing.T) {
+	got, err := load(t, "10.0.0.0/30 vars: env=lab\nb.example.com\n", FormatAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("Load returned %d targets, want 3", len(got))
+	}
+	for _, target := range got[:2] {
+		if target.Vars["env"] != "lab" {
+			t.Errorf("%s has vars %v, want env=lab", target.URL, target.Vars)
+		}
+	}
+	if got[2].Vars != nil {
+		t.Errorf("%s has vars %v, want none", got[2].URL, got[2].Vars)
+	}
+}
@@ -0,0 +1,103 @@
+package targets
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// MaxCIDRHosts caps how many hosts a single CIDR range may expand to
+const MaxCIDRHosts = 65536
+
+// tlsPorts are ports assumed to speak HTTPS when no scheme is given
+var tlsPorts = map[int]bool{443: true, 8443: true, 9443: true}
+
+// parseCIDR expands a CIDR range into one http:// target per host. Network
+// and broadcast addresses are skipped for IPv4 ranges larger than /31.
+func parseCIDR(line string) ([]string, error) {
+	ip, network, err := net.ParseCIDR(line)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q", line)
+	}
+
+	ones, bits := network.Mask.Size()
+	if bits-ones > 16 {
+		return nil, fmt.Errorf("CIDR %s is too large (more than %d hosts)", line, MaxCIDRHosts)
+	}
+
+	skipEdges := ip.To4() != nil && bits-ones > 1
+
+	var hosts []string
+	current := make(net.IP, len(network.IP))
+	copy(current, network.IP)
+	for ; network.Contains(current); incrementIP(current) {
+		hosts = append(hosts, hostPortURL(current.String(), 0, false))
+	}
+
+	if skipEdges && len(hosts) > 2 {
+		hosts = hosts[1 : len(hosts)-1]
+	}
+	return hosts, nil
+}
+
+// incrementIP adds one to an IP address in place
+func incrementIP(ip net.IP) {
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]++
+		if ip[i] != 0 {
+			return
+		}
+	}
+}
+
+// isCIDR reports whether a line is a CIDR range
+func isCIDR(line string) bool {
+	_, _, err := net.ParseCIDR(line)
+	return err == nil
+}
+
+// isHostPort reports whether a line is a bare host:port entry
+func isHostPort(line string) bool {
+	host, port, err := net.SplitHostPort(line)
+	if err != nil || host == "" {
+		return false
+	}
+	_, err = strconv.Atoi(port)
+	return err == nil
+}
+
+// parseHostPort turns host:port into a URL, guessing the scheme from the port
+func parseHostPort(line string) ([]string, error) {
+	host, portStr, err := net.SplitHostPort(line)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host:port %q: %w", line, err)
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return nil, fmt.Errorf("invalid port in %q", line)
+	}
+
+	return []string{hostPortURL(host, port, tlsPorts[port])}, nil
+}
+
+// hostPortURL builds a target URL, leaving out the port when it is the
+// default for the scheme
+func hostPortURL(host string, port int, tls bool) string {
+	scheme := "http"
+	defaultPort := 80
+	if tls {
+		scheme = "https"
+		defaultPort = 443
+	}
+
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	if port == 0 || port == defaultPort {
+		return scheme + "://" + host
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, host, port)
+}
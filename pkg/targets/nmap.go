@@ -0,0 +1,95 @@
+package targets
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type nmapRun struct {
+	Hosts []nmapHost `xml:"host"`
+}
+
+type nmapHost struct {
+	Addresses []nmapAddress  `xml:"address"`
+	Hostnames []nmapHostname `xml:"hostnames>hostname"`
+	Ports     []nmapPort     `xml:"ports>port"`
+}
+
+type nmapAddress struct {
+	Addr     string `xml:"addr,attr"`
+	AddrType string `xml:"addrtype,attr"`
+}
+
+type nmapHostname struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type nmapPort struct {
+	Protocol string `xml:"protocol,attr"`
+	PortID   string `xml:"portid,attr"`
+	State    struct {
+		State string `xml:"state,attr"`
+	} `xml:"state"`
+	Service struct {
+		Name   string `xml:"name,attr"`
+		Tunnel string `xml:"tunnel,attr"`
+	} `xml:"service"`
+}
+
+// loadNmapXML reads nmap -oX output and returns a target for every open
+// port whose service is HTTP or HTTPS
+func loadNmapXML(r io.Reader) ([]Target, error) {
+	var run nmapRun
+	if err := xml.NewDecoder(r).Decode(&run); err != nil {
+		return nil, fmt.Errorf("failed to parse nmap XML: %w", err)
+	}
+
+	var targets []string
+	for _, host := range run.Hosts {
+		name := host.name()
+		if name == "" {
+			continue
+		}
+
+		for _, port := range host.Ports {
+			if port.Protocol != "tcp" || port.State.State != "open" {
+				continue
+			}
+
+			service := strings.ToLower(port.Service.Name)
+			if !strings.Contains(service, "http") {
+				continue
+			}
+
+			portNum, err := strconv.Atoi(port.PortID)
+			if err != nil {
+				continue
+			}
+
+			tls := port.Service.Tunnel == "ssl" || strings.Contains(service, "https")
+			targets = append(targets, hostPortURL(name, portNum, tls))
+		}
+	}
+
+	return fromURLs(targets), nil
+}
+
+// name returns the hostname the user scanned, falling back to the IP
+// address so virtual hosts keep working
+func (h nmapHost) name() string {
+	for _, hostname := range h.Hostnames {
+		if hostname.Type == "user" {
+			return hostname.Name
+		}
+	}
+	for _, address := range h.Addresses {
+		if address.AddrType == "ipv4" || address.AddrType == "ipv6" {
+			return address.Addr
+		}
+	}
+	return ""
+}
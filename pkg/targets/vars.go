@@ -0,0 +1,71 @@
+package targets
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// varsMarker starts the variables of a target line, e.g.
+//
+//	https://a.com  vars: cookie="sid=abc; theme=dark" user=bob
+var varsMarker = regexp.MustCompile(`\s+vars:`)
+
+// splitVars separates a target line from its variables
+func splitVars(line string) (string, map[string]string, error) {
+	loc := varsMarker.FindStringIndex(line)
+	if loc == nil {
+		return line, nil, nil
+	}
+
+	vars, err := parseVars(line[loc[1]:])
+	if err != nil {
+		return "", nil, err
+	}
+	return line[:loc[0]], vars, nil
+}
+
+// parseVars parses whitespace separated name=value pairs. Values may be
+// double quoted to hold spaces.
+func parseVars(s string) (map[string]string, error) {
+	vars := make(map[string]string)
+	s = strings.TrimSpace(s)
+	for s != "" {
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 || strings.ContainsAny(s[:eq], " \t") {
+			return nil, fmt.Errorf("invalid variable %q (expected name=value)", strings.Fields(s)[0])
+		}
+		name := s[:eq]
+		s = s[eq+1:]
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			quoted, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("variable %s: unterminated quoted value", name)
+			}
+			if value, err = strconv.Unquote(quoted); err != nil {
+				return nil, fmt.Errorf("variable %s: %w", name, err)
+			}
+			s = s[len(quoted):]
+			if s != "" && s[0] != ' ' && s[0] != '\t' {
+				return nil, fmt.Errorf("variable %s: expected a space after the quoted value", name)
+			}
+		} else {
+			end := strings.IndexAny(s, " \t")
+			if end == -1 {
+				end = len(s)
+			}
+			value, s = s[:end], s[end:]
+		}
+
+		vars[name] = value
+		s = strings.TrimSpace(s)
+	}
+
+	if len(vars) == 0 {
+		return nil, fmt.Errorf("vars: without any name=value")
+	}
+	return vars, nil
+}
@@ -2,13 +2,21 @@ package tmux
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 )
 
-// CreateSession creates a new tmux session
-func CreateSession(sessionName, command string) error {
-	cmd := exec.Command("tmux", "new-session", "-d", "-s", sessionName, "bash", "-c", command)
+// NewSessionArgs returns the tmux arguments that start argv in a new
+// detached session. With more than one element tmux runs argv directly,
+// without passing it through a shell.
+func NewSessionArgs(sessionName string, argv []string) []string {
+	return append([]string{"new-session", "-d", "-s", sessionName}, argv...)
+}
+
+// CreateSession creates a new tmux session running argv
+func CreateSession(sessionName string, argv ...string) error {
+	cmd := exec.Command("tmux", NewSessionArgs(sessionName, argv)...)
 	return cmd.Run()
 }
 
@@ -44,15 +52,26 @@ func KillSession(sessionName string) error {
 	return cmd.Run()
 }
 
-// AttachSession attaches to a tmux session
+// AttachSession attaches to a tmux session, handing over the terminal.
+// When already running inside tmux, the current client is switched instead.
 func AttachSession(sessionName string) error {
-	cmd := exec.Command("tmux", "attach-session", "-t", sessionName)
-	cmd.Stdin = nil
-	cmd.Stdout = nil
-	cmd.Stderr = nil
+	action := "attach-session"
+	if InsideTmux() {
+		action = "switch-client"
+	}
+
+	cmd := exec.Command("tmux", action, "-t", sessionName)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
+// InsideTmux reports whether the current process runs inside a tmux client
+func InsideTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
+
 // FilterSessionsByPrefix filters sessions by prefix
 func FilterSessionsByPrefix(sessions []string, prefix string) []string {
 	var filtered []string
@@ -95,3 +114,21 @@ func IsTmuxAvailable() bool {
 	cmd := exec.Command("tmux", "-V")
 	return cmd.Run() == nil
 }
+
+// PipePane sends everything printed in the session's pane to the stdin of
+// a shell command, e.g. "cat >> file"
+func PipePane(sessionName, command string) error {
+	cmd := exec.Command("tmux", "pipe-pane", "-o", "-t", sessionName, command)
+	return cmd.Run()
+}
+
+// CapturePane returns the contents of the session's pane including its
+// scrollback history
+func CapturePane(sessionName string) (string, error) {
+	cmd := exec.Command("tmux", "capture-pane", "-p", "-J", "-S", "-", "-t", sessionName)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
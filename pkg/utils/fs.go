@@ -50,7 +50,7 @@ func ReadLines(path string) ([]string, error) {
 	return lines, nil
 }
 
-// WriteFile writes content to a file
+// WriteFile writes content to a file, with secret values redacted
 func WriteFile(path, content string) error {
 	// Ensure parent directory exists
 	dir := filepath.Dir(path)
@@ -58,10 +58,39 @@ func WriteFile(path, content string) error {
 		return err
 	}
 
-	return os.WriteFile(path, []byte(content), 0644)
+	return os.WriteFile(path, []byte(Redact(content)), 0644)
 }
 
-// WriteLines writes lines to a file (one line per string)
+// WriteFileAtomic replaces a file in one step: the data is written to a
+// temporary file in the same directory, synced and renamed over path, so
+// readers see either the old or the new content, never a partial write.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// WriteLines writes lines to a file (one line per string), with secret
+// values redacted
 func WriteLines(path string, lines []string) error {
 	// Ensure parent directory exists
 	dir := filepath.Dir(path)
@@ -70,7 +99,7 @@ func WriteLines(path string, lines []string) error {
 	}
 
 	content := strings.Join(lines, "\n") + "\n"
-	return os.WriteFile(path, []byte(content), 0644)
+	return os.WriteFile(path, []byte(Redact(content)), 0644)
 }
 
 // GenerateOutputDir generates a timestamped output directory name
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// FileLock is an exclusive advisory lock on a file. Other processes taking
+// the same lock wait until it is released; the lock is also released when
+// the holder exits, so a crash never leaves it stuck.
+type FileLock struct {
+	file *os.File
+}
+
+// LockFile takes an exclusive lock on path, creating the file if needed,
+// and waits for other holders to release it. Locks are not reentrant: taking
+// the same lock twice in one process blocks.
+func LockFile(path string) (*FileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &FileLock{file: file}, nil
+}
+
+// ErrLocked is returned by TryLockFile when another process holds the lock
+var ErrLocked = errors.New("locked by another process")
+
+// TryLockFile takes an exclusive lock on path like LockFile, but fails with
+// ErrLocked instead of waiting when the lock is held
+func TryLockFile(path string) (*FileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, ErrLocked
+		}
+		return nil, err
+	}
+
+	return &FileLock{file: file}, nil
+}
+
+// File returns the locked file, e.g. to record who holds the lock
+func (l *FileLock) File() *os.File {
+	return l.file
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	defer l.file.Close()
+	return syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+}
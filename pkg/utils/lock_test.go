@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockFileWaitsForHolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.lock")
+
+	first, err := LockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan *FileLock)
+	go func() {
+		second, err := LockFile(path)
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("lock taken while held")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := first.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case second := <-acquired:
+		second.Unlock()
+	case <-time.After(2 * time.Second):
+		t.Fatal("lock not released")
+	}
+}
+
+func TestTryLockFileFailsWhenHeld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watch.pid")
+
+	first, err := TryLockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TryLockFile(path); !errors.Is(err, ErrLocked) {
+		t.Fatalf("second TryLockFile() error = %v, want ErrLocked", err)
+	}
+
+	if err := first.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	second, err := TryLockFile(path)
+	if err != nil {
+		t.Fatalf("lock not released: %v", err)
+	}
+	second.Unlock()
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces secret values in everything trident writes
+const Redacted = "[REDACTED]"
+
+var (
+	redactMu     sync.RWMutex
+	redactValues []string
+)
+
+// AddRedaction registers a secret value that Redact hides from now on. The
+// JSON and HTML escaped forms are hidden too, so state files and reports
+// are covered.
+func AddRedaction(value string) {
+	if value == "" {
+		return
+	}
+
+	forms := []string{value, html.EscapeString(value)}
+	if data, err := json.Marshal(value); err == nil {
+		forms = append(forms, string(data[1:len(data)-1]))
+	}
+
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	for _, form := range forms {
+		if !containsString(redactValues, form) {
+			redactValues = append(redactValues, form)
+		}
+	}
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact replaces every registered secret value in s
+func Redact(s string) string {
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+	for _, v := range redactValues {
+		s = strings.ReplaceAll(s, v, Redacted)
+	}
+	return s
+}
+
+// RedactWriter returns a writer that redacts each write before passing it
+// on to w. A secret split across two writes is not caught.
+func RedactWriter(w io.Writer) io.Writer {
+	return redactWriter{w: w}
+}
+
+type redactWriter struct {
+	w io.Writer
+}
+
+func (rw redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// CopyRedacted copies src to dst with secret values redacted. Output is
+// passed on a line at a time (lines may end in \r for progress bars), so a
+// secret is only missed if it is split by a line break.
+func CopyRedacted(dst io.Writer, src io.Reader) error {
+	buf := make([]byte, 32*1024)
+	var pending []byte
+	for {
+		n, err := src.Read(buf)
+		pending = append(pending, buf[:n]...)
+
+		// Flush complete lines, or everything once the line gets long
+		cut := bytes.LastIndexAny(pending, "\r\n") + 1
+		if err != nil || len(pending) > 64*1024 {
+			cut = len(pending)
+		}
+		if cut > 0 {
+			if _, werr := io.WriteString(dst, Redact(string(pending[:cut]))); werr != nil {
+				return werr
+			}
+			pending = pending[cut:]
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestRedact(t *testing.T) {
+	AddRedaction(`tok"en'1`)
+
+	for in, want := range map[string]string{
+		`Cookie: tok"en'1`:          "Cookie: " + Redacted,
+		`{"header":"tok\"en'1"}`:    `{"header":"` + Redacted + `"}`,
+		"<td>tok&#34;en&#39;1</td>": "<td>" + Redacted + "</td>",
+		"nothing secret":            "nothing secret",
+	} {
+		if got := Redact(in); got != want {
+			t.Errorf("Redact(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCopyRedacted(t *testing.T) {
+	AddRedaction("hunter2-secret")
+
+	input := "start\n" +
+		"\r:: Progress: [1/10] :: Authorization: Bearer hunter2-secret" +
+		"\r:: Progress: [2/10] :: hunter2-secret\n" +
+		"last line without newline hunter2-secret"
+
+	// One byte at a time, so every secret arrives split across reads
+	var out bytes.Buffer
+	if err := CopyRedacted(&out, iotest.OneByteReader(strings.NewReader(input))); err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.ReplaceAll(input, "hunter2-secret", Redacted)
+	if out.String() != want {
+		t.Errorf("CopyRedacted() = %q, want %q", out.String(), want)
+	}
+}
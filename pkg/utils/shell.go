@@ -0,0 +1,114 @@
+package utils
+
+import "strings"
+
+// This file is the only place shell quoting is implemented. Command
+// templates, the executor and the markdown and plain text outputs all go
+// through these functions so they agree on how a value reaches the shell.
+
+// shellSafe reports whether s can be used as a shell word without quoting
+func shellSafe(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("@%+=:,./_-", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ShellQuote quotes a string for safe use as a single shell word. Strings
+// made only of safe characters are returned as they are.
+func ShellQuote(s string) string {
+	if shellSafe(s) {
+		return s
+	}
+	return "'" + ShellEscapeSingle(s) + "'"
+}
+
+// ShellJoin quotes every argument and joins them into a command line that
+// the shell splits back into the same arguments
+func ShellJoin(args ...string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = ShellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// ShellEscapeSingle escapes s for use between single quotes
+func ShellEscapeSingle(s string) string {
+	return strings.ReplaceAll(s, "'", `'\''`)
+}
+
+// ShellEscapeDouble escapes s for use between double quotes, where $, `,
+// " and \ would otherwise be interpreted
+func ShellEscapeDouble(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("$`\"\\", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ShellSplit splits a command line into words the way the shell would,
+// removing quotes and backslash escapes. Operators such as ; and > are not
+// recognised and stay part of the words.
+func ShellSplit(s string) []string {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	quote := rune(0)
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			if quote == '"' && !strings.ContainsRune("$`\"\\\n", r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
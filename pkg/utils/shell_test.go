@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// hostileValues are strings that break commands when they are not quoted
+var hostileValues = []string{
+	"example.com",
+	"",
+	"a.com;touch /tmp/trident-pwned",
+	"$(id)",
+	"`id`",
+	"${HOME}",
+	"it's",
+	`say "hi"`,
+	`back\slash`,
+	"two words",
+	"tab\there",
+	"new\nline",
+	"*",
+	"~root",
+	"-rf",
+	"!event",
+	"a|b&c>d<e",
+	"'",
+	`"`,
+	`\`,
+	"%s%n",
+	"ünïcødé",
+}
+
+// shellArgs runs script with bash and returns the arguments it printed
+func shellArgs(t *testing.T, script string) []string {
+	t.Helper()
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+
+	out, err := exec.Command(bash, "-c", script).Output()
+	if err != nil {
+		t.Fatalf("bash -c %q: %v", script, err)
+	}
+	args := strings.Split(string(out), "\x00")
+	return args[:len(args)-1]
+}
+
+func TestShellJoinRoundTrip(t *testing.T) {
+	got := shellArgs(t, `printf '%s\0' `+ShellJoin(hostileValues...))
+	if !reflect.DeepEqual(got, hostileValues) {
+		t.Errorf("bash split ShellJoin output into\n%q\nwant\n%q", got, hostileValues)
+	}
+}
+
+func TestShellSplitRoundTrip(t *testing.T) {
+	got := ShellSplit(ShellJoin(hostileValues...))
+	if !reflect.DeepEqual(got, hostileValues) {
+		t.Errorf("ShellSplit(ShellJoin()) =\n%q\nwant\n%q", got, hostileValues)
+	}
+}
+
+func TestShellEscapeInQuotes(t *testing.T) {
+	for _, value := range hostileValues {
+		script := `printf '%s\0' "` + ShellEscapeDouble(value) + `" '` + ShellEscapeSingle(value) + `'`
+		got := shellArgs(t, script)
+		want := []string{value, value}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("value %q came back as %q", value, got)
+		}
+	}
+}
+
+func TestShellQuoteLeavesSafeWords(t *testing.T) {
+	for _, word := range []string{"https://example.com:8443/api", "/tmp/out/ffuf-a.com.json", "-t", "a=b,c"} {
+		if got := ShellQuote(word); got != word {
+			t.Errorf("ShellQuote(%q) = %q, want it unchanged", word, got)
+		}
+	}
+	if got := ShellQuote(""); got != "''" {
+		t.Errorf("ShellQuote(\"\") = %q, want ''", got)
+	}
+}
+
+func TestShellSplitQuotes(t *testing.T) {
+	got := ShellSplit(`ffuf -H "X: \"a\" \$b \c" -w 'it'\''s' plain\ word`)
+	want := []string{"ffuf", "-H", `X: "a" $b \c`, "-w", "it's", "plain word"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ShellSplit = %q, want %q", got, want)
+	}
+}
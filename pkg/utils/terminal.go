@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/chzyer/readline"
+	"golang.org/x/sys/unix"
+)
+
+// Keys returned by Terminal.ReadKey besides single characters
+const (
+	KeyUp        = "up"
+	KeyDown      = "down"
+	KeyPageUp    = "pgup"
+	KeyPageDown  = "pgdown"
+	KeyHome      = "home"
+	KeyEnd       = "end"
+	KeyEnter     = "enter"
+	KeyEscape    = "esc"
+	KeyBackspace = "backspace"
+	KeyCtrlC     = "ctrl-c"
+)
+
+// escapeKeys maps the sequences terminals send for special keys
+var escapeKeys = map[string]string{
+	"\x1b[A":  KeyUp,
+	"\x1bOA":  KeyUp,
+	"\x1b[B":  KeyDown,
+	"\x1bOB":  KeyDown,
+	"\x1b[5~": KeyPageUp,
+	"\x1b[6~": KeyPageDown,
+	"\x1b[H":  KeyHome,
+	"\x1bOH":  KeyHome,
+	"\x1b[1~": KeyHome,
+	"\x1b[7~": KeyHome,
+	"\x1b[F":  KeyEnd,
+	"\x1bOF":  KeyEnd,
+	"\x1b[4~": KeyEnd,
+	"\x1b[8~": KeyEnd,
+}
+
+// Terminal is the controlling terminal switched to a full-screen view:
+// raw input on the alternate screen with the cursor hidden
+type Terminal struct {
+	fd      int
+	state   *readline.State
+	pending []byte // Input read but not returned as a key yet
+}
+
+// OpenTerminal switches the terminal to a full-screen view. Close must be
+// called to give it back.
+func OpenTerminal() (*Terminal, error) {
+	fd := int(os.Stdin.Fd())
+	if !readline.IsTerminal(fd) || !readline.IsTerminal(int(os.Stdout.Fd())) {
+		return nil, fmt.Errorf("not running in a terminal")
+	}
+
+	t := &Terminal{fd: fd}
+	if err := t.Resume(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Suspend gives the terminal back in its normal state, e.g. to run another
+// program in it, until Resume is called
+func (t *Terminal) Suspend() error {
+	fmt.Print("\x1b[?25h\x1b[?1049l")
+	return readline.Restore(t.fd, t.state)
+}
+
+// Resume switches back to the full-screen view after Suspend
+func (t *Terminal) Resume() error {
+	state, err := readline.MakeRaw(t.fd)
+	if err != nil {
+		return err
+	}
+	t.state = state
+	t.pending = nil
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	return nil
+}
+
+// Close restores the terminal
+func (t *Terminal) Close() error {
+	return t.Suspend()
+}
+
+// Size returns the width and height of the terminal
+func (t *Terminal) Size() (int, int) {
+	width, height, err := readline.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// ReadKey waits up to timeout for a key press and returns it, or an empty
+// string when none came. Special keys are returned as the Key constants,
+// other keys as the character typed.
+func (t *Terminal) ReadKey(timeout time.Duration) (string, error) {
+	if len(t.pending) == 0 {
+		fds := []unix.PollFd{{Fd: int32(t.fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, int(timeout/time.Millisecond))
+		if err == unix.EINTR || n == 0 {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		buf := make([]byte, 256)
+		n, err = os.Stdin.Read(buf)
+		if err != nil {
+			return "", err
+		}
+		t.pending = buf[:n]
+	}
+
+	return t.nextKey(), nil
+}
+
+// nextKey takes the first key off the pending input
+func (t *Terminal) nextKey() string {
+	in := t.pending
+	if in[0] == 0x1b {
+		for seq, key := range escapeKeys {
+			if len(in) >= len(seq) && string(in[:len(seq)]) == seq {
+				t.pending = in[len(seq):]
+				return key
+			}
+		}
+		// Unknown sequences are dropped whole, a lone escape is the key
+		t.pending = nil
+		if len(in) == 1 {
+			return KeyEscape
+		}
+		return ""
+	}
+
+	r, size := utf8.DecodeRune(in)
+	t.pending = in[size:]
+	switch r {
+	case '\r', '\n':
+		return KeyEnter
+	case 0x7f, 0x08:
+		return KeyBackspace
+	case 0x03:
+		return KeyCtrlC
+	}
+	return string(r)
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTerminalKeys(t *testing.T) {
+	term := &Terminal{pending: []byte("\x1b[Aq\x1b[6~ü\r\x7f\x03\x1b[99~x")}
+
+	var keys []string
+	for len(term.pending) > 0 {
+		keys = append(keys, term.nextKey())
+	}
+
+	// The unknown sequence takes the rest of its read with it
+	want := []string{KeyUp, "q", KeyPageDown, "ü", KeyEnter, KeyBackspace, KeyCtrlC, ""}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %q, want %q", keys, want)
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"net"
 	"net/url"
 	"strings"
 )
@@ -23,13 +24,49 @@ func ParseURL(rawURL string) (protocol, domain string, err error) {
 	return protocol, domain, nil
 }
 
-// SanitizeDomain removes port from domain if present
+// SanitizeDomain removes port from domain if present, and the brackets
+// around an IPv6 address ("[2001:db8::1]:8080" is "2001:db8::1")
 func SanitizeDomain(domain string) string {
-	// Remove port if present
-	if idx := strings.Index(domain, ":"); idx != -1 {
-		domain = domain[:idx]
+	host, _ := splitHostPort(domain)
+	return host
+}
+
+// splitHostPort splits a URL host into the host and the port, which is
+// empty when the host has none
+func splitHostPort(domain string) (host, port string) {
+	if host, port, err := net.SplitHostPort(domain); err == nil {
+		return host, port
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(domain, "["), "]"), ""
+}
+
+// TargetKey names a target's output directory and findings file. It is the
+// domain without the port, unless the URL has an explicit port, in which case
+// "_<port>" is appended so the same host on several ports does not collide.
+// Characters that are unsafe in a file name are replaced with "_".
+func TargetKey(domain string) string {
+	key, port := splitHostPort(domain)
+	if port != "" {
+		key += "_" + port
+	}
+	return safeFileName(key)
+}
+
+// safeFileName keeps letters, digits, '.', '_' and '-' and refuses names
+// that would point outside the directory
+func safeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		return "_"
 	}
-	return domain
+	return name
 }
 
 // NormalizeURL ensures URL has proper format
@@ -0,0 +1,35 @@
+package utils
+
+import "testing"
+
+func TestTargetKey(t *testing.T) {
+	for domain, want := range map[string]string{
+		"example.com":        "example.com",
+		"example.com:8443":   "example.com_8443",
+		"..":                 "_",
+		"a$(id)b.com":        "a__id_b.com",
+		"evil.com:80/../x":   "evil.com_80_.._x",
+		"[2001:db8::1]":      "2001_db8__1",
+		"[2001:db8::2]:8080": "2001_db8__2_8080",
+		"[::1]:443":          "__1_443",
+		"10.0.0.1:8443":      "10.0.0.1_8443",
+	} {
+		if got := TargetKey(domain); got != want {
+			t.Errorf("TargetKey(%q) = %q, want %q", domain, got, want)
+		}
+	}
+}
+
+func TestSanitizeDomain(t *testing.T) {
+	for domain, want := range map[string]string{
+		"example.com":        "example.com",
+		"example.com:8443":   "example.com",
+		"[2001:db8::1]":      "2001:db8::1",
+		"[2001:db8::2]:8080": "2001:db8::2",
+		"10.0.0.1:80":        "10.0.0.1",
+	} {
+		if got := SanitizeDomain(domain); got != want {
+			t.Errorf("SanitizeDomain(%q) = %q, want %q", domain, got, want)
+		}
+	}
+}